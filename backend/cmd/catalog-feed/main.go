@@ -0,0 +1,61 @@
+// cmd/catalog-feed/main.go
+package main
+
+import (
+	"backend/internal/config"
+	"backend/internal/feeds"
+	"backend/internal/strapi"
+	"backend/pkg/logger"
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	format := flag.String("format", "", "формат фида: yml или google; без него выгружаются все форматы в -dir")
+	dir := flag.String("dir", "", "каталог для фидов всех форматов (по умолчанию FEEDS_DIR)")
+	flag.Parse()
+
+	// Инициализация логирования
+	logger.Init()
+
+	// Загрузка конфигурации
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.ErrorLogger.Fatal("Ошибка конфигурации:", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	strapiClient := strapi.NewClient(cfg)
+	generator := feeds.NewGenerator(strapiClient, feeds.ConfigOptions(cfg, strapiClient.BaseURL()))
+
+	// Один формат пишется в stdout, чтобы фид можно было перенаправить куда угодно
+	if *format != "" {
+		f, err := feeds.ParseFormat(*format)
+		if err != nil {
+			logger.ErrorLogger.Fatal(err)
+		}
+		products, err := generator.Load(ctx)
+		if err != nil {
+			logger.ErrorLogger.Fatal("Ошибка загрузки каталога:", err)
+		}
+		if err := generator.Write(os.Stdout, f, products, time.Now()); err != nil {
+			logger.ErrorLogger.Fatal("Ошибка выгрузки фида:", err)
+		}
+		return
+	}
+
+	if *dir == "" {
+		*dir = cfg.FeedsDir
+	}
+	count, err := generator.WriteDir(ctx, *dir)
+	if err != nil {
+		logger.ErrorLogger.Fatal("Ошибка выгрузки фидов:", err)
+	}
+	logger.InfoLogger.Printf("Фиды выгружены в %s: %d товаров", *dir, count)
+}
@@ -0,0 +1,87 @@
+// cmd/catalog-sync/main.go
+package main
+
+import (
+	"backend/internal/catalogsync"
+	"backend/internal/config"
+	"backend/internal/strapi"
+	"backend/internal/woocommerce"
+	"backend/pkg/logger"
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	full := flag.Bool("full", false, "пройти весь каталог, а не только изменения с прошлого запуска")
+	dryRun := flag.Bool("dry-run", false, "показать изменения, ничего не записывая в WooCommerce")
+	interval := flag.Duration("interval", 0, "повторять синхронизацию с этим периодом; 0 — один проход")
+	flag.Parse()
+
+	// Инициализация логирования
+	logger.Init()
+
+	// Загрузка конфигурации
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.ErrorLogger.Fatal("Ошибка конфигурации:", err)
+	}
+	if cfg.WooCommerceKey == "" || cfg.WooCommerceSecret == "" {
+		logger.ErrorLogger.Fatal("Не заданы WOOCOMMERCE_KEY и WOOCOMMERCE_SECRET")
+	}
+
+	strapiClient := strapi.NewClient(cfg)
+	syncer := catalogsync.NewSyncer(strapiClient, woocommerce.NewClient(cfg), catalogsync.Options{
+		StatePath:         cfg.CatalogSyncState,
+		MediaBaseURL:      strapiClient.BaseURL(),
+		Populate:          cfg.CatalogSyncPopulate,
+		CategorySeparator: cfg.CatalogCategorySep,
+		Retries:           cfg.CatalogSyncRetries,
+		RetryDelay:        cfg.CatalogSyncRetryDelay,
+		Full:              *full,
+		DryRun:            *dryRun,
+		DeleteMissing:     cfg.CatalogSyncDeleteMissing,
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for {
+		failed := run(ctx, syncer)
+		if *interval <= 0 {
+			if failed {
+				os.Exit(1)
+			}
+			return
+		}
+
+		// Полный проход нужен только первый раз, дальше — по изменениям
+		syncer.Options.Full = false
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// run выполняет один проход и пишет сводку в лог; true — если были ошибки
+func run(ctx context.Context, syncer *catalogsync.Syncer) bool {
+	report, err := syncer.Run(ctx)
+	if report != nil {
+		if len(report.Failed) > 0 {
+			logger.ErrorLogger.Println(report)
+		} else {
+			logger.InfoLogger.Println(report)
+		}
+	}
+	if err != nil {
+		logger.ErrorLogger.Println("Ошибка синхронизации каталога:", err)
+		return true
+	}
+	return len(report.Failed) > 0
+}
@@ -5,6 +5,7 @@ import (
 	"backend/internal/config"
 	"backend/internal/gateway"
 	"backend/pkg/logger"
+	"context"
 	"fmt"
 )
 
@@ -13,7 +14,10 @@ func main() {
 	logger.Init()
 
 	// Загрузка конфигурации
-	cfg := config.LoadConfig()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.ErrorLogger.Fatal("Ошибка конфигурации:", err)
+	}
 
 	// Создание API Gateway
 	gw, err := gateway.NewGateway(cfg)
@@ -21,6 +25,9 @@ func main() {
 		logger.ErrorLogger.Fatal("Ошибка инициализации Gateway:", err)
 	}
 
+	// Фоновые задачи: поисковый индекс и т.п.
+	gw.Start(context.Background())
+
 	// Настройка маршрутов
 	router := gw.SetupRouter()
 
@@ -0,0 +1,249 @@
+// internal/auth/jwks.go
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksMinRefresh ограничивает частоту внеплановых загрузок JWKS при неизвестном kid
+	jwksMinRefresh = time.Minute
+	// jwksRetryBase и jwksRetryMax задают экспоненциальную паузу после неудачных загрузок
+	jwksRetryBase = 5 * time.Second
+	jwksRetryMax  = 5 * time.Minute
+)
+
+// publicKey — ключ проверки подписи вместе с алгоритмом, если он задан в JWK
+type publicKey struct {
+	kid string
+	alg string
+	key crypto.PublicKey
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKSCache загружает и кеширует набор ключей с JWKS-эндпоинта
+type JWKSCache struct {
+	url        string
+	ttl        time.Duration
+	minRefresh time.Duration
+	retryBase  time.Duration
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	keys        []publicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	failures    int
+	lastErr     error
+	inflight    *jwksCall
+}
+
+// jwksCall — загрузка набора ключей, результата которой ждут все параллельные запросы
+type jwksCall struct {
+	done chan struct{}
+	keys []publicKey
+	err  error
+}
+
+func NewJWKSCache(url string, ttl time.Duration) *JWKSCache {
+	return &JWKSCache{
+		url:        url,
+		ttl:        ttl,
+		minRefresh: jwksMinRefresh,
+		retryBase:  jwksRetryBase,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Keys возвращает актуальный набор ключей. forceRefresh перезагружает набор
+// раньше срока (не чаще minRefresh) — так подхватываются новые ключи после ротации.
+// Загрузка идёт без блокировки кеша и одна на всех: параллельные запросы ждут её результат.
+// При ошибке загрузки продолжаем работать со старым набором, а следующие попытки
+// откладываем с растущей паузой, чтобы не нагружать недоступный эндпоинт.
+func (j *JWKSCache) Keys(ctx context.Context, forceRefresh bool) ([]publicKey, error) {
+	j.mu.Lock()
+
+	stale := j.keys == nil || time.Since(j.fetchedAt) > j.ttl
+	if !stale && !(forceRefresh && time.Since(j.attemptedAt) > j.minRefresh) {
+		keys := j.keys
+		j.mu.Unlock()
+		return keys, nil
+	}
+
+	call := j.inflight
+	if call == nil {
+		if j.failures > 0 && time.Since(j.attemptedAt) < j.retryDelay() {
+			keys, err := j.keys, j.lastErr
+			j.mu.Unlock()
+			if keys != nil {
+				return keys, nil
+			}
+			return nil, err
+		}
+
+		call = &jwksCall{done: make(chan struct{})}
+		j.inflight = call
+		j.attemptedAt = time.Now()
+		j.mu.Unlock()
+
+		// Результат нужен и другим запросам, поэтому отмена контекста первого
+		// запроса не должна обрывать загрузку; её ограничивает таймаут клиента
+		keys, err := j.fetch(context.WithoutCancel(ctx))
+		j.finish(call, keys, err)
+		return call.keys, call.err
+	}
+	j.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.keys, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// finish сохраняет результат загрузки и будит ожидающие запросы
+func (j *JWKSCache) finish(call *jwksCall, keys []publicKey, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.inflight = nil
+	if err != nil {
+		j.failures++
+		j.lastErr = err
+	} else {
+		j.keys = keys
+		j.fetchedAt = time.Now()
+		j.failures = 0
+		j.lastErr = nil
+	}
+
+	call.keys, call.err = j.keys, nil
+	if j.keys == nil {
+		call.err = err
+	}
+	close(call.done)
+}
+
+// retryDelay — пауза перед следующей попыткой после j.failures неудач подряд
+func (j *JWKSCache) retryDelay() time.Duration {
+	delay := j.retryBase
+	for i := 1; i < j.failures && delay < jwksRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, jwksRetryMax)
+}
+
+func (j *JWKSCache) fetch(ctx context.Context) ([]publicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: создание запроса: %w", err)
+	}
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: загрузка: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("jwks: ответ %d: %s", resp.StatusCode, string(body))
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("jwks: парсинг: %w", err)
+	}
+
+	keys := make([]publicKey, 0, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// Неизвестные типы ключей пропускаем, чтобы не терять остальные
+			continue
+		}
+		keys = append(keys, publicKey{kid: jwk.Kid, alg: jwk.Alg, key: key})
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, fmt.Errorf("jwk %q: слишком большая экспонента", k.Kid)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("jwk %q: неподдерживаемая кривая %q", k.Kid, k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("jwk %q: точка не на кривой", k.Kid)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("jwk %q: неподдерживаемый тип %q", k.Kid, k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("jwk: некорректное base64url: %w", err)
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,178 @@
+// internal/auth/principal.go
+package auth
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// PrincipalKey — ключ, под которым Principal лежит в gin.Context
+const PrincipalKey = "principal"
+
+// Wildcard в списке прав роли означает «все права»
+const Wildcard = "*"
+
+// contextValues — контекст запроса со значениями по ключу, например *gin.Context
+type contextValues interface {
+	Get(key string) (value any, exists bool)
+}
+
+// PrincipalFrom возвращает пользователя запроса, если middleware аутентификации его определил
+func PrincipalFrom(c contextValues) (*Principal, bool) {
+	value, exists := c.Get(PrincipalKey)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := value.(*Principal)
+	return principal, ok
+}
+
+// Principal — аутентифицированный пользователь запроса
+type Principal struct {
+	UserID      int
+	Role        string
+	Permissions []string
+
+	// Данные токена, по которым его можно отозвать
+	TokenID   string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// HasRole сообщает, что у пользователя одна из перечисленных ролей
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if p.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission сообщает, что у пользователя есть право perm
+func (p *Principal) HasPermission(perm string) bool {
+	for _, granted := range p.Permissions {
+		if granted == Wildcard || granted == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// RolePermissions сопоставляет роли и выданные им права
+type RolePermissions map[string][]string
+
+// ParseRolePermissions разбирает строку вида
+// "admin=*;manager=orders:read,orders:manage,catalog:edit"
+func ParseRolePermissions(s string) (RolePermissions, error) {
+	perms := RolePermissions{}
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		role, list, ok := strings.Cut(entry, "=")
+		role = strings.TrimSpace(role)
+		if !ok || role == "" {
+			return nil, fmt.Errorf("некорректное описание роли %q", entry)
+		}
+
+		for _, perm := range strings.Split(list, ",") {
+			if perm = strings.TrimSpace(perm); perm != "" {
+				perms[role] = append(perms[role], perm)
+			}
+		}
+	}
+	return perms, nil
+}
+
+// PrincipalFromClaims строит Principal из claims токена.
+// Роль берётся из roleClaim: строкой (наши токены) или объектом роли
+// users-permissions {"type": "...", "name": "..."}; права — из роли
+// и дополнительно из claim "permissions".
+func PrincipalFromClaims(claims jwt.MapClaims, roleClaim string, rolePerms RolePermissions) (*Principal, error) {
+	userID, err := claimInt(claims["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	principal := &Principal{
+		UserID:    userID,
+		Role:      claimRole(claims[roleClaim]),
+		IssuedAt:  claimTime(claims["iat"]),
+		ExpiresAt: claimTime(claims["exp"]),
+	}
+	principal.TokenID, _ = claims["jti"].(string)
+
+	seen := map[string]bool{}
+	add := func(perm string) {
+		if perm != "" && !seen[perm] {
+			seen[perm] = true
+			principal.Permissions = append(principal.Permissions, perm)
+		}
+	}
+	for _, perm := range rolePerms[principal.Role] {
+		add(perm)
+	}
+	if extra, ok := claims["permissions"].([]interface{}); ok {
+		for _, perm := range extra {
+			if s, ok := perm.(string); ok {
+				add(s)
+			}
+		}
+	}
+
+	return principal, nil
+}
+
+func claimRole(v interface{}) string {
+	switch role := v.(type) {
+	case string:
+		return role
+	case map[string]interface{}:
+		if t, ok := role["type"].(string); ok && t != "" {
+			return t
+		}
+		if name, ok := role["name"].(string); ok {
+			return strings.ToLower(name)
+		}
+	}
+	return ""
+}
+
+func claimTime(v interface{}) time.Time {
+	if unix, ok := v.(float64); ok {
+		// NumericDate может быть дробным — сохраняем миллисекунды
+		return time.UnixMilli(int64(math.Round(unix * 1000)))
+	}
+	return time.Time{}
+}
+
+func claimInt(v interface{}) (int, error) {
+	switch id := v.(type) {
+	case float64:
+		if id != float64(int(id)) {
+			return 0, fmt.Errorf("дробный ID %v", id)
+		}
+		return int(id), nil
+	case string:
+		return strconv.Atoi(id)
+	case nil:
+		return 0, fmt.Errorf("в токене нет claim id")
+	default:
+		return 0, fmt.Errorf("неверный тип claim id: %T", v)
+	}
+}
+
+// Права, на которые опираются служебные маршруты шлюза
+const (
+	PermOrdersRead     = "orders:read"
+	PermOrdersManage   = "orders:manage"
+	PermSessionsRevoke = "sessions:revoke"
+)
@@ -0,0 +1,396 @@
+// internal/auth/refresh.go
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrRefreshInvalid = errors.New("refresh-токен недействителен")
+	ErrRefreshReused  = errors.New("refresh-токен использован повторно")
+)
+
+// RefreshSession — сессия, к которой привязан refresh-токен.
+// Family объединяет все токены одной цепочки ротации.
+type RefreshSession struct {
+	UserID    int
+	Role      string
+	Family    string
+	ExpiresAt time.Time
+}
+
+// RefreshStore хранит refresh-токены и ротирует их при каждом обмене
+type RefreshStore interface {
+	// Create выпускает новый токен для сессии (новая семья, если Family пуст)
+	Create(ctx context.Context, session RefreshSession) (string, error)
+	// Lookup возвращает сессию действующего токена, не гася его;
+	// для погашенного токена возвращает ErrRefreshReused
+	Lookup(ctx context.Context, token string) (RefreshSession, error)
+	// Rotate гасит предъявленный токен и выдаёт следующий в той же семье.
+	// Повторное предъявление погашенного токена отзывает всю семью.
+	Rotate(ctx context.Context, token string, ttl time.Duration) (RefreshSession, string, error)
+	// Revoke отзывает семью, к которой относится токен
+	Revoke(ctx context.Context, token string) error
+	// RevokeUser отзывает все refresh-токены пользователя
+	RevokeUser(ctx context.Context, userID int) error
+}
+
+type refreshRecord struct {
+	session RefreshSession
+	used    bool
+}
+
+// MemoryRefreshStore хранит refresh-токены в памяти процесса
+type MemoryRefreshStore struct {
+	mu       sync.Mutex
+	records  map[string]*refreshRecord // ключ — SHA-256 токена
+	families map[string]map[string]struct{}
+}
+
+func NewMemoryRefreshStore() *MemoryRefreshStore {
+	return &MemoryRefreshStore{
+		records:  make(map[string]*refreshRecord),
+		families: make(map[string]map[string]struct{}),
+	}
+}
+
+func (s *MemoryRefreshStore) Create(_ context.Context, session RefreshSession) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanupLocked(time.Now())
+	return s.createLocked(session)
+}
+
+func (s *MemoryRefreshStore) Lookup(_ context.Context, token string) (RefreshSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[hashToken(token)]
+	if !ok || time.Now().After(record.session.ExpiresAt) {
+		return RefreshSession{}, ErrRefreshInvalid
+	}
+	if record.used {
+		return RefreshSession{}, ErrRefreshReused
+	}
+	return record.session, nil
+}
+
+func (s *MemoryRefreshStore) Rotate(_ context.Context, token string, ttl time.Duration) (RefreshSession, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.cleanupLocked(now)
+
+	record, ok := s.records[hashToken(token)]
+	if !ok || now.After(record.session.ExpiresAt) {
+		return RefreshSession{}, "", ErrRefreshInvalid
+	}
+	if record.used {
+		s.revokeFamilyLocked(record.session.Family)
+		return RefreshSession{}, "", ErrRefreshReused
+	}
+	record.used = true
+
+	next := record.session
+	next.ExpiresAt = now.Add(ttl)
+	newToken, err := s.createLocked(next)
+	if err != nil {
+		return RefreshSession{}, "", err
+	}
+	return record.session, newToken, nil
+}
+
+func (s *MemoryRefreshStore) Revoke(_ context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[hashToken(token)]
+	if !ok {
+		return ErrRefreshInvalid
+	}
+	s.revokeFamilyLocked(record.session.Family)
+	return nil
+}
+
+func (s *MemoryRefreshStore) RevokeUser(_ context.Context, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range s.records {
+		if record.session.UserID == userID {
+			s.revokeFamilyLocked(record.session.Family)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryRefreshStore) createLocked(session RefreshSession) (string, error) {
+	if session.Family == "" {
+		family, err := randomToken(16)
+		if err != nil {
+			return "", err
+		}
+		session.Family = family
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	key := hashToken(token)
+	s.records[key] = &refreshRecord{session: session}
+	if s.families[session.Family] == nil {
+		s.families[session.Family] = make(map[string]struct{})
+	}
+	s.families[session.Family][key] = struct{}{}
+	return token, nil
+}
+
+func (s *MemoryRefreshStore) revokeFamilyLocked(family string) {
+	for key := range s.families[family] {
+		delete(s.records, key)
+	}
+	delete(s.families, family)
+}
+
+// cleanupLocked удаляет истёкшие токены, чтобы хранилище не росло бесконечно
+func (s *MemoryRefreshStore) cleanupLocked(now time.Time) {
+	for key, record := range s.records {
+		if now.After(record.session.ExpiresAt) {
+			delete(s.records, key)
+			if members := s.families[record.session.Family]; members != nil {
+				delete(members, key)
+				if len(members) == 0 {
+					delete(s.families, record.session.Family)
+				}
+			}
+		}
+	}
+}
+
+// maxRotateAttempts — сколько раз повторять Rotate при параллельном обмене того же токена
+const maxRotateAttempts = 5
+
+// RedisRefreshStore хранит refresh-токены в Redis, чтобы обмен и отзыв
+// работали на любой реплике шлюза. Ключи:
+//
+//	token:<sha256>  — JSON redisRefreshRecord, живёт до истечения токена
+//	family:<id>     — множество хешей токенов семьи
+//	user:<id>       — множество семей пользователя
+type RedisRefreshStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+type redisRefreshRecord struct {
+	UserID    int       `json:"userId"`
+	Role      string    `json:"role,omitempty"`
+	Family    string    `json:"family"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Used      bool      `json:"used,omitempty"`
+}
+
+func NewRedisRefreshStore(client redis.UniversalClient, prefix string) *RedisRefreshStore {
+	return &RedisRefreshStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *RedisRefreshStore) Create(ctx context.Context, session RefreshSession) (string, error) {
+	if session.Family == "" {
+		family, err := randomToken(16)
+		if err != nil {
+			return "", err
+		}
+		session.Family = family
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		return s.createPipelined(ctx, pipe, hashToken(token), session)
+	})
+	if err != nil {
+		return "", fmt.Errorf("сохранение refresh-токена: %w", err)
+	}
+	return token, nil
+}
+
+func (s *RedisRefreshStore) Lookup(ctx context.Context, token string) (RefreshSession, error) {
+	record, err := s.load(ctx, s.client, hashToken(token))
+	if err != nil {
+		return RefreshSession{}, err
+	}
+	if record.Used {
+		return RefreshSession{}, ErrRefreshReused
+	}
+	return record.session(), nil
+}
+
+// Rotate гасит токен в оптимистичной транзакции WATCH/MULTI: из двух
+// параллельных обменов одного токена успешен только один
+func (s *RedisRefreshStore) Rotate(ctx context.Context, token string, ttl time.Duration) (RefreshSession, string, error) {
+	key := hashToken(token)
+
+	var session RefreshSession
+	var newToken string
+	var reused string
+	txf := func(tx *redis.Tx) error {
+		record, err := s.load(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		if record.Used {
+			reused = record.Family
+			return ErrRefreshReused
+		}
+
+		next := record.session()
+		next.ExpiresAt = time.Now().Add(ttl)
+		nextToken, err := randomToken(32)
+		if err != nil {
+			return err
+		}
+
+		record.Used = true
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, s.prefix+"token:"+key, data, redis.KeepTTL)
+			return s.createPipelined(ctx, pipe, hashToken(nextToken), next)
+		})
+		if err == nil {
+			session, newToken = record.session(), nextToken
+		}
+		return err
+	}
+
+	for attempt := 0; attempt < maxRotateAttempts; attempt++ {
+		err := s.client.Watch(ctx, txf, s.prefix+"token:"+key)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if errors.Is(err, ErrRefreshReused) {
+			if revokeErr := s.revokeFamily(ctx, reused); revokeErr != nil {
+				return RefreshSession{}, "", revokeErr
+			}
+			return RefreshSession{}, "", err
+		}
+		if err != nil {
+			return RefreshSession{}, "", err
+		}
+		return session, newToken, nil
+	}
+	// Токен меняют параллельно — считаем это повторным предъявлением
+	return RefreshSession{}, "", ErrRefreshInvalid
+}
+
+func (s *RedisRefreshStore) Revoke(ctx context.Context, token string) error {
+	record, err := s.load(ctx, s.client, hashToken(token))
+	if err != nil {
+		return err
+	}
+	return s.revokeFamily(ctx, record.Family)
+}
+
+func (s *RedisRefreshStore) RevokeUser(ctx context.Context, userID int) error {
+	userKey := s.prefix + "user:" + strconv.Itoa(userID)
+	families, err := s.client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return fmt.Errorf("чтение сессий пользователя: %w", err)
+	}
+	for _, family := range families {
+		if err := s.revokeFamily(ctx, family); err != nil {
+			return err
+		}
+	}
+	return s.client.Del(ctx, userKey).Err()
+}
+
+// createPipelined записывает токен и добавляет его в семью и к пользователю.
+// Срок множеств продлевается до срока нового токена — он выпущен последним.
+func (s *RedisRefreshStore) createPipelined(ctx context.Context, pipe redis.Pipeliner, key string, session RefreshSession) error {
+	data, err := json.Marshal(redisRefreshRecord{
+		UserID:    session.UserID,
+		Role:      session.Role,
+		Family:    session.Family,
+		ExpiresAt: session.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	familyKey := s.prefix + "family:" + session.Family
+	userKey := s.prefix + "user:" + strconv.Itoa(session.UserID)
+
+	pipe.Set(ctx, s.prefix+"token:"+key, data, time.Until(session.ExpiresAt))
+	pipe.SAdd(ctx, familyKey, key)
+	pipe.ExpireAt(ctx, familyKey, session.ExpiresAt)
+	pipe.SAdd(ctx, userKey, session.Family)
+	pipe.ExpireAt(ctx, userKey, session.ExpiresAt)
+	return nil
+}
+
+func (s *RedisRefreshStore) revokeFamily(ctx context.Context, family string) error {
+	familyKey := s.prefix + "family:" + family
+	members, err := s.client.SMembers(ctx, familyKey).Result()
+	if err != nil {
+		return fmt.Errorf("чтение семьи refresh-токенов: %w", err)
+	}
+	keys := make([]string, 0, len(members)+1)
+	for _, member := range members {
+		keys = append(keys, s.prefix+"token:"+member)
+	}
+	keys = append(keys, familyKey)
+	return s.client.Del(ctx, keys...).Err()
+}
+
+func (s *RedisRefreshStore) load(ctx context.Context, client redis.Cmdable, key string) (*redisRefreshRecord, error) {
+	data, err := client.Get(ctx, s.prefix+"token:"+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrRefreshInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("чтение refresh-токена: %w", err)
+	}
+	var record redisRefreshRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("разбор refresh-токена: %w", err)
+	}
+	if time.Now().After(record.ExpiresAt) {
+		return nil, ErrRefreshInvalid
+	}
+	return &record, nil
+}
+
+func (r *redisRefreshRecord) session() RefreshSession {
+	return RefreshSession{
+		UserID:    r.UserID,
+		Role:      r.Role,
+		Family:    r.Family,
+		ExpiresAt: r.ExpiresAt,
+	}
+}
+
+// hashToken — в памяти храним только хеши, чтобы дамп не раскрывал токены
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
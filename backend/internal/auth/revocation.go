@@ -0,0 +1,170 @@
+// internal/auth/revocation.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RevocationStore хранит отозванные access-токены.
+// Отдельный токен отзывается по jti до истечения его exp;
+// все сессии пользователя — отметкой «выпущенные раньше этого момента недействительны».
+type RevocationStore interface {
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	RevokeUser(ctx context.Context, userID int, issuedBefore time.Time, ttl time.Duration) error
+	UserRevokedBefore(ctx context.Context, userID int) (time.Time, error)
+}
+
+// IsRevoked проверяет токен Principal по обоим видам отзыва
+func IsRevoked(ctx context.Context, store RevocationStore, principal *Principal) (bool, error) {
+	if principal.TokenID != "" {
+		revoked, err := store.IsTokenRevoked(ctx, principal.TokenID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	before, err := store.UserRevokedBefore(ctx, principal.UserID)
+	if err != nil {
+		return false, err
+	}
+	if before.IsZero() {
+		return false, nil
+	}
+	// Токен без iat нельзя сопоставить с отметкой — считаем отозванным.
+	// Отметка и iat наших токенов точны до миллисекунды, поэтому вход сразу
+	// после отзыва даёт действующий токен; у целых iat внешних провайдеров
+	// токены той же секунды, что и отметка, считаются отозванными.
+	return principal.IssuedAt.IsZero() || !principal.IssuedAt.After(before), nil
+}
+
+// MemoryRevocationStore — отзыв токенов в памяти одного экземпляра шлюза
+type MemoryRevocationStore struct {
+	mu     sync.Mutex
+	tokens map[string]time.Time // jti -> когда запись можно удалить
+	users  map[int]userRevocation
+}
+
+type userRevocation struct {
+	before    time.Time
+	expiresAt time.Time
+}
+
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		tokens: make(map[string]time.Time),
+		users:  make(map[int]userRevocation),
+	}
+}
+
+func (s *MemoryRevocationStore) RevokeToken(_ context.Context, jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return errors.New("у токена нет jti")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanupLocked(time.Now())
+	s.tokens[jti] = expiresAt
+	return nil
+}
+
+func (s *MemoryRevocationStore) IsTokenRevoked(_ context.Context, jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt, ok := s.tokens[jti]
+	return ok && time.Now().Before(expiresAt), nil
+}
+
+func (s *MemoryRevocationStore) RevokeUser(_ context.Context, userID int, issuedBefore time.Time, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanupLocked(time.Now())
+	s.users[userID] = userRevocation{before: issuedBefore.Truncate(time.Millisecond), expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryRevocationStore) UserRevokedBefore(_ context.Context, userID int) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rev, ok := s.users[userID]
+	if !ok || time.Now().After(rev.expiresAt) {
+		return time.Time{}, nil
+	}
+	return rev.before, nil
+}
+
+func (s *MemoryRevocationStore) cleanupLocked(now time.Time) {
+	for jti, expiresAt := range s.tokens {
+		if now.After(expiresAt) {
+			delete(s.tokens, jti)
+		}
+	}
+	for userID, rev := range s.users {
+		if now.After(rev.expiresAt) {
+			delete(s.users, userID)
+		}
+	}
+}
+
+// RedisRevocationStore хранит отзыв в Redis (или совместимом сервере),
+// чтобы выход действовал сразу на всех репликах шлюза
+type RedisRevocationStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+func NewRedisRevocationStore(client redis.UniversalClient, prefix string) *RedisRevocationStore {
+	return &RedisRevocationStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *RedisRevocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return errors.New("у токена нет jti")
+	}
+
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, s.prefix+"jti:"+jti, "1", ttl).Err()
+}
+
+func (s *RedisRevocationStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := s.client.Exists(ctx, s.prefix+"jti:"+jti).Result()
+	if err != nil {
+		return false, fmt.Errorf("проверка отзыва токена: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (s *RedisRevocationStore) RevokeUser(ctx context.Context, userID int, issuedBefore time.Time, ttl time.Duration) error {
+	key := s.prefix + "user:" + strconv.Itoa(userID)
+	return s.client.Set(ctx, key, issuedBefore.UnixMilli(), ttl).Err()
+}
+
+func (s *RedisRevocationStore) UserRevokedBefore(ctx context.Context, userID int) (time.Time, error) {
+	key := s.prefix + "user:" + strconv.Itoa(userID)
+	millis, err := s.client.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("проверка отзыва сессий пользователя: %w", err)
+	}
+	return time.UnixMilli(millis), nil
+}
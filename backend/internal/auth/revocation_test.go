@@ -0,0 +1,117 @@
+// internal/auth/revocation_test.go
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryRevocationStoreTokens(t *testing.T) {
+	store := NewMemoryRevocationStore()
+	ctx := context.Background()
+
+	if err := store.RevokeToken(ctx, "", time.Now().Add(time.Hour)); err == nil {
+		t.Error("token without jti revoked")
+	}
+	if err := store.RevokeToken(ctx, "live", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RevokeToken(ctx, "expired", time.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	for jti, want := range map[string]bool{"live": true, "expired": false, "other": false} {
+		if got, _ := store.IsTokenRevoked(ctx, jti); got != want {
+			t.Errorf("IsTokenRevoked(%q) = %v, want %v", jti, got, want)
+		}
+	}
+
+	// Истёкшие записи вычищаются при следующей записи
+	store.RevokeToken(ctx, "next", time.Now().Add(time.Hour))
+	if _, ok := store.tokens["expired"]; ok {
+		t.Error("expired jti not cleaned up")
+	}
+
+	revoked, err := IsRevoked(ctx, store, &Principal{UserID: 1, TokenID: "live", IssuedAt: time.Now()})
+	if err != nil || !revoked {
+		t.Errorf("IsRevoked by jti = %v, %v", revoked, err)
+	}
+}
+
+func TestMemoryRevocationStoreUsers(t *testing.T) {
+	store := NewMemoryRevocationStore()
+	ctx := context.Background()
+
+	revokedAt := time.Date(2026, 3, 1, 12, 0, 0, 400*int(time.Millisecond), time.UTC)
+	if err := store.RevokeUser(ctx, 1, revokedAt, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RevokeUser(ctx, 2, revokedAt, -time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		userID   int
+		issuedAt time.Time
+		want     bool
+	}{
+		{"issued earlier", 1, revokedAt.Add(-time.Hour), true},
+		{"same second, before", 1, revokedAt.Add(-300 * time.Millisecond), true},
+		{"same second, after", 1, revokedAt.Add(300 * time.Millisecond), false},
+		{"whole-second iat of the same second", 1, revokedAt.Truncate(time.Second), true},
+		{"no iat", 1, time.Time{}, true},
+		{"other user", 3, revokedAt.Add(-time.Hour), false},
+		{"expired mark", 2, revokedAt.Add(-time.Hour), false},
+	}
+	for _, tc := range cases {
+		revoked, err := IsRevoked(ctx, store, &Principal{UserID: tc.userID, IssuedAt: tc.issuedAt})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if revoked != tc.want {
+			t.Errorf("%s: revoked = %v, want %v", tc.name, revoked, tc.want)
+		}
+	}
+}
+
+func TestIssuedTokenSurvivesEarlierRevocation(t *testing.T) {
+	store := NewMemoryRevocationStore()
+	ctx := context.Background()
+	issuer := NewIssuer([]byte("secret"), time.Minute, "", "")
+	verifier, err := NewVerifier(VerifierConfig{HMACSecret: []byte("secret"), Algorithms: []string{"HS256"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	principal := func() *Principal {
+		token, _, err := issuer.IssueAccessToken(Subject{UserID: 1, Role: "customer"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		claims, err := verifier.Verify(ctx, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := PrincipalFromClaims(claims, "role", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	before := principal()
+	time.Sleep(2 * time.Millisecond)
+	store.RevokeUser(ctx, 1, time.Now(), time.Hour)
+	time.Sleep(2 * time.Millisecond)
+	// Повторный вход сразу после отзыва — обычно в ту же секунду
+	after := principal()
+
+	if revoked, _ := IsRevoked(ctx, store, before); !revoked {
+		t.Error("token issued before revocation is still valid")
+	}
+	if revoked, _ := IsRevoked(ctx, store, after); revoked {
+		t.Errorf("token issued at %v revoked by mark at %v", after.IssuedAt, store.users[1].before)
+	}
+}
@@ -0,0 +1,82 @@
+// internal/auth/tokens.go
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Subject — данные пользователя, которые попадают в access-токен
+type Subject struct {
+	UserID int
+	Role   string
+}
+
+// Issuer выпускает короткоживущие access-токены шлюза
+type Issuer struct {
+	secret    []byte
+	accessTTL time.Duration
+	issuer    string
+	audience  string
+}
+
+// NewIssuer создаёт выпускающего токены; issuer и audience попадают в iss/aud,
+// если заданы, чтобы токены шлюза проходили собственную проверку Verifier
+func NewIssuer(secret []byte, accessTTL time.Duration, issuer, audience string) *Issuer {
+	return &Issuer{
+		secret:    secret,
+		accessTTL: accessTTL,
+		issuer:    issuer,
+		audience:  audience,
+	}
+}
+
+// AccessTTL возвращает время жизни access-токена
+func (i *Issuer) AccessTTL() time.Duration {
+	return i.accessTTL
+}
+
+// IssueAccessToken подписывает access-токен HS256 для пользователя
+func (i *Issuer) IssueAccessToken(subject Subject) (string, time.Time, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(i.accessTTL)
+	// iat с миллисекундами: отзыв всех сессий не должен задевать токен,
+	// выпущенный в ту же секунду сразу после отзыва
+	claims := jwt.MapClaims{
+		"id":   subject.UserID,
+		"role": subject.Role,
+		"jti":  jti,
+		"iat":  float64(now.UnixMilli()) / 1000,
+		"exp":  expiresAt.Unix(),
+	}
+	if i.issuer != "" {
+		claims["iss"] = i.issuer
+	}
+	if i.audience != "" {
+		claims["aud"] = i.audience
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(i.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("подпись access-токена: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// randomToken возвращает n случайных байт в base64url без паддинга
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("генерация случайного токена: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
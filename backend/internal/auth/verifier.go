@@ -0,0 +1,233 @@
+// internal/auth/verifier.go
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrTokenInvalid = errors.New("токен недействителен")
+	ErrUnknownKey   = errors.New("ключ подписи не найден")
+)
+
+// VerifierConfig описывает, какими ключами и с какими требованиями проверять токены
+type VerifierConfig struct {
+	HMACSecret     []byte
+	PublicKeyFiles []string
+	JWKSURL        string
+	JWKSCacheTTL   time.Duration
+	Algorithms     []string
+	Issuer         string
+	Audience       string
+}
+
+// Verifier проверяет подпись и claims входящих JWT.
+// HS-токены проверяются общим секретом, RS/ES — локальными PEM-ключами
+// и ключами JWKS; нужный ключ выбирается по kid, поэтому во время ротации
+// одновременно действуют старый и новый ключи.
+type Verifier struct {
+	secret     []byte
+	staticKeys []publicKey
+	jwks       *JWKSCache
+	parser     *jwt.Parser
+	issuer     string
+	audience   string
+}
+
+func NewVerifier(cfg VerifierConfig) (*Verifier, error) {
+	v := &Verifier{
+		secret:   cfg.HMACSecret,
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		parser:   jwt.NewParser(jwt.WithValidMethods(cfg.Algorithms)),
+	}
+
+	for _, path := range cfg.PublicKeyFiles {
+		keys, err := loadPEMKeys(path)
+		if err != nil {
+			return nil, err
+		}
+		v.staticKeys = append(v.staticKeys, keys...)
+	}
+
+	if cfg.JWKSURL != "" {
+		v.jwks = NewJWKSCache(cfg.JWKSURL, cfg.JWKSCacheTTL)
+	}
+
+	return v, nil
+}
+
+// Verify проверяет токен и возвращает его claims
+func (v *Verifier) Verify(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	token, err := v.parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return v.keyFor(ctx, token)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenInvalid, err)
+	}
+	if !token.Valid {
+		return nil, ErrTokenInvalid
+	}
+
+	// exp/nbf/iat проверяет парсер, но только если они есть; exp делаем обязательным
+	if _, ok := claims["exp"]; !ok {
+		return nil, fmt.Errorf("%w: нет exp", ErrTokenInvalid)
+	}
+	if v.issuer != "" && !claims.VerifyIssuer(v.issuer, true) {
+		return nil, fmt.Errorf("%w: неверный iss", ErrTokenInvalid)
+	}
+	if v.audience != "" && !claims.VerifyAudience(v.audience, true) {
+		return nil, fmt.Errorf("%w: неверный aud", ErrTokenInvalid)
+	}
+
+	return claims, nil
+}
+
+// keyFor подбирает ключ проверки по алгоритму и kid токена
+func (v *Verifier) keyFor(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	alg := token.Method.Alg()
+
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if len(v.secret) == 0 {
+			return nil, fmt.Errorf("%w: HMAC не настроен", ErrUnknownKey)
+		}
+		return v.secret, nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+	default:
+		return nil, fmt.Errorf("unexpected signing method: %v", alg)
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	if key := matchKey(v.staticKeys, kid, token.Method); key != nil {
+		return key, nil
+	}
+
+	if v.jwks != nil {
+		keys, err := v.jwks.Keys(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+		if key := matchKey(keys, kid, token.Method); key != nil {
+			return key, nil
+		}
+
+		// kid мог появиться после ротации — перечитываем набор вне расписания
+		keys, err = v.jwks.Keys(ctx, true)
+		if err != nil {
+			return nil, err
+		}
+		if key := matchKey(keys, kid, token.Method); key != nil {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: kid %q, alg %s", ErrUnknownKey, kid, alg)
+}
+
+// matchKey ищет ключ подходящего типа с нужным kid.
+// Токен без kid принимается, только если подходящий ключ единственный.
+func matchKey(keys []publicKey, kid string, method jwt.SigningMethod) interface{} {
+	var candidates []publicKey
+	for _, k := range keys {
+		if k.alg != "" && k.alg != method.Alg() {
+			continue
+		}
+		if !keyFitsMethod(k, method) {
+			continue
+		}
+		if kid != "" {
+			if k.kid == kid {
+				return k.key
+			}
+			continue
+		}
+		candidates = append(candidates, k)
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0].key
+	}
+	return nil
+}
+
+func keyFitsMethod(k publicKey, method jwt.SigningMethod) bool {
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := k.key.(*rsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodECDSA:
+		_, ok := k.key.(*ecdsa.PublicKey)
+		return ok
+	}
+	return false
+}
+
+// loadPEMKeys читает публичные ключи из PEM-файла. kid ключа — имя файла
+// без расширения; если ключей в файле несколько, к имени добавляется номер.
+func loadPEMKeys(path string) ([]publicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("чтение ключа %s: %w", path, err)
+	}
+
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	var keys []publicKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		var key interface{}
+		switch block.Type {
+		case "PUBLIC KEY":
+			key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		case "CERTIFICATE":
+			var cert *x509.Certificate
+			if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+				key = cert.PublicKey
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("разбор ключа %s: %w", path, err)
+		}
+
+		switch key.(type) {
+		case *rsa.PublicKey, *ecdsa.PublicKey:
+		default:
+			return nil, fmt.Errorf("ключ %s: неподдерживаемый тип %T", path, key)
+		}
+
+		kid := base
+		if len(keys) > 0 {
+			kid = fmt.Sprintf("%s-%d", base, len(keys))
+		}
+		keys = append(keys, publicKey{kid: kid, key: key})
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("в файле %s нет публичных ключей", path)
+	}
+	return keys, nil
+}
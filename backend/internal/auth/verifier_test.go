@@ -0,0 +1,345 @@
+// internal/auth/verifier_test.go
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func newECKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// sign подписывает токен с обязательными claims; пустой kid в заголовок не попадает
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, jwt.MapClaims{
+		"id":  7,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// writePEM сохраняет публичный ключ в файл name; имя без расширения становится kid
+func writePEM(t *testing.T, name string, pub any) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func b64(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+func rsaJWK(kid string, pub *rsa.PublicKey) jsonWebKey {
+	return jsonWebKey{Kty: "RSA", Kid: kid, Use: "sig", Alg: "RS256", N: b64(pub.N), E: b64(big.NewInt(int64(pub.E)))}
+}
+
+func ecJWK(kid string, pub *ecdsa.PublicKey) jsonWebKey {
+	return jsonWebKey{Kty: "EC", Kid: kid, Crv: "P-256", X: b64(pub.X), Y: b64(pub.Y)}
+}
+
+// jwksServer отдаёт текущий набор ключей и считает обращения
+type jwksServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	keys   []jsonWebKey
+	status int
+	hits   atomic.Int32
+}
+
+func newJWKSServer(t *testing.T, keys ...jsonWebKey) *jwksServer {
+	s := &jwksServer{keys: keys, status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.hits.Add(1)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.status != http.StatusOK {
+			w.WriteHeader(s.status)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"keys": s.keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) set(status int, keys ...jsonWebKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+	s.keys = keys
+}
+
+func TestVerifierPEMKeys(t *testing.T) {
+	rsaKey, ecKey := newRSAKey(t), newECKey(t)
+	v, err := NewVerifier(VerifierConfig{
+		PublicKeyFiles: []string{writePEM(t, "main.pem", &rsaKey.PublicKey), writePEM(t, "edge.pem", &ecKey.PublicKey)},
+		Algorithms:     []string{"RS256", "ES256"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for name, token := range map[string]string{
+		"rsa by kid": sign(t, jwt.SigningMethodRS256, rsaKey, "main"),
+		"ec by kid":  sign(t, jwt.SigningMethodES256, ecKey, "edge"),
+		// Без kid ключ выбирается по типу, если он единственный
+		"rsa without kid": sign(t, jwt.SigningMethodRS256, rsaKey, ""),
+	} {
+		if _, err := v.Verify(ctx, token); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	for name, token := range map[string]string{
+		"unknown kid":   sign(t, jwt.SigningMethodRS256, rsaKey, "other"),
+		"wrong key":     sign(t, jwt.SigningMethodRS256, newRSAKey(t), "main"),
+		"kid of ec key": sign(t, jwt.SigningMethodRS256, rsaKey, "edge"),
+	} {
+		if _, err := v.Verify(ctx, token); !errors.Is(err, ErrTokenInvalid) {
+			t.Errorf("%s: err = %v, want ErrTokenInvalid", name, err)
+		}
+	}
+}
+
+func TestVerifierJWKSRotation(t *testing.T) {
+	oldKey, newKey := newRSAKey(t), newECKey(t)
+	server := newJWKSServer(t, rsaJWK("2024", &oldKey.PublicKey))
+
+	v, err := NewVerifier(VerifierConfig{JWKSURL: server.URL, JWKSCacheTTL: time.Hour, Algorithms: []string{"RS256", "ES256"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.jwks.minRefresh = 0
+	ctx := context.Background()
+
+	if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodRS256, oldKey, "2024")); err != nil {
+		t.Fatalf("old key: %v", err)
+	}
+
+	// Провайдер опубликовал новый ключ, старый ещё действует
+	server.set(http.StatusOK, rsaJWK("2024", &oldKey.PublicKey), ecJWK("2025", &newKey.PublicKey))
+	if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodES256, newKey, "2025")); err != nil {
+		t.Fatalf("new kid after rotation: %v", err)
+	}
+	if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodRS256, oldKey, "2024")); err != nil {
+		t.Fatalf("old key during rotation: %v", err)
+	}
+	if hits := server.hits.Load(); hits != 2 {
+		t.Errorf("jwks fetched %d times, want 2", hits)
+	}
+
+	// Старый ключ отозван — его токены больше не принимаются
+	server.set(http.StatusOK, ecJWK("2025", &newKey.PublicKey))
+	if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodES256, newKey, "2026")); err == nil {
+		t.Fatal("unknown kid accepted")
+	}
+	if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodRS256, oldKey, "2024")); err == nil {
+		t.Fatal("revoked key accepted")
+	}
+}
+
+func TestVerifierAlgorithmPinning(t *testing.T) {
+	rsaKey, ecKey := newRSAKey(t), newECKey(t)
+	pemPath := writePEM(t, "main.pem", &rsaKey.PublicKey)
+	pemBytes, err := os.ReadFile(pemPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewVerifier(VerifierConfig{
+		HMACSecret:     []byte("secret"),
+		PublicKeyFiles: []string{pemPath, writePEM(t, "edge.pem", &ecKey.PublicKey)},
+		Algorithms:     []string{"RS256"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodRS256, rsaKey, "main")); err != nil {
+		t.Fatalf("pinned algorithm: %v", err)
+	}
+
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"id": 7, "exp": time.Now().Add(time.Hour).Unix()}).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{
+		"es256 with valid key": sign(t, jwt.SigningMethodES256, ecKey, "edge"),
+		"hs256 with secret":    sign(t, jwt.SigningMethodHS256, []byte("secret"), ""),
+		// Подмена алгоритма: публичный ключ в роли HMAC-секрета
+		"hs256 with public key": sign(t, jwt.SigningMethodHS256, pemBytes, "main"),
+		"none":                  none,
+	} {
+		if _, err := v.Verify(ctx, token); !errors.Is(err, ErrTokenInvalid) {
+			t.Errorf("%s: err = %v, want ErrTokenInvalid", name, err)
+		}
+	}
+
+	// Алгоритм из JWK тоже ограничивает ключ: RS256-ключ не проверяет PS256
+	v, err = NewVerifier(VerifierConfig{JWKSURL: newJWKSServer(t, rsaJWK("2024", &rsaKey.PublicKey)).URL, Algorithms: []string{"RS256", "PS256"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(ctx, sign(t, jwt.SigningMethodPS256, rsaKey, "2024")); err == nil {
+		t.Error("PS256 accepted by an RS256 key")
+	}
+}
+
+func TestJWKSCacheSingleFetch(t *testing.T) {
+	key := newRSAKey(t)
+	release := make(chan struct{})
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		json.NewEncoder(w).Encode(map[string]any{"keys": []jsonWebKey{rsaJWK("a", &key.PublicKey)}})
+	}))
+	defer server.Close()
+
+	cache := NewJWKSCache(server.URL, time.Hour)
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			keys, err := cache.Keys(context.Background(), false)
+			if err == nil && len(keys) != 1 {
+				err = errors.New("keys not shared")
+			}
+			errs <- err
+		}()
+	}
+
+	// Пока загрузка висит, кеш не заблокирован: отменённый запрос сразу выходит
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for hits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := cache.Keys(ctx, false); !errors.Is(err, context.Canceled) {
+		t.Errorf("waiting request err = %v, want context.Canceled", err)
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("jwks fetched %d times, want 1", n)
+	}
+}
+
+func TestJWKSCacheBackoff(t *testing.T) {
+	key := newRSAKey(t)
+	server := newJWKSServer(t)
+	server.set(http.StatusServiceUnavailable)
+
+	cache := NewJWKSCache(server.URL, time.Hour)
+	ctx := context.Background()
+
+	if _, err := cache.Keys(ctx, false); err == nil {
+		t.Fatal("expected error without keys")
+	}
+	// Повторная попытка до истечения паузы не идёт в сеть
+	if _, err := cache.Keys(ctx, true); err == nil {
+		t.Fatal("expected cached error")
+	}
+	if n := server.hits.Load(); n != 1 {
+		t.Fatalf("jwks fetched %d times during backoff, want 1", n)
+	}
+	if cache.failures != 1 || cache.attemptedAt.IsZero() {
+		t.Fatalf("failures = %d, attemptedAt = %v", cache.failures, cache.attemptedAt)
+	}
+
+	// После паузы загрузка повторяется и сбрасывает счётчик
+	cache.retryBase = 0
+	server.set(http.StatusOK, rsaJWK("a", &key.PublicKey))
+	keys, err := cache.Keys(ctx, false)
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("keys = %v, err = %v", keys, err)
+	}
+	if cache.failures != 0 {
+		t.Errorf("failures = %d after success", cache.failures)
+	}
+
+	// Сбой при обновлении не теряет уже загруженный набор
+	cache.ttl = 0
+	server.set(http.StatusInternalServerError)
+	keys, err = cache.Keys(ctx, false)
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("stale keys = %v, err = %v", keys, err)
+	}
+}
+
+func TestJWKSRetryDelay(t *testing.T) {
+	cache := NewJWKSCache("", time.Hour)
+	for failures, want := range map[int]time.Duration{
+		1:  jwksRetryBase,
+		2:  2 * jwksRetryBase,
+		3:  4 * jwksRetryBase,
+		20: jwksRetryMax,
+	} {
+		cache.failures = failures
+		if got := cache.retryDelay(); got != want {
+			t.Errorf("retryDelay after %d failures = %v, want %v", failures, got, want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// internal/cache/cache.go
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// refreshTimeout ограничивает фоновое обновление устаревшей записи
+const refreshTimeout = 30 * time.Second
+
+// Loader загружает значение для ключа при промахе или устаревании
+type Loader[V any] func(ctx context.Context) (V, error)
+
+// Cache — LRU-кеш в памяти процесса с TTL и stale-while-revalidate:
+// свежая запись отдаётся сразу; устаревшая, но моложе ttl+stale —
+// тоже сразу, а в фоне запускается её обновление; старше — загружается заново.
+// Одновременные загрузки одного ключа объединяются в одну.
+type Cache[V any] struct {
+	maxEntries int
+	ttl        time.Duration
+	stale      time.Duration
+
+	mu         sync.Mutex
+	ll         *list.List
+	items      map[string]*list.Element
+	inflight   map[string]*call[V]
+	generation uint64
+}
+
+type entry[V any] struct {
+	key      string
+	value    V
+	storedAt time.Time
+}
+
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+func New[V any](maxEntries int, ttl, stale time.Duration) *Cache[V] {
+	return &Cache[V]{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		stale:      stale,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		inflight:   make(map[string]*call[V]),
+	}
+}
+
+// GetOrLoad возвращает значение из кеша или загружает его через load
+func (c *Cache[V]) GetOrLoad(ctx context.Context, key string, load Loader[V]) (V, error) {
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[V])
+		age := time.Since(e.storedAt)
+		value := e.value
+		if age < c.ttl {
+			c.ll.MoveToFront(el)
+			c.mu.Unlock()
+			return value, nil
+		}
+		if age < c.ttl+c.stale {
+			c.ll.MoveToFront(el)
+			if _, loading := c.inflight[key]; !loading {
+				c.startLoadLocked(key, load)
+			}
+			c.mu.Unlock()
+			return value, nil
+		}
+	}
+
+	cl, loading := c.inflight[key]
+	if !loading {
+		cl = c.startLoadLocked(key, load)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.value, cl.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// startLoadLocked запускает загрузку ключа в отдельной горутине. Загрузка
+// не привязана к контексту запроса: её результат нужен и другим ожидающим.
+func (c *Cache[V]) startLoadLocked(key string, load Loader[V]) *call[V] {
+	cl := &call[V]{done: make(chan struct{})}
+	c.inflight[key] = cl
+	generation := c.generation
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+
+		value, err := load(ctx)
+
+		c.mu.Lock()
+		delete(c.inflight, key)
+		// Если за время загрузки кеш сбросили, результат мог устареть — не сохраняем
+		if err == nil && generation == c.generation {
+			c.storeLocked(key, value)
+		}
+		c.mu.Unlock()
+
+		cl.value, cl.err = value, err
+		close(cl.done)
+	}()
+
+	return cl
+}
+
+func (c *Cache[V]) storeLocked(key string, value V) {
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[V])
+		e.value = value
+		e.storedAt = time.Now()
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&entry[V]{key: key, value: value, storedAt: time.Now()})
+	for c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*entry[V]).key)
+	}
+}
+
+// Delete удаляет ключи из кеша
+func (c *Cache[V]) Delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.ll.Remove(el)
+			delete(c.items, key)
+		}
+	}
+	c.generation++
+}
+
+// DeletePrefix удаляет все ключи с префиксом
+func (c *Cache[V]) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, el := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.ll.Remove(el)
+			delete(c.items, key)
+		}
+	}
+	c.generation++
+}
+
+// Purge очищает кеш полностью
+func (c *Cache[V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+	c.generation++
+}
+
+// Len возвращает число записей
+func (c *Cache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
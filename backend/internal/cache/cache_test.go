@@ -0,0 +1,184 @@
+// internal/cache/cache_test.go
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// counter — загрузчик, который считает вызовы и отдаёт номер вызова
+type counter struct {
+	calls atomic.Int32
+}
+
+func (l *counter) load(ctx context.Context) (int, error) {
+	return int(l.calls.Add(1)), nil
+}
+
+func get(t *testing.T, c *Cache[int], key string, load Loader[int]) int {
+	t.Helper()
+	value, err := c.GetOrLoad(context.Background(), key, load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestCacheLRUEviction(t *testing.T) {
+	c := New[int](2, time.Hour, 0)
+	loads := map[string]*counter{"a": {}, "b": {}, "c": {}}
+
+	get(t, c, "a", loads["a"].load)
+	get(t, c, "b", loads["b"].load)
+	// Обращение к a делает самым старым b
+	get(t, c, "a", loads["a"].load)
+	get(t, c, "c", loads["c"].load)
+
+	if c.Len() != 2 {
+		t.Fatalf("len = %d, want 2", c.Len())
+	}
+	get(t, c, "a", loads["a"].load)
+	get(t, c, "b", loads["b"].load)
+	if n := loads["a"].calls.Load(); n != 1 {
+		t.Errorf("a loaded %d times, want 1", n)
+	}
+	if n := loads["b"].calls.Load(); n != 2 {
+		t.Errorf("evicted b loaded %d times, want 2", n)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	c := New[int](10, 20*time.Millisecond, 0)
+	var l counter
+
+	if v := get(t, c, "k", l.load); v != 1 {
+		t.Fatalf("first value = %d", v)
+	}
+	if v := get(t, c, "k", l.load); v != 1 {
+		t.Fatalf("fresh value = %d, want cached 1", v)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	// Без окна stale просроченная запись загружается заново синхронно
+	if v := get(t, c, "k", l.load); v != 2 {
+		t.Fatalf("expired value = %d, want 2", v)
+	}
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	c := New[int](10, 20*time.Millisecond, time.Hour)
+	var l counter
+	release := make(chan struct{})
+	slow := func(ctx context.Context) (int, error) {
+		if l.calls.Load() > 0 {
+			<-release
+		}
+		return l.load(ctx)
+	}
+
+	get(t, c, "k", slow)
+	time.Sleep(30 * time.Millisecond)
+
+	// Устаревшая запись отдаётся сразу, пока в фоне идёт одно обновление
+	for i := 0; i < 3; i++ {
+		if v := get(t, c, "k", slow); v != 1 {
+			t.Fatalf("stale value = %d, want 1", v)
+		}
+	}
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for get(t, c, "k", slow) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("background refresh did not store the new value")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := l.calls.Load(); n != 2 {
+		t.Errorf("loaded %d times, want 2", n)
+	}
+}
+
+func TestCacheCoalescesLoads(t *testing.T) {
+	c := New[int](10, time.Hour, 0)
+	var l counter
+	release := make(chan struct{})
+	slow := func(ctx context.Context) (int, error) {
+		<-release
+		return l.load(ctx)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := c.GetOrLoad(context.Background(), "k", slow); err != nil || v != 1 {
+				t.Errorf("value = %d, err = %v", v, err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := l.calls.Load(); n != 1 {
+		t.Errorf("loaded %d times, want 1", n)
+	}
+}
+
+func TestCacheErrorsAreNotStored(t *testing.T) {
+	c := New[int](10, time.Hour, 0)
+	fail := errors.New("strapi down")
+
+	if _, err := c.GetOrLoad(context.Background(), "k", func(context.Context) (int, error) { return 0, fail }); !errors.Is(err, fail) {
+		t.Fatalf("err = %v", err)
+	}
+	var l counter
+	if v := get(t, c, "k", l.load); v != 1 {
+		t.Errorf("value after error = %d, want fresh load", v)
+	}
+}
+
+func TestCacheInvalidationDuringLoad(t *testing.T) {
+	c := New[int](10, time.Hour, 0)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan int)
+	go func() {
+		v, _ := c.GetOrLoad(context.Background(), "products:1", func(context.Context) (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+		done <- v
+	}()
+
+	<-started
+	// Сброс во время загрузки: её результат мог устареть и не должен попасть в кеш
+	c.DeletePrefix("products:")
+	close(release)
+	if v := <-done; v != 1 {
+		t.Fatalf("waiting request got %d", v)
+	}
+
+	var l counter
+	if v := get(t, c, "products:1", l.load); v != 1 || l.calls.Load() != 1 {
+		t.Errorf("stale load was stored: value = %d", v)
+	}
+
+	get(t, c, "products:2", l.load)
+	get(t, c, "other", l.load)
+	c.Delete("products:2")
+	if c.Len() != 2 {
+		t.Errorf("len after Delete = %d, want 2", c.Len())
+	}
+	c.Purge()
+	if c.Len() != 0 {
+		t.Errorf("len after Purge = %d", c.Len())
+	}
+}
@@ -0,0 +1,86 @@
+// internal/catalogsync/mapping.go
+package catalogsync
+
+import (
+	"backend/internal/strapi"
+	"backend/internal/woocommerce"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// skuPrefix отличает товары из Strapi от заведённых в WooCommerce вручную
+const skuPrefix = "strapi-"
+
+// productKey — ключ товара в состоянии синхронизации: documentId, для старых записей — id
+func productKey(product strapi.Product) string {
+	if product.DocumentID != "" {
+		return product.DocumentID
+	}
+	return strconv.Itoa(product.ID)
+}
+
+// MapProduct переводит товар Strapi в простой товар WooCommerce.
+// Размеры и цвета вариантов становятся атрибутами, а не вариациями:
+// остатки WooCommerce ведёт по товару целиком.
+func MapProduct(product strapi.Product, mediaBaseURL string, categoryID int) woocommerce.Product {
+	status := "publish"
+	if product.PublishedAt == "" {
+		status = "draft"
+	}
+
+	mapped := woocommerce.Product{
+		Name:         product.Name,
+		Type:         "simple",
+		Status:       status,
+		SKU:          skuPrefix + productKey(product),
+		RegularPrice: strconv.Itoa(product.Price),
+		Description:  product.Description,
+		Categories:   []woocommerce.CategoryID{},
+		Images:       []woocommerce.Image{},
+		Attributes:   []woocommerce.Attribute{},
+		MetaData: []woocommerce.MetaData{
+			{Key: "_strapi_document_id", Value: productKey(product)},
+		},
+	}
+
+	if categoryID > 0 {
+		mapped.Categories = append(mapped.Categories, woocommerce.CategoryID{ID: categoryID})
+	}
+
+	for _, image := range product.Images {
+		mapped.Images = append(mapped.Images, woocommerce.Image{
+			Src:  strapi.MediaURL(mediaBaseURL, image.URL),
+			Name: image.Name,
+			Alt:  image.AlternativeText,
+		})
+	}
+
+	if sizes := product.Sizes(); len(sizes) > 0 {
+		mapped.Attributes = append(mapped.Attributes, woocommerce.Attribute{Name: "Размер", Visible: true, Options: sizes})
+	}
+	if colors := product.Colors(); len(colors) > 0 {
+		mapped.Attributes = append(mapped.Attributes, woocommerce.Attribute{Name: "Цвет", Visible: true, Options: colors})
+	}
+	if brand := strings.TrimSpace(product.Brand.Name); brand != "" {
+		mapped.Attributes = append(mapped.Attributes, woocommerce.Attribute{Name: "Бренд", Visible: true, Options: []string{brand}})
+	}
+
+	if stock, ok := product.Stock(); ok {
+		mapped.ManageStock = true
+		mapped.StockQuantity = &stock
+	}
+
+	return mapped
+}
+
+// productHash — отпечаток товара для WooCommerce: если он не изменился,
+// обновление не отправляется, даже если в Strapi сдвинулся updatedAt.
+// category — путь категории товара, который заменяет в отпечатке её ID.
+func productHash(product woocommerce.Product, category string) string {
+	payload, _ := json.Marshal(product)
+	sum := sha256.Sum256(append(append(payload, '\n'), category...))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,68 @@
+// internal/catalogsync/report.go
+package catalogsync
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Change — товар, который синхронизация создала, обновила или удалила
+type Change struct {
+	DocumentID string `json:"documentId"`
+	Name       string `json:"name,omitempty"`
+	WooID      int    `json:"wooId,omitempty"`
+}
+
+// Failure — товар, который не удалось синхронизировать даже после повторов
+type Failure struct {
+	DocumentID string `json:"documentId"`
+	Name       string `json:"name,omitempty"`
+	Error      string `json:"error"`
+}
+
+// Report — итог одного запуска синхронизации
+type Report struct {
+	Started   time.Time `json:"started"`
+	Finished  time.Time `json:"finished"`
+	Full      bool      `json:"full"`
+	DryRun    bool      `json:"dryRun"`
+	Created   []Change  `json:"created"`
+	Updated   []Change  `json:"updated"`
+	Deleted   []Change  `json:"deleted"`
+	Unchanged int       `json:"unchanged"`
+	Failed    []Failure `json:"failed"`
+}
+
+// String — сводка изменений для лога: счётчики и перечень затронутых товаров
+func (r *Report) String() string {
+	var b strings.Builder
+
+	mode := "инкрементальная"
+	if r.Full {
+		mode = "полная"
+	}
+	if r.DryRun {
+		mode += ", без записи"
+	}
+	fmt.Fprintf(&b, "Синхронизация каталога (%s) за %s: создано %d, обновлено %d, удалено %d, без изменений %d, ошибок %d",
+		mode, r.Finished.Sub(r.Started).Round(time.Millisecond),
+		len(r.Created), len(r.Updated), len(r.Deleted), r.Unchanged, len(r.Failed))
+
+	writeChanges := func(sign string, changes []Change) {
+		for _, change := range changes {
+			fmt.Fprintf(&b, "\n  %s %s %q", sign, change.DocumentID, change.Name)
+			if change.WooID != 0 {
+				fmt.Fprintf(&b, " (WooCommerce #%d)", change.WooID)
+			}
+		}
+	}
+	writeChanges("+", r.Created)
+	writeChanges("~", r.Updated)
+	writeChanges("-", r.Deleted)
+	for _, failure := range r.Failed {
+		fmt.Fprintf(&b, "\n  ! %s %q: %s", failure.DocumentID, failure.Name, failure.Error)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,84 @@
+// internal/catalogsync/state.go
+package catalogsync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State — состояние синхронизации между запусками: соответствие
+// товаров Strapi и WooCommerce и отметка последнего обработанного изменения
+type State struct {
+	// LastSync — updatedAt последнего синхронизированного товара по часам Strapi
+	LastSync   string                   `json:"lastSync,omitempty"`
+	Products   map[string]*ProductState `json:"products"`
+	Categories map[string]int           `json:"categories"` // путь категории -> ID в WooCommerce
+}
+
+// ProductState — товар WooCommerce, соответствующий документу Strapi
+type ProductState struct {
+	WooID     int       `json:"wooId"`
+	Name      string    `json:"name,omitempty"`
+	Hash      string    `json:"hash"`
+	UpdatedAt string    `json:"updatedAt"`
+	SyncedAt  time.Time `json:"syncedAt"`
+}
+
+func newState() *State {
+	return &State{
+		Products:   map[string]*ProductState{},
+		Categories: map[string]int{},
+	}
+}
+
+// LoadState читает состояние из файла; отсутствие файла — первый запуск
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return newState(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("чтение состояния синхронизации: %w", err)
+	}
+
+	state := newState()
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("разбор состояния синхронизации %s: %w", path, err)
+	}
+	if state.Products == nil {
+		state.Products = map[string]*ProductState{}
+	}
+	if state.Categories == nil {
+		state.Categories = map[string]int{}
+	}
+	return state, nil
+}
+
+// Save записывает состояние атомарно: через временный файл и переименование,
+// чтобы прерванный запуск не оставил файл наполовину записанным
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("сохранение состояния синхронизации: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("сохранение состояния синхронизации: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("сохранение состояния синхронизации: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,354 @@
+// internal/catalogsync/sync.go
+package catalogsync
+
+import (
+	"backend/internal/strapi"
+	"backend/internal/woocommerce"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// StrapiSource — чтение каталога из Strapi, реализуется *strapi.Client
+type StrapiSource interface {
+	ListAllProducts(ctx context.Context, query url.Values) ([]strapi.Product, error)
+}
+
+// WooAPI — операции WooCommerce, которые нужны синхронизации;
+// реализуется *woocommerce.Client
+type WooAPI interface {
+	CreateProduct(ctx context.Context, product woocommerce.Product) (*woocommerce.Product, error)
+	UpdateProduct(ctx context.Context, id int, product woocommerce.Product) (*woocommerce.Product, error)
+	DeleteProduct(ctx context.Context, id int) error
+	FindProductBySKU(ctx context.Context, sku string) (*woocommerce.Product, error)
+	EnsureCategory(ctx context.Context, name string, parent int) (int, error)
+}
+
+// Options — параметры запуска синхронизации
+type Options struct {
+	// StatePath — файл с соответствием ID и отметкой последней синхронизации
+	StatePath string
+	// MediaBaseURL — адрес Strapi для относительных ссылок на изображения
+	MediaBaseURL string
+	// Populate — связи товара, которые нужно подгрузить из Strapi
+	Populate []string
+	// CategorySeparator разделяет уровни в строковой категории
+	CategorySeparator string
+
+	// Retries — число повторов при сетевых ошибках, 429 и 5xx;
+	// RetryDelay — пауза перед первым повтором, дальше она удваивается
+	Retries    int
+	RetryDelay time.Duration
+
+	// Full — пройти весь каталог, а не только изменённое с прошлого запуска
+	Full bool
+	// DryRun — посчитать изменения, ничего не записывая
+	DryRun bool
+	// DeleteMissing — убирать из WooCommerce товары, удалённые
+	// или снятые с публикации в Strapi
+	DeleteMissing bool
+}
+
+// defaultRetryDelay — пауза перед первым повтором, если RetryDelay не задан:
+// без неё повторы при 429 и 5xx шли бы подряд без ожидания
+const defaultRetryDelay = time.Second
+
+// Syncer переносит каталог из Strapi в WooCommerce
+type Syncer struct {
+	Strapi  StrapiSource
+	Woo     WooAPI
+	Options Options
+}
+
+func NewSyncer(source StrapiSource, woo WooAPI, opts Options) *Syncer {
+	if opts.CategorySeparator == "" {
+		opts.CategorySeparator = "/"
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = defaultRetryDelay
+	}
+	return &Syncer{Strapi: source, Woo: woo, Options: opts}
+}
+
+// Run выполняет один проход синхронизации и возвращает сводку изменений.
+// Ошибка возвращается, только если проход не удалось начать или сохранить;
+// ошибки отдельных товаров попадают в Report.Failed и повторяются в следующий раз.
+func (s *Syncer) Run(ctx context.Context) (*Report, error) {
+	report := &Report{Started: time.Now(), DryRun: s.Options.DryRun}
+
+	state, err := LoadState(s.Options.StatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	query := sortedByID(strapi.PopulateValues(s.Options.Populate))
+	incremental := !s.Options.Full && state.LastSync != ""
+	report.Full = !incremental
+	if incremental {
+		query.Set("filters[updatedAt][$gt]", state.LastSync)
+	}
+
+	var products []strapi.Product
+	err = s.retry(ctx, func() error {
+		products, err = s.Strapi.ListAllProducts(ctx, query)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var latest, firstFailed time.Time
+	for _, product := range products {
+		if ctx.Err() != nil {
+			break
+		}
+
+		updated, _ := time.Parse(time.RFC3339, product.UpdatedAt)
+		if err := s.syncProduct(ctx, state, product, report); err != nil {
+			report.Failed = append(report.Failed, Failure{
+				DocumentID: productKey(product),
+				Name:       product.Name,
+				Error:      err.Error(),
+			})
+			if firstFailed.IsZero() || updated.Before(firstFailed) {
+				firstFailed = updated
+			}
+			continue
+		}
+		if updated.After(latest) {
+			latest = updated
+		}
+	}
+
+	if s.Options.DeleteMissing && ctx.Err() == nil {
+		present, err := s.presentKeys(ctx, products, incremental)
+		if err != nil {
+			report.Failed = append(report.Failed, Failure{Error: "список товаров для удаления: " + err.Error()})
+		} else {
+			s.deleteMissing(ctx, state, present, report)
+		}
+	}
+
+	// Отметка не должна перескочить через товар с ошибкой — тогда в следующий
+	// раз он попадёт в выборку снова. Прерванный проход отметку не двигает.
+	if ctx.Err() == nil {
+		mark := latest
+		if !firstFailed.IsZero() {
+			mark = firstFailed.Add(-time.Millisecond)
+		}
+		if !mark.IsZero() {
+			state.LastSync = mark.UTC().Format(time.RFC3339Nano)
+		}
+	}
+
+	report.Finished = time.Now()
+	if s.Options.DryRun {
+		return report, ctx.Err()
+	}
+	if err := state.Save(s.Options.StatePath); err != nil {
+		return report, err
+	}
+	return report, ctx.Err()
+}
+
+// syncProduct создаёт или обновляет один товар и записывает результат в state
+func (s *Syncer) syncProduct(ctx context.Context, state *State, product strapi.Product, report *Report) error {
+	key := productKey(product)
+
+	categoryID, err := s.ensureCategory(ctx, state, product.Category)
+	if err != nil {
+		return err
+	}
+
+	mapped := MapProduct(product, s.Options.MediaBaseURL, categoryID)
+	// Отпечаток берёт путь категории, а не её ID: в пробном запуске новая
+	// категория ещё не создана, и ID дал бы отпечаток, отличный от настоящего
+	hash := productHash(MapProduct(product, s.Options.MediaBaseURL, 0),
+		strings.Join(product.Category.Levels(s.Options.CategorySeparator), s.Options.CategorySeparator))
+	change := Change{DocumentID: key, Name: product.Name}
+
+	prev := state.Products[key]
+	if prev != nil && prev.Hash == hash {
+		report.Unchanged++
+		return nil
+	}
+
+	wooID := 0
+	if prev != nil {
+		wooID = prev.WooID
+	} else if !s.Options.DryRun {
+		// Состояние могло потеряться: подхватываем товар, созданный раньше
+		var existing *woocommerce.Product
+		err := s.retry(ctx, func() error {
+			existing, err = s.Woo.FindProductBySKU(ctx, mapped.SKU)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			wooID = existing.ID
+		}
+	}
+
+	if s.Options.DryRun {
+		change.WooID = wooID
+		if wooID == 0 {
+			report.Created = append(report.Created, change)
+		} else {
+			report.Updated = append(report.Updated, change)
+		}
+		return nil
+	}
+
+	var saved *woocommerce.Product
+	if wooID != 0 {
+		err = s.retry(ctx, func() error {
+			saved, err = s.Woo.UpdateProduct(ctx, wooID, mapped)
+			return err
+		})
+		// Товар удалили в WooCommerce вручную — создаём заново
+		if woocommerce.IsNotFound(err) {
+			wooID, err = 0, nil
+		}
+	}
+	if wooID == 0 && err == nil {
+		err = s.retry(ctx, func() error {
+			saved, err = s.Woo.CreateProduct(ctx, mapped)
+			return err
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	if wooID == 0 {
+		change.WooID = saved.ID
+		report.Created = append(report.Created, change)
+	} else {
+		change.WooID = wooID
+		report.Updated = append(report.Updated, change)
+	}
+	state.Products[key] = &ProductState{
+		WooID:     change.WooID,
+		Name:      product.Name,
+		Hash:      hash,
+		UpdatedAt: product.UpdatedAt,
+		SyncedAt:  time.Now().UTC(),
+	}
+	return nil
+}
+
+// ensureCategory возвращает ID категории WooCommerce для категории товара,
+// создавая недостающие уровни дерева
+func (s *Syncer) ensureCategory(ctx context.Context, state *State, term strapi.Term) (int, error) {
+	path := term.Levels(s.Options.CategorySeparator)
+	parent := 0
+	for depth, name := range path {
+		key := strings.Join(path[:depth+1], s.Options.CategorySeparator)
+		if id, ok := state.Categories[key]; ok {
+			parent = id
+			continue
+		}
+		if s.Options.DryRun {
+			return 0, nil
+		}
+
+		var id int
+		err := s.retry(ctx, func() error {
+			var err error
+			id, err = s.Woo.EnsureCategory(ctx, name, parent)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+		state.Categories[key] = id
+		parent = id
+	}
+	return parent, nil
+}
+
+// presentKeys возвращает ключи всех опубликованных товаров Strapi.
+// При полном проходе они уже загружены, иначе запрашивается лёгкий список.
+func (s *Syncer) presentKeys(ctx context.Context, products []strapi.Product, incremental bool) (map[string]bool, error) {
+	if incremental {
+		query := sortedByID(url.Values{"fields[0]": {"documentId"}})
+		err := s.retry(ctx, func() error {
+			var err error
+			products, err = s.Strapi.ListAllProducts(ctx, query)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	present := make(map[string]bool, len(products))
+	for _, product := range products {
+		present[productKey(product)] = true
+	}
+	return present, nil
+}
+
+// deleteMissing убирает из WooCommerce товары, которых больше нет среди опубликованных
+func (s *Syncer) deleteMissing(ctx context.Context, state *State, present map[string]bool, report *Report) {
+	for key, product := range state.Products {
+		if present[key] || ctx.Err() != nil {
+			continue
+		}
+
+		change := Change{DocumentID: key, Name: product.Name, WooID: product.WooID}
+		if !s.Options.DryRun {
+			err := s.retry(ctx, func() error {
+				return s.Woo.DeleteProduct(ctx, product.WooID)
+			})
+			if err != nil && !woocommerce.IsNotFound(err) {
+				report.Failed = append(report.Failed, Failure{DocumentID: key, Name: product.Name, Error: err.Error()})
+				continue
+			}
+			delete(state.Products, key)
+		}
+		report.Deleted = append(report.Deleted, change)
+	}
+}
+
+// sortedByID задаёт постраничному обходу порядок по id: без явной сортировки
+// Strapi не гарантирует порядок, и правка товара во время обхода может
+// перенести его на другую страницу — тогда он пропускается или приходит дважды
+func sortedByID(query url.Values) url.Values {
+	query.Set("sort[0]", "id:asc")
+	return query
+}
+
+// retry повторяет fn при временных ошибках с удвоением паузы
+func (s *Syncer) retry(ctx context.Context, fn func() error) error {
+	delay := s.Options.RetryDelay
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= s.Options.Retries || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// isRetryable: сетевые ошибки, 429 и 5xx от Strapi или WooCommerce
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if status := strapi.StatusCode(err); status != 0 {
+		return status == http.StatusTooManyRequests || status >= 500
+	}
+	return woocommerce.IsRetryable(err)
+}
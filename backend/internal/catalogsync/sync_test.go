@@ -0,0 +1,345 @@
+// internal/catalogsync/sync_test.go
+package catalogsync
+
+import (
+	"backend/internal/config"
+	"backend/internal/strapi"
+	"backend/internal/woocommerce"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeStrapi — каталог Strapi в памяти с фильтром по updatedAt
+type fakeStrapi struct {
+	products []strapi.Product
+	queries  []url.Values
+}
+
+func (f *fakeStrapi) ListAllProducts(_ context.Context, query url.Values) ([]strapi.Product, error) {
+	f.queries = append(f.queries, query)
+	since := query.Get("filters[updatedAt][$gt]")
+
+	var out []strapi.Product
+	for _, product := range f.products {
+		if since == "" || product.UpdatedAt > since {
+			out = append(out, product)
+		}
+	}
+	return out, nil
+}
+
+// fakeWoo — REST API WooCommerce в памяти поверх httptest.Server
+type fakeWoo struct {
+	mu         sync.Mutex
+	products   map[int]woocommerce.Product
+	categories []woocommerce.Category
+	nextID     int
+	// failNext — сколько следующих запросов ответить 503
+	failNext int
+	requests int
+}
+
+func newFakeWoo(t *testing.T) (*fakeWoo, *woocommerce.Client) {
+	t.Helper()
+
+	woo := &fakeWoo{products: map[int]woocommerce.Product{}, nextID: 500}
+	server := httptest.NewServer(woo)
+	t.Cleanup(server.Close)
+
+	client := woocommerce.NewClient(&config.Config{
+		WooCommerceURL:     server.URL,
+		WooCommerceKey:     "ck",
+		WooCommerceSecret:  "cs",
+		WooCommerceTimeout: 5 * time.Second,
+	})
+	return woo, client
+}
+
+func (w *fakeWoo) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.requests++
+	if w.failNext > 0 {
+		w.failNext--
+		http.Error(rw, `{"code":"unavailable","message":"try later"}`, http.StatusServiceUnavailable)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/wp-json/wc/v3")
+	switch {
+	case path == "/products/categories" && r.Method == http.MethodGet:
+		var found []woocommerce.Category
+		for _, category := range w.categories {
+			if category.Name == r.URL.Query().Get("search") && strconv.Itoa(category.Parent) == r.URL.Query().Get("parent") {
+				found = append(found, category)
+			}
+		}
+		writeJSON(rw, http.StatusOK, found)
+
+	case path == "/products/categories" && r.Method == http.MethodPost:
+		var category woocommerce.Category
+		_ = json.NewDecoder(r.Body).Decode(&category)
+		w.nextID++
+		category.ID = w.nextID
+		w.categories = append(w.categories, category)
+		writeJSON(rw, http.StatusCreated, category)
+
+	case path == "/products" && r.Method == http.MethodGet:
+		found := []woocommerce.Product{}
+		for _, product := range w.products {
+			if product.SKU == r.URL.Query().Get("sku") {
+				found = append(found, product)
+			}
+		}
+		writeJSON(rw, http.StatusOK, found)
+
+	case path == "/products" && r.Method == http.MethodPost:
+		var product woocommerce.Product
+		_ = json.NewDecoder(r.Body).Decode(&product)
+		w.nextID++
+		product.ID = w.nextID
+		w.products[product.ID] = product
+		writeJSON(rw, http.StatusCreated, product)
+
+	case strings.HasPrefix(path, "/products/"):
+		id, _ := strconv.Atoi(strings.TrimPrefix(path, "/products/"))
+		if _, ok := w.products[id]; !ok {
+			writeJSON(rw, http.StatusNotFound, map[string]string{"code": "woocommerce_rest_product_invalid_id"})
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			var product woocommerce.Product
+			_ = json.NewDecoder(r.Body).Decode(&product)
+			product.ID = id
+			w.products[id] = product
+			writeJSON(rw, http.StatusOK, product)
+		case http.MethodDelete:
+			product := w.products[id]
+			delete(w.products, id)
+			writeJSON(rw, http.StatusOK, product)
+		}
+
+	default:
+		http.NotFound(rw, r)
+	}
+}
+
+// skus возвращает артикулы товаров в WooCommerce по алфавиту
+func (w *fakeWoo) skus() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var skus []string
+	for _, product := range w.products {
+		skus = append(skus, product.SKU)
+	}
+	sort.Strings(skus)
+	return skus
+}
+
+func writeJSON(rw http.ResponseWriter, status int, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(v)
+}
+
+func testProduct(id int, documentID, updatedAt string, price int) strapi.Product {
+	return strapi.Product{
+		ID:          id,
+		DocumentID:  documentID,
+		Name:        "Товар " + documentID,
+		Price:       price,
+		Category:    strapi.Term{Name: "Одежда/Куртки"},
+		UpdatedAt:   updatedAt,
+		PublishedAt: updatedAt,
+	}
+}
+
+func newTestSyncer(t *testing.T, source *fakeStrapi, woo WooAPI, opts Options) *Syncer {
+	t.Helper()
+	if opts.StatePath == "" {
+		opts.StatePath = filepath.Join(t.TempDir(), "state.json")
+	}
+	return NewSyncer(source, woo, opts)
+}
+
+func run(t *testing.T, syncer *Syncer) *Report {
+	t.Helper()
+	report, err := syncer.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestFullSyncCreatesProductsAndCategories(t *testing.T) {
+	source := &fakeStrapi{products: []strapi.Product{
+		testProduct(1, "a", "2026-01-01T10:00:00Z", 1000),
+		testProduct(2, "b", "2026-01-02T10:00:00Z", 2000),
+	}}
+	woo, client := newFakeWoo(t)
+	syncer := newTestSyncer(t, source, client, Options{Full: true})
+
+	report := run(t, syncer)
+	if len(report.Created) != 2 || len(report.Failed) != 0 {
+		t.Fatalf("report = %s", report)
+	}
+	if got := woo.skus(); strings.Join(got, ",") != "strapi-a,strapi-b" {
+		t.Fatalf("woo skus = %v", got)
+	}
+	if len(woo.categories) != 2 || woo.categories[1].Parent != woo.categories[0].ID {
+		t.Fatalf("categories = %+v, want Одежда > Куртки", woo.categories)
+	}
+	if order := source.queries[0].Get("sort[0]"); order != "id:asc" {
+		t.Fatalf("sort = %q, want stable id:asc", order)
+	}
+
+	report = run(t, syncer)
+	if report.Unchanged != 2 || len(report.Created)+len(report.Updated) != 0 {
+		t.Fatalf("second run: %s", report)
+	}
+}
+
+func TestIncrementalSyncSendsOnlyChanges(t *testing.T) {
+	source := &fakeStrapi{products: []strapi.Product{
+		testProduct(1, "a", "2026-01-01T10:00:00Z", 1000),
+		testProduct(2, "b", "2026-01-02T10:00:00Z", 2000),
+	}}
+	woo, client := newFakeWoo(t)
+	syncer := newTestSyncer(t, source, client, Options{})
+	run(t, syncer)
+
+	source.products[0] = testProduct(1, "a", "2026-01-03T10:00:00Z", 1500)
+	requests := woo.requests
+	report := run(t, syncer)
+
+	last := source.queries[len(source.queries)-1]
+	if since := last.Get("filters[updatedAt][$gt]"); !strings.HasPrefix(since, "2026-01-02T10:00:00") {
+		t.Fatalf("incremental filter = %q", since)
+	}
+	if report.Full || len(report.Updated) != 1 || report.Updated[0].DocumentID != "a" {
+		t.Fatalf("report = %s", report)
+	}
+	if woo.requests-requests != 1 {
+		t.Fatalf("woo requests = %d, want one update", woo.requests-requests)
+	}
+	for _, product := range woo.products {
+		if product.SKU == "strapi-a" && product.RegularPrice != "1500" {
+			t.Fatalf("price = %s, want 1500", product.RegularPrice)
+		}
+	}
+}
+
+func TestSyncRetriesTransientErrors(t *testing.T) {
+	source := &fakeStrapi{products: []strapi.Product{testProduct(1, "a", "2026-01-01T10:00:00Z", 1000)}}
+	woo, client := newFakeWoo(t)
+	woo.failNext = 2
+	syncer := newTestSyncer(t, source, client, Options{Retries: 3, RetryDelay: time.Millisecond})
+
+	report := run(t, syncer)
+	if len(report.Created) != 1 || len(report.Failed) != 0 {
+		t.Fatalf("report = %s", report)
+	}
+}
+
+func TestSyncFailureKeepsProductForNextRun(t *testing.T) {
+	source := &fakeStrapi{products: []strapi.Product{
+		testProduct(1, "a", "2026-01-01T10:00:00Z", 1000),
+	}}
+	woo, client := newFakeWoo(t)
+	syncer := newTestSyncer(t, source, client, Options{Retries: 1, RetryDelay: time.Millisecond})
+
+	woo.failNext = 100
+	report := run(t, syncer)
+	if len(report.Failed) != 1 {
+		t.Fatalf("report = %s, want one failure", report)
+	}
+
+	woo.failNext = 0
+	report = run(t, syncer)
+	if len(report.Created) != 1 {
+		t.Fatalf("retry run: %s, want the failed product created", report)
+	}
+}
+
+func TestDeleteMissingRemovesUnpublishedProducts(t *testing.T) {
+	source := &fakeStrapi{products: []strapi.Product{
+		testProduct(1, "a", "2026-01-01T10:00:00Z", 1000),
+		testProduct(2, "b", "2026-01-02T10:00:00Z", 2000),
+	}}
+	woo, client := newFakeWoo(t)
+	syncer := newTestSyncer(t, source, client, Options{DeleteMissing: true})
+	run(t, syncer)
+
+	source.products = source.products[:1]
+	report := run(t, syncer)
+	if len(report.Deleted) != 1 || report.Deleted[0].DocumentID != "b" {
+		t.Fatalf("report = %s", report)
+	}
+	if got := woo.skus(); strings.Join(got, ",") != "strapi-a" {
+		t.Fatalf("woo skus = %v, want only strapi-a", got)
+	}
+	// Инкрементальный проход берёт полный список ключей отдельным запросом
+	last := source.queries[len(source.queries)-1]
+	if last.Get("fields[0]") != "documentId" || last.Get("sort[0]") != "id:asc" {
+		t.Fatalf("present keys query = %v", last)
+	}
+}
+
+func TestDryRunMatchesRealRun(t *testing.T) {
+	source := &fakeStrapi{products: []strapi.Product{
+		testProduct(1, "a", "2026-01-01T10:00:00Z", 1000),
+	}}
+	woo, client := newFakeWoo(t)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	dryRun := newTestSyncer(t, source, client, Options{StatePath: statePath, Full: true, DryRun: true})
+	live := newTestSyncer(t, source, client, Options{StatePath: statePath, Full: true})
+
+	report := run(t, dryRun)
+	if len(report.Created) != 1 || len(woo.categories) != 0 || len(woo.products) != 0 {
+		t.Fatalf("dry run: %s, woo categories = %d, products = %d", report, len(woo.categories), len(woo.products))
+	}
+	run(t, live)
+
+	// Категории нет в состоянии (новая или потерянная запись) — пробный
+	// запуск не может узнать её ID, но отпечаток товара от этого не меняется
+	state, err := LoadState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Categories = map[string]int{}
+	if err := state.Save(statePath); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := woo.requests
+	report = run(t, dryRun)
+	if report.Unchanged != 1 || len(report.Updated)+len(report.Created) != 0 {
+		t.Fatalf("dry run after real run: %s, want unchanged", report)
+	}
+	if woo.requests != requests {
+		t.Fatalf("dry run sent %d requests to WooCommerce", woo.requests-requests)
+	}
+	if report = run(t, live); report.Unchanged != 1 {
+		t.Fatalf("real run: %s, want unchanged like the dry run", report)
+	}
+}
+
+func TestSyncerDefaultsRetryDelay(t *testing.T) {
+	syncer := NewSyncer(&fakeStrapi{}, nil, Options{Retries: 3})
+	if syncer.Options.RetryDelay <= 0 {
+		t.Fatalf("RetryDelay = %v, want a non-zero default", syncer.Options.RetryDelay)
+	}
+}
@@ -2,37 +2,297 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// InsecureJWTSecret — общеизвестный секрет JWT для локальной разработки.
+// С ним любой может подписать токен с ролью admin, поэтому LoadConfig
+// принимает его только при ALLOW_INSECURE_JWT_SECRET=true.
+const InsecureJWTSecret = "your_jwt_secret"
+
 type Config struct {
-	StrapiURL    string
-	FrontendURL  string
-	JWTSecret    string
-	APIProxyPort string
+	StrapiURL      string
+	StrapiAPIToken string
+	StrapiTimeout  time.Duration
+	// ProductPopulate — связи, которые подгружаются в карточку товара
+	ProductPopulate []string
+
+	CatalogTaxonomyTTL      time.Duration
+	CatalogCategorySep      string
+	CatalogTaxonomyPopulate []string
+	CatalogCacheTTL         time.Duration
+	CatalogCacheStale       time.Duration
+	CatalogCacheSize        int
+	CatalogPriceBuckets     int
+
+	// Cache-Control ответов каталога по маршрутам
+	CacheControlProducts string
+	CacheControlProduct  string
+	CacheControlTaxonomy string
+	CacheControlFeeds    string
+	CacheControlMedia    string
+
+	SearchReindexInterval time.Duration
+	SearchPopulate        []string
+
+	StrapiWebhookSecret string
+	FrontendURL         string // один или несколько источников через запятую
+	JWTSecret           string
+	// JWTAllowInsecureSecret разрешает InsecureJWTSecret; только для разработки
+	JWTAllowInsecureSecret bool
+	APIProxyPort           string
+
+	JWTAlgorithms     []string
+	JWTPublicKeyFiles []string
+	JWKSURL           string
+	JWKSCacheTTL      time.Duration
+	JWTIssuer         string
+	JWTAudience       string
+
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	RedisURL    string
+	RedisPrefix string
+
+	RateLimits map[string]string
+	// TrustedProxies — адреса и подсети прокси, которым можно верить в X-Forwarded-For;
+	// пусто — IP клиента берётся из соединения
+	TrustedProxies []string
+
+	CORSOriginsFile string
+	CORSMaxAge      time.Duration
+
+	LoginMaxAttempts   int
+	LoginMaxIPAttempts int
+	LoginLockWindow    time.Duration
+	LoginBackoffBase   time.Duration
+	LoginBackoffMax    time.Duration
+
+	WooCommerceURL     string
+	WooCommerceKey     string
+	WooCommerceSecret  string
+	WooCommerceTimeout time.Duration
+
+	CatalogSyncState         string
+	CatalogSyncPopulate      []string
+	CatalogSyncRetries       int
+	CatalogSyncRetryDelay    time.Duration
+	CatalogSyncDeleteMissing bool
+
+	CartProductPopulate []string
+	CartMaxItemQuantity int
+	CartStorePrice      bool
+	CartCurrency        string
+	CartShippingCost    int
+	CartFreeShipping    int
+
+	GuestCartSecret         string
+	GuestCartTTL            time.Duration
+	GuestCartMaxItems       int
+	GuestCartCookie         string
+	GuestCartCookieSecure   bool
+	GuestCartCookieSameSite string
+
+	MediaPublicURL      string
+	MediaCacheDir       string
+	MediaCacheSizeMB    int
+	MediaMaxDimension   int
+	MediaDefaultQuality int
+	MediaWidths         []int
+	MediaQualities      []int
+	MediaMaxSourceMB    int
+	MediaCWebP          string
+
+	FeedsDir       string
+	FeedsInterval  time.Duration
+	FeedsPopulate  []string
+	FeedShopName   string
+	FeedCompany    string
+	FeedShopURL    string
+	FeedProductURL string
+	FeedCurrency   string
+
+	BudibaseURL string
+	AdminPrefix string
+	AdminRole   string
+	// Cookie сессии админки: браузер не передаёт Bearer-заголовок при загрузке страниц Budibase
+	AdminSessionCookie       string
+	AdminSessionCookieSecure bool
+
+	RoleClaim       string
+	RolePermissions string
 }
 
-func LoadConfig() *Config {
+func LoadConfig() (*Config, error) {
 	err := godotenv.Load()
 	if err != nil {
 		log.Println("No .env file found, using environment variables")
 	}
 
 	config := &Config{
-		StrapiURL:    getEnv("STRAPI_URL", "http://strapi:1337"),
-		FrontendURL:  getEnv("FRONTEND_URL", "http://localhost:3000"),
-		JWTSecret:    getEnv("JWT_SECRET", "your_jwt_secret"),
-		APIProxyPort: getEnv("API_PROXY_PORT", "8000"),
+		StrapiURL:       getEnv("STRAPI_URL", "http://strapi:1337"),
+		StrapiAPIToken:  getEnv("STRAPI_API_TOKEN", ""),
+		StrapiTimeout:   getEnvDuration("STRAPI_TIMEOUT", 10*time.Second),
+		ProductPopulate: getEnvList("STRAPI_PRODUCT_POPULATE", "image,related.image,variants"),
+
+		CatalogTaxonomyTTL:      getEnvDuration("CATALOG_TAXONOMY_TTL", 5*time.Minute),
+		CatalogCategorySep:      getEnv("CATALOG_CATEGORY_SEPARATOR", "/"),
+		CatalogTaxonomyPopulate: getEnvList("STRAPI_TAXONOMY_POPULATE", ""),
+		CatalogCacheTTL:         getEnvDuration("CATALOG_CACHE_TTL", time.Minute),
+		CatalogCacheStale:       getEnvDuration("CATALOG_CACHE_STALE", 10*time.Minute),
+		CatalogCacheSize:        getEnvInt("CATALOG_CACHE_SIZE", 1000),
+		CatalogPriceBuckets:     getEnvInt("CATALOG_PRICE_BUCKETS", 5),
+
+		CacheControlProducts: getEnv("CACHE_CONTROL_PRODUCTS", "public, max-age=60, stale-while-revalidate=300"),
+		CacheControlProduct:  getEnv("CACHE_CONTROL_PRODUCT", "public, max-age=300, stale-while-revalidate=600"),
+		CacheControlTaxonomy: getEnv("CACHE_CONTROL_TAXONOMY", "public, max-age=600"),
+		CacheControlFeeds:    getEnv("CACHE_CONTROL_FEEDS", "public, max-age=900"),
+		CacheControlMedia:    getEnv("CACHE_CONTROL_MEDIA", "public, max-age=604800"),
+
+		SearchReindexInterval: getEnvDuration("SEARCH_REINDEX_INTERVAL", 10*time.Minute),
+		SearchPopulate:        getEnvList("STRAPI_SEARCH_POPULATE", "image"),
+
+		StrapiWebhookSecret: getEnv("STRAPI_WEBHOOK_SECRET", ""),
+		FrontendURL:         getEnv("FRONTEND_URL", "http://localhost:3000"),
+		JWTSecret:           getEnv("JWT_SECRET", ""),
+		APIProxyPort:        getEnv("API_PROXY_PORT", "8000"),
+
+		JWTAllowInsecureSecret: getEnvBool("ALLOW_INSECURE_JWT_SECRET", false),
+		JWTPublicKeyFiles:      getEnvList("JWT_PUBLIC_KEY_FILES", ""),
+		JWKSURL:                getEnv("JWT_JWKS_URL", ""),
+		JWKSCacheTTL:           getEnvDuration("JWT_JWKS_TTL", 10*time.Minute),
+		JWTIssuer:              getEnv("JWT_ISSUER", ""),
+		JWTAudience:            getEnv("JWT_AUDIENCE", ""),
+
+		AccessTokenTTL:  getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+		RedisURL:    getEnv("REDIS_URL", ""),
+		RedisPrefix: getEnv("REDIS_PREFIX", "gateway:"),
+
+		// Формат лимитов ulule/limiter: "<количество>-<S|M|H|D>"
+		RateLimits: map[string]string{
+			"global":  getEnv("RATE_LIMIT_GLOBAL", "300-M"),
+			"default": getEnv("RATE_LIMIT_DEFAULT", "100-M"),
+			"catalog": getEnv("RATE_LIMIT_CATALOG", "200-M"),
+			"cart":    getEnv("RATE_LIMIT_CART", "60-M"),
+			"orders":  getEnv("RATE_LIMIT_ORDERS", "10-M"),
+			"login":   getEnv("RATE_LIMIT_LOGIN", "10-M"),
+		},
+		TrustedProxies: getEnvList("TRUSTED_PROXIES", ""),
+
+		CORSOriginsFile: getEnv("CORS_ORIGINS_FILE", ""),
+		CORSMaxAge:      getEnvDuration("CORS_MAX_AGE", 10*time.Minute),
+
+		LoginMaxAttempts:   getEnvInt("LOGIN_MAX_ATTEMPTS", 5),
+		LoginMaxIPAttempts: getEnvInt("LOGIN_MAX_IP_ATTEMPTS", 20),
+		LoginLockWindow:    getEnvDuration("LOGIN_LOCK_WINDOW", 15*time.Minute),
+		LoginBackoffBase:   getEnvDuration("LOGIN_BACKOFF_BASE", 500*time.Millisecond),
+		LoginBackoffMax:    getEnvDuration("LOGIN_BACKOFF_MAX", 8*time.Second),
+
+		WooCommerceURL:     getEnv("WOOCOMMERCE_URL", "http://wordpress:80"),
+		WooCommerceKey:     getEnv("WOOCOMMERCE_KEY", ""),
+		WooCommerceSecret:  getEnv("WOOCOMMERCE_SECRET", ""),
+		WooCommerceTimeout: getEnvDuration("WOOCOMMERCE_TIMEOUT", 30*time.Second),
+
+		CatalogSyncState:         getEnv("CATALOG_SYNC_STATE", "catalog-sync-state.json"),
+		CatalogSyncPopulate:      getEnvList("CATALOG_SYNC_POPULATE", "image,variants"),
+		CatalogSyncRetries:       getEnvInt("CATALOG_SYNC_RETRIES", 3),
+		CatalogSyncRetryDelay:    getEnvDuration("CATALOG_SYNC_RETRY_DELAY", 2*time.Second),
+		CatalogSyncDeleteMissing: getEnvBool("CATALOG_SYNC_DELETE_MISSING", true),
+
+		CartProductPopulate: getEnvList("CART_PRODUCT_POPULATE", "image,variants"),
+		CartMaxItemQuantity: getEnvInt("CART_MAX_ITEM_QUANTITY", 99),
+		CartStorePrice:      getEnvBool("CART_STORE_PRICE", false),
+		CartCurrency:        getEnv("CART_CURRENCY", "RUB"),
+		CartShippingCost:    getEnvInt("CART_SHIPPING_COST", 0),
+		CartFreeShipping:    getEnvInt("CART_FREE_SHIPPING_FROM", 0),
+
+		GuestCartSecret:         getEnv("GUEST_CART_SECRET", ""),
+		GuestCartTTL:            getEnvDuration("GUEST_CART_TTL", 30*24*time.Hour),
+		GuestCartMaxItems:       getEnvInt("GUEST_CART_MAX_ITEMS", 50),
+		GuestCartCookie:         getEnv("GUEST_CART_COOKIE", "guest_cart"),
+		GuestCartCookieSecure:   getEnvBool("GUEST_CART_COOKIE_SECURE", false),
+		GuestCartCookieSameSite: getEnv("GUEST_CART_COOKIE_SAMESITE", "lax"),
+
+		// Пусто — ссылки на /uploads в ответах API не переписываются
+		MediaPublicURL:      getEnv("MEDIA_PUBLIC_URL", ""),
+		MediaCacheDir:       getEnv("MEDIA_CACHE_DIR", "media-cache"),
+		MediaCacheSizeMB:    getEnvInt("MEDIA_CACHE_SIZE_MB", 1024),
+		MediaMaxDimension:   getEnvInt("MEDIA_MAX_DIMENSION", 2560),
+		MediaDefaultQuality: getEnvInt("MEDIA_DEFAULT_QUALITY", 80),
+		MediaWidths:         getEnvIntList("MEDIA_WIDTHS", "64,128,256,384,512,768,1024,1280,1600,1920,2560"),
+		MediaQualities:      getEnvIntList("MEDIA_QUALITIES", "50,65,80,90"),
+		MediaMaxSourceMB:    getEnvInt("MEDIA_MAX_SOURCE_MB", 20),
+		MediaCWebP:          getEnv("MEDIA_CWEBP", "cwebp"),
+
+		FeedsDir:       getEnv("FEEDS_DIR", "feeds"),
+		FeedsInterval:  getEnvDuration("FEEDS_INTERVAL", time.Hour),
+		FeedsPopulate:  getEnvList("FEEDS_POPULATE", "image,variants"),
+		FeedShopName:   getEnv("FEED_SHOP_NAME", "Store"),
+		FeedCompany:    getEnv("FEED_COMPANY", ""),
+		FeedShopURL:    getEnv("FEED_SHOP_URL", "http://localhost:3000"),
+		FeedProductURL: getEnv("FEED_PRODUCT_URL", "http://localhost:3000/product/{id}"),
+		FeedCurrency:   getEnv("FEED_CURRENCY", "RUB"),
+
+		BudibaseURL: getEnv("BUDIBASE_URL", "http://budibase:80"), // Адрес Budibase внутри Docker сети
+		AdminPrefix: getEnv("ADMIN_PATH_PREFIX", "/admin"),
+		AdminRole:   getEnv("ADMIN_ROLE", "admin"),
+
+		AdminSessionCookie:       getEnv("ADMIN_SESSION_COOKIE", "admin_session"),
+		AdminSessionCookieSecure: getEnvBool("ADMIN_SESSION_COOKIE_SECURE", true),
+
+		RoleClaim:       getEnv("JWT_ROLE_CLAIM", "role"),
+		RolePermissions: getEnv("ROLE_PERMISSIONS", "admin=*;manager=orders:read,orders:manage,catalog:edit"),
 	}
 
-	if config.JWTSecret == "" {
-		log.Fatal("JWT_SECRET is required")
+	if err := config.loadJWTKeys(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// loadJWTKeys проверяет секрет JWT и выбирает алгоритмы по умолчанию
+func (config *Config) loadJWTKeys() error {
+	external := len(config.JWTPublicKeyFiles) > 0 || config.JWKSURL != ""
+
+	if config.JWTSecret == "" && !external {
+		if !config.JWTAllowInsecureSecret {
+			return errors.New("JWT_SECRET, JWT_PUBLIC_KEY_FILES or JWT_JWKS_URL is required")
+		}
+		config.JWTSecret = InsecureJWTSecret
+	}
+	if config.JWTSecret == InsecureJWTSecret {
+		if !config.JWTAllowInsecureSecret {
+			return errors.New("JWT_SECRET is the public default; set a real secret or ALLOW_INSECURE_JWT_SECRET=true for local development")
+		}
+		log.Println("JWT_SECRET is the insecure default, allowed by ALLOW_INSECURE_JWT_SECRET")
 	}
 
-	return config
+	// С внешними ключами HS256 по умолчанию выключен: общий секрет не должен
+	// открывать второй путь к подписи токенов, которым верит RBAC
+	defaultAlgorithms := "HS256"
+	if external {
+		defaultAlgorithms = "RS256,ES256"
+	}
+	config.JWTAlgorithms = getEnvList("JWT_ALGORITHMS", defaultAlgorithms)
+
+	hs256 := false
+	for _, alg := range config.JWTAlgorithms {
+		hs256 = hs256 || alg == "HS256"
+	}
+	if !hs256 && config.JWTSecret != "" {
+		log.Println("HS256 is not in JWT_ALGORITHMS: access tokens issued by /api/auth/login will be rejected")
+	}
+	return nil
 }
 
 func getEnv(key, defaultVal string) string {
@@ -41,3 +301,67 @@ func getEnv(key, defaultVal string) string {
 	}
 	return defaultVal
 }
+
+// getEnvList разбирает список значений через запятую, пропуская пустые
+func getEnvList(key, defaultVal string) []string {
+	var list []string
+	for _, item := range strings.Split(getEnv(key, defaultVal), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvIntList разбирает список целых чисел через запятую, пропуская некорректные
+func getEnvIntList(key, defaultVal string) []int {
+	var list []int
+	for _, item := range getEnvList(key, defaultVal) {
+		n, err := strconv.Atoi(item)
+		if err != nil || n < 1 {
+			log.Printf("Invalid integer %q in %s, skipping", item, key)
+			continue
+		}
+		list = append(list, n)
+	}
+	return list
+}
+
+func getEnvInt(key string, defaultVal int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultVal
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid integer in %s: %v, using %d", key, err, defaultVal)
+		return defaultVal
+	}
+	return n
+}
+
+func getEnvBool(key string, defaultVal bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultVal
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid boolean in %s: %v, using %t", key, err, defaultVal)
+		return defaultVal
+	}
+	return b
+}
+
+func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultVal
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration in %s: %v, using %s", key, err, defaultVal)
+		return defaultVal
+	}
+	return d
+}
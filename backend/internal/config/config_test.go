@@ -0,0 +1,55 @@
+// internal/config/config_test.go
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigRejectsInsecureJWTSecret(t *testing.T) {
+	cases := []struct {
+		name       string
+		env        map[string]string
+		wantErr    bool
+		algorithms string
+	}{
+		{"nothing configured", map[string]string{}, true, ""},
+		{"public default", map[string]string{"JWT_SECRET": InsecureJWTSecret}, true, ""},
+		{"dev opt-out", map[string]string{"ALLOW_INSECURE_JWT_SECRET": "true"}, false, "HS256"},
+		{"real secret", map[string]string{"JWT_SECRET": "s3cr3t-from-vault"}, false, "HS256"},
+		{"jwks", map[string]string{"JWT_JWKS_URL": "https://idp.example.com/jwks"}, false, "RS256,ES256"},
+		{"explicit algorithms", map[string]string{
+			"JWT_JWKS_URL":   "https://idp.example.com/jwks",
+			"JWT_SECRET":     "s3cr3t-from-vault",
+			"JWT_ALGORITHMS": "HS256,RS256",
+		}, false, "HS256,RS256"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, key := range []string{"JWT_SECRET", "ALLOW_INSECURE_JWT_SECRET", "JWT_JWKS_URL", "JWT_PUBLIC_KEY_FILES", "JWT_ALGORITHMS"} {
+				// t.Setenv восстановит прежнее значение после теста
+				t.Setenv(key, "")
+				if value, ok := tc.env[key]; ok {
+					os.Setenv(key, value)
+				} else {
+					os.Unsetenv(key)
+				}
+			}
+
+			cfg, err := LoadConfig()
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("LoadConfig succeeded with secret %q", cfg.JWTSecret)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(cfg.JWTAlgorithms, ","); got != tc.algorithms {
+				t.Fatalf("algorithms = %s, want %s", got, tc.algorithms)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+// internal/feeds/feeds.go
+package feeds
+
+import (
+	"backend/internal/config"
+	"backend/internal/strapi"
+	"bufio"
+	"context"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Format — формат выгрузки каталога
+type Format string
+
+const (
+	// FormatYML — Яндекс Маркет (YML)
+	FormatYML Format = "yml"
+	// FormatGoogle — Google Merchant Center (RSS 2.0)
+	FormatGoogle Format = "google"
+)
+
+// Formats — все поддерживаемые форматы
+var Formats = []Format{FormatYML, FormatGoogle}
+
+// FileName — имя файла выгрузки в каталоге фидов
+func (f Format) FileName() string {
+	if f == FormatGoogle {
+		return "google.xml"
+	}
+	return "yml.xml"
+}
+
+// ParseFormat проверяет название формата
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("неизвестный формат фида %q", name)
+}
+
+// ProductSource — чтение каталога из Strapi, реализуется *strapi.Client
+type ProductSource interface {
+	ListAllProducts(ctx context.Context, query url.Values) ([]strapi.Product, error)
+}
+
+// Options — сведения о магазине и адреса для ссылок в фиде
+type Options struct {
+	ShopName string
+	Company  string
+	// ShopURL — адрес витрины
+	ShopURL string
+	// ProductURL — шаблон ссылки на карточку, {id} заменяется на documentId
+	ProductURL string
+	// MediaBaseURL — адрес Strapi для относительных ссылок на изображения
+	MediaBaseURL string
+	// Currency — код валюты цен в каталоге (ISO 4217)
+	Currency string
+	// CategorySeparator разделяет уровни в строковой категории
+	CategorySeparator string
+	// Populate — связи товара, которые нужно подгрузить из Strapi
+	Populate []string
+}
+
+// ConfigOptions собирает параметры фида из конфигурации; mediaBaseURL — адрес Strapi
+func ConfigOptions(cfg *config.Config, mediaBaseURL string) Options {
+	return Options{
+		ShopName:          cfg.FeedShopName,
+		Company:           cfg.FeedCompany,
+		ShopURL:           cfg.FeedShopURL,
+		ProductURL:        cfg.FeedProductURL,
+		MediaBaseURL:      mediaBaseURL,
+		Currency:          cfg.FeedCurrency,
+		CategorySeparator: cfg.CatalogCategorySep,
+		Populate:          cfg.FeedsPopulate,
+	}
+}
+
+// Generator выгружает каталог Strapi в фиды маркетплейсов
+type Generator struct {
+	Source  ProductSource
+	Options Options
+}
+
+func NewGenerator(source ProductSource, opts Options) *Generator {
+	if opts.Currency == "" {
+		opts.Currency = "RUB"
+	}
+	if opts.CategorySeparator == "" {
+		opts.CategorySeparator = "/"
+	}
+	return &Generator{Source: source, Options: opts}
+}
+
+// Load читает из Strapi все опубликованные товары
+func (g *Generator) Load(ctx context.Context) ([]strapi.Product, error) {
+	return g.Source.ListAllProducts(ctx, strapi.PopulateValues(g.Options.Populate))
+}
+
+// Write выгружает товары в w в выбранном формате. Товары записываются
+// в поток по одному, документ целиком в памяти не собирается.
+func (g *Generator) Write(w io.Writer, format Format, products []strapi.Product, generated time.Time) error {
+	buf := bufio.NewWriter(w)
+	var err error
+	switch format {
+	case FormatYML:
+		err = g.writeYML(buf, products, generated)
+	case FormatGoogle:
+		err = g.writeGoogle(buf, products)
+	default:
+		err = fmt.Errorf("неизвестный формат фида %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+	return buf.Flush()
+}
+
+// WriteDir выгружает все форматы в dir. Каждый файл пишется во временный
+// и переименовывается, так что читатели не видят наполовину записанный фид.
+func (g *Generator) WriteDir(ctx context.Context, dir string) (int, error) {
+	products, err := g.Load(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+
+	generated := time.Now()
+	for _, format := range Formats {
+		if err := g.writeFile(filepath.Join(dir, format.FileName()), format, products, generated); err != nil {
+			return 0, err
+		}
+	}
+	return len(products), nil
+}
+
+func (g *Generator) writeFile(path string, format Format, products []strapi.Product, generated time.Time) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := g.Write(tmp, format, products, generated); err != nil {
+		tmp.Close()
+		return fmt.Errorf("фид %s: %w", format, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// productURL — ссылка на карточку товара на витрине
+func (g *Generator) productURL(product strapi.Product) string {
+	return strings.ReplaceAll(g.Options.ProductURL, "{id}", url.PathEscape(productID(product)))
+}
+
+// pictures — абсолютные ссылки на изображения товара
+func (g *Generator) pictures(product strapi.Product) []string {
+	var urls []string
+	for _, image := range product.Images {
+		if image.URL != "" {
+			urls = append(urls, strapi.MediaURL(g.Options.MediaBaseURL, image.URL))
+		}
+	}
+	return urls
+}
+
+// productID — идентификатор предложения: documentId, для старых записей — id
+func productID(product strapi.Product) string {
+	if product.DocumentID != "" {
+		return product.DocumentID
+	}
+	return fmt.Sprint(product.ID)
+}
+
+// available: товар в наличии, если остаток не ведётся или он положительный
+func available(product strapi.Product) bool {
+	stock, ok := product.Stock()
+	return !ok || stock > 0
+}
+
+// feedCategory — категория фида с устойчивым числовым ID
+type feedCategory struct {
+	ID       uint32
+	ParentID uint32
+	Name     string
+}
+
+// categoryID — ID категории по её пути; не меняется между выгрузками,
+// как того требуют маркетплейсы
+func categoryID(path []string) uint32 {
+	return crc32.ChecksumIEEE([]byte(strings.Join(path, "\x00")))
+}
+
+// collectCategories строит дерево категорий по всем товарам
+func (g *Generator) collectCategories(products []strapi.Product) []feedCategory {
+	seen := map[uint32]bool{}
+	var categories []feedCategory
+	for _, product := range products {
+		path := product.Category.Levels(g.Options.CategorySeparator)
+		for depth := range path {
+			id := categoryID(path[:depth+1])
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			category := feedCategory{ID: id, Name: path[depth]}
+			if depth > 0 {
+				category.ParentID = categoryID(path[:depth])
+			}
+			categories = append(categories, category)
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+	return categories
+}
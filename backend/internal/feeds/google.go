@@ -0,0 +1,121 @@
+// internal/feeds/google.go
+package feeds
+
+import (
+	"backend/internal/strapi"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// googleNamespace — пространство имён атрибутов g: в RSS-фиде Merchant Center
+const googleNamespace = "http://base.google.com/ns/1.0"
+
+type googleItem struct {
+	XMLName          xml.Name `xml:"item"`
+	ID               string   `xml:"g:id"`
+	Title            string   `xml:"g:title"`
+	Description      string   `xml:"g:description"`
+	Link             string   `xml:"g:link"`
+	ImageLink        string   `xml:"g:image_link,omitempty"`
+	AdditionalImages []string `xml:"g:additional_image_link"`
+	Price            string   `xml:"g:price"`
+	SalePrice        string   `xml:"g:sale_price,omitempty"`
+	Availability     string   `xml:"g:availability"`
+	Condition        string   `xml:"g:condition"`
+	Brand            string   `xml:"g:brand,omitempty"`
+	ProductType      string   `xml:"g:product_type,omitempty"`
+	ItemGroupID      string   `xml:"g:item_group_id,omitempty"`
+	Size             string   `xml:"g:size,omitempty"`
+	Color            string   `xml:"g:color,omitempty"`
+}
+
+// writeGoogle выгружает каталог в RSS 2.0 для Google Merchant Center
+func (g *Generator) writeGoogle(w io.Writer, products []strapi.Product) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	rss := xml.StartElement{
+		Name: xml.Name{Local: "rss"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "version"}, Value: "2.0"},
+			{Name: xml.Name{Local: "xmlns:g"}, Value: googleNamespace},
+		},
+	}
+	channel := xml.StartElement{Name: xml.Name{Local: "channel"}}
+	if err := encodeStart(enc, rss, channel); err != nil {
+		return err
+	}
+
+	for _, field := range []struct{ name, value string }{
+		{"title", g.Options.ShopName},
+		{"link", g.Options.ShopURL},
+		{"description", g.Options.Company},
+	} {
+		if err := enc.EncodeElement(field.value, xml.StartElement{Name: xml.Name{Local: field.name}}); err != nil {
+			return err
+		}
+	}
+
+	for _, product := range products {
+		for _, item := range g.googleItems(product) {
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := encodeEnd(enc, channel, rss); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+func (g *Generator) googleItems(product strapi.Product) []googleItem {
+	base := googleItem{
+		Title:       product.Name,
+		Description: product.Description,
+		Link:        g.productURL(product),
+		Condition:   "new",
+		Brand:       product.Brand.Name,
+		ProductType: strings.Join(product.Category.Levels(g.Options.CategorySeparator), " > "),
+	}
+	if pictures := g.pictures(product); len(pictures) > 0 {
+		base.ImageLink = pictures[0]
+		// Merchant Center принимает до 10 дополнительных изображений
+		base.AdditionalImages = pictures[1:min(len(pictures), 11)]
+	}
+	if colors := product.Colors(); len(colors) == 1 {
+		base.Color = colors[0]
+	}
+
+	id := productID(product)
+	var items []googleItem
+	for _, o := range productOffers(product) {
+		item := base
+		item.ID = id + o.suffix
+		item.Size = o.size
+		// В Merchant Center price — обычная цена, а цена со скидкой — sale_price
+		item.Price = g.googlePrice(o.price)
+		if o.oldPrice > 0 {
+			item.Price, item.SalePrice = g.googlePrice(o.oldPrice), g.googlePrice(o.price)
+		}
+		item.Availability = "out_of_stock"
+		if o.available {
+			item.Availability = "in_stock"
+		}
+		if o.grouped {
+			item.ItemGroupID = id
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func (g *Generator) googlePrice(price int) string {
+	return fmt.Sprintf("%d.00 %s", price, g.Options.Currency)
+}
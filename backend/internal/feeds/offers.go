@@ -0,0 +1,78 @@
+// internal/feeds/offers.go
+package feeds
+
+import (
+	"backend/internal/strapi"
+	"hash/crc32"
+	"strconv"
+	"strings"
+)
+
+// offer — одно предложение фида. Товар с несколькими размерами
+// выгружается группой предложений, по одному на размер, как того
+// требуют Маркет и Merchant Center для одежды и обуви.
+type offer struct {
+	suffix    string // добавка к ID товара для размера, пусто для одиночного; см. sizeSuffix
+	grouped   bool
+	size      string
+	available bool
+	// price — цена размера; oldPrice — цена до скидки, 0 если скидки нет
+	price    int
+	oldPrice int
+}
+
+func productOffers(product strapi.Product) []offer {
+	sizes := product.Sizes()
+	if len(sizes) <= 1 {
+		o := offer{available: available(product)}
+		if len(sizes) == 1 {
+			o.size = sizes[0]
+		}
+		o.price, o.oldPrice = offerPrices(product, o.size)
+		return []offer{o}
+	}
+
+	offers := make([]offer, 0, len(sizes))
+	for _, size := range sizes {
+		o := offer{
+			suffix:    sizeSuffix(size),
+			grouped:   true,
+			size:      size,
+			available: sizeAvailable(product, size),
+		}
+		o.price, o.oldPrice = offerPrices(product, size)
+		offers = append(offers, o)
+	}
+	return offers
+}
+
+// offerPrices — цена размера с учётом собственной цены варианта и цена
+// до скидки, если она выше; правила те же, что у корзины
+func offerPrices(product strapi.Product, size string) (price, oldPrice int) {
+	price = product.VariantPrice(size, "")
+	if list := product.ListPrice(size, ""); list > price {
+		oldPrice = list
+	}
+	return price, oldPrice
+}
+
+// sizeSuffix выводит добавку к ID предложения из самого размера: маркетплейсы
+// копят статистику по ID, и он не должен меняться, если варианты в Strapi
+// переставили или часть удалили. Номер варианта для этого не годится —
+// Strapi может пересоздать компоненты при сохранении товара.
+// Только латиница и цифры, чтобы ID укладывался в ограничения YML (до 20 символов).
+func sizeSuffix(size string) string {
+	normalized := strings.ToUpper(strings.Join(strings.Fields(size), " "))
+	return "s" + strconv.FormatUint(uint64(crc32.ChecksumIEEE([]byte(normalized))), 36)
+}
+
+// sizeAvailable проверяет остаток варианта с этим размером;
+// если остатки по размерам не ведутся — наличие товара целиком
+func sizeAvailable(product strapi.Product, size string) bool {
+	for _, variant := range product.Variants {
+		if strings.EqualFold(strings.TrimSpace(variant.Size), size) && variant.Stock != nil {
+			return *variant.Stock > 0
+		}
+	}
+	return available(product)
+}
@@ -0,0 +1,130 @@
+// internal/feeds/offers_test.go
+package feeds
+
+import (
+	"backend/internal/strapi"
+	"encoding/json"
+	"math"
+	"strconv"
+	"testing"
+)
+
+// strapiJacket — товар в том виде, в каком его отдаёт Strapi v5
+// с populate=image,variants
+const strapiJacket = `{
+	"id": 118,
+	"documentId": "ykd3w9ohr7vbm1e4k2m0qxzl",
+	"name": "Куртка зимняя",
+	"description": "Пуховая куртка",
+	"price": 8990,
+	"oldPrice": 11990,
+	"category": "Одежда/Куртки",
+	"brand": "Nord",
+	"size": null,
+	"createdAt": "2026-01-10T09:12:44.315Z",
+	"updatedAt": "2026-02-01T17:03:09.120Z",
+	"publishedAt": "2026-02-01T17:03:09.140Z",
+	"image": [{"id": 31, "name": "jacket.jpg", "url": "/uploads/jacket_4f1c2a.jpg", "formats": {}}],
+	"variants": [
+		{"id": 301, "size": "S", "color": "Чёрный", "stock": 0},
+		{"id": 302, "size": "M", "color": "Чёрный", "stock": 4},
+		{"id": 303, "size": "XXL", "color": "Чёрный", "stock": 2, "price": 9990}
+	]
+}`
+
+func decodeProduct(t *testing.T, raw string) strapi.Product {
+	t.Helper()
+	var product strapi.Product
+	if err := json.Unmarshal([]byte(raw), &product); err != nil {
+		t.Fatal(err)
+	}
+	return product
+}
+
+func offerSuffixes(product strapi.Product) map[string]string {
+	suffixes := map[string]string{}
+	for _, o := range productOffers(product) {
+		suffixes[o.size] = o.suffix
+	}
+	return suffixes
+}
+
+func TestOfferIDsSurviveVariantChanges(t *testing.T) {
+	product := decodeProduct(t, strapiJacket)
+	before := offerSuffixes(product)
+	if len(before) != 3 {
+		t.Fatalf("offers = %v, want one per size", before)
+	}
+
+	// Варианты переставлены, один удалён, остальные пересозданы с новыми ID
+	product.Variants = []strapi.Variant{{ID: 411, Size: "XXL"}, {ID: 412, Size: " m"}}
+	after := offerSuffixes(product)
+
+	if after["XXL"] != before["XXL"] || after["m"] != before["M"] {
+		t.Fatalf("suffixes changed: before %v, after %v", before, after)
+	}
+	if before["S"] == before["M"] || before["M"] == before["XXL"] {
+		t.Fatalf("suffixes collide: %v", before)
+	}
+}
+
+func TestOfferIDsFitMarketplaceLimits(t *testing.T) {
+	product := decodeProduct(t, strapiJacket)
+	g := NewGenerator(nil, Options{})
+
+	// id в Strapi — целое со знаком, YML ограничивает ID 20 символами
+	product.ID = math.MaxInt32
+	for _, offer := range g.ymlOffers(product) {
+		if len(offer.ID) > 20 || offer.GroupID != strconv.Itoa(product.ID) {
+			t.Errorf("yml offer ID %q (group %q) breaks the 20-char limit", offer.ID, offer.GroupID)
+		}
+	}
+	// Merchant Center ограничивает ID 50 символами, ID строится из documentId
+	for _, item := range g.googleItems(product) {
+		if len(item.ID) > 50 || item.ItemGroupID != product.DocumentID {
+			t.Errorf("google item ID %q (group %q) breaks the 50-char limit", item.ID, item.ItemGroupID)
+		}
+	}
+}
+
+func TestOfferPricesFollowVariants(t *testing.T) {
+	product := decodeProduct(t, strapiJacket)
+	g := NewGenerator(nil, Options{})
+
+	type prices struct{ price, oldPrice int }
+	want := map[string]prices{
+		"S": {8990, 11990},
+		"M": {8990, 11990},
+		// Своя цена варианта: скидка товара к ней не относится
+		"XXL": {9990, 0},
+	}
+
+	for _, offer := range g.ymlOffers(product) {
+		size := offer.Params[len(offer.Params)-1].Value
+		if got := (prices{offer.Price, offer.OldPrice}); got != want[size] {
+			t.Errorf("yml %s: price/oldprice = %v, want %v", size, got, want[size])
+		}
+	}
+
+	googleWant := map[string][2]string{
+		"S":   {"11990.00 RUB", "8990.00 RUB"},
+		"M":   {"11990.00 RUB", "8990.00 RUB"},
+		"XXL": {"9990.00 RUB", ""},
+	}
+	for _, item := range g.googleItems(product) {
+		if got := [2]string{item.Price, item.SalePrice}; got != googleWant[item.Size] {
+			t.Errorf("google %s: price/sale_price = %v, want %v", item.Size, got, googleWant[item.Size])
+		}
+	}
+
+	// Без скидки oldprice и sale_price не выводятся
+	product.OldPrice = nil
+	product.Variants = nil
+	offers := g.ymlOffers(product)
+	if len(offers) != 1 || offers[0].Price != 8990 || offers[0].OldPrice != 0 {
+		t.Errorf("single offer = %+v", offers)
+	}
+	if items := g.googleItems(product); items[0].Price != "8990.00 RUB" || items[0].SalePrice != "" {
+		t.Errorf("single item = %+v", items[0])
+	}
+}
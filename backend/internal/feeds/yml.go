@@ -0,0 +1,166 @@
+// internal/feeds/yml.go
+package feeds
+
+import (
+	"backend/internal/strapi"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ymlDateLayout — формат атрибута date в yml_catalog
+const ymlDateLayout = "2006-01-02T15:04-07:00"
+
+type ymlCategory struct {
+	XMLName  xml.Name `xml:"category"`
+	ID       uint32   `xml:"id,attr"`
+	ParentID uint32   `xml:"parentId,attr,omitempty"`
+	Name     string   `xml:",chardata"`
+}
+
+type ymlOffer struct {
+	XMLName     xml.Name   `xml:"offer"`
+	ID          string     `xml:"id,attr"`
+	GroupID     string     `xml:"group_id,attr,omitempty"`
+	Available   bool       `xml:"available,attr"`
+	URL         string     `xml:"url,omitempty"`
+	Price       int        `xml:"price"`
+	OldPrice    int        `xml:"oldprice,omitempty"`
+	CurrencyID  string     `xml:"currencyId"`
+	CategoryID  uint32     `xml:"categoryId,omitempty"`
+	Pictures    []string   `xml:"picture"`
+	Name        string     `xml:"name"`
+	Vendor      string     `xml:"vendor,omitempty"`
+	Description string     `xml:"description,omitempty"`
+	Params      []ymlParam `xml:"param"`
+}
+
+type ymlParam struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// writeYML выгружает каталог в формате Яндекс Маркета. ID предложения
+// в YML — только латиница и цифры до 20 символов, поэтому берётся числовой id.
+func (g *Generator) writeYML(w io.Writer, products []strapi.Product, generated time.Time) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	catalog := xml.StartElement{
+		Name: xml.Name{Local: "yml_catalog"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "date"}, Value: generated.Format(ymlDateLayout)}},
+	}
+	shop := xml.StartElement{Name: xml.Name{Local: "shop"}}
+	if err := encodeStart(enc, catalog, shop); err != nil {
+		return err
+	}
+
+	for _, field := range []struct{ name, value string }{
+		{"name", g.Options.ShopName},
+		{"company", g.Options.Company},
+		{"url", g.Options.ShopURL},
+	} {
+		if err := enc.EncodeElement(field.value, xml.StartElement{Name: xml.Name{Local: field.name}}); err != nil {
+			return err
+		}
+	}
+
+	currencies := struct {
+		XMLName  xml.Name `xml:"currencies"`
+		Currency struct {
+			ID   string `xml:"id,attr"`
+			Rate string `xml:"rate,attr"`
+		} `xml:"currency"`
+	}{}
+	currencies.Currency.ID = g.Options.Currency
+	currencies.Currency.Rate = "1"
+	if err := enc.Encode(currencies); err != nil {
+		return err
+	}
+
+	categories := xml.StartElement{Name: xml.Name{Local: "categories"}}
+	if err := encodeStart(enc, categories); err != nil {
+		return err
+	}
+	for _, category := range g.collectCategories(products) {
+		if err := enc.Encode(ymlCategory{ID: category.ID, ParentID: category.ParentID, Name: category.Name}); err != nil {
+			return err
+		}
+	}
+	if err := encodeEnd(enc, categories); err != nil {
+		return err
+	}
+
+	offers := xml.StartElement{Name: xml.Name{Local: "offers"}}
+	if err := encodeStart(enc, offers); err != nil {
+		return err
+	}
+	for _, product := range products {
+		for _, o := range g.ymlOffers(product) {
+			if err := enc.Encode(o); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := encodeEnd(enc, offers, shop, catalog); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+func (g *Generator) ymlOffers(product strapi.Product) []ymlOffer {
+	base := ymlOffer{
+		URL:         g.productURL(product),
+		CurrencyID:  g.Options.Currency,
+		Pictures:    g.pictures(product),
+		Name:        product.Name,
+		Vendor:      product.Brand.Name,
+		Description: product.Description,
+	}
+	if path := product.Category.Levels(g.Options.CategorySeparator); len(path) > 0 {
+		base.CategoryID = categoryID(path)
+	}
+	if colors := product.Colors(); len(colors) == 1 {
+		base.Params = append(base.Params, ymlParam{Name: "Цвет", Value: colors[0]})
+	}
+
+	id := strconv.Itoa(product.ID)
+	var offers []ymlOffer
+	for _, o := range productOffers(product) {
+		offer := base
+		offer.ID = id + o.suffix
+		offer.Available = o.available
+		offer.Price, offer.OldPrice = o.price, o.oldPrice
+		if o.grouped {
+			offer.GroupID = id
+		}
+		if o.size != "" {
+			offer.Params = append(append([]ymlParam{}, base.Params...), ymlParam{Name: "Размер", Value: o.size})
+		}
+		offers = append(offers, offer)
+	}
+	return offers
+}
+
+func encodeStart(enc *xml.Encoder, elements ...xml.StartElement) error {
+	for _, element := range elements {
+		if err := enc.EncodeToken(element); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeEnd(enc *xml.Encoder, elements ...xml.StartElement) error {
+	for _, element := range elements {
+		if err := enc.EncodeToken(element.End()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// internal/gateway/admin_proxy.go
+package gateway
+
+import (
+	"backend/internal/auth"
+	"backend/pkg/logger"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Заголовки, которые не должны уходить в Budibase: наш JWT и чужие X-Forwarded-*.
+// Cookie шлюза вырезаются из заголовка Cookie отдельно, cookie Budibase остаются.
+var adminProxyStripRequestHeaders = []string{
+	"Authorization",
+	"X-Forwarded-For",
+	"X-Forwarded-Host",
+	"X-Forwarded-Proto",
+	"X-Real-Ip",
+	"Forwarded",
+}
+
+// Заголовки ответа Budibase, раскрывающие внутреннюю инфраструктуру
+var adminProxyStripResponseHeaders = []string{
+	"Server",
+	"X-Powered-By",
+}
+
+// AdminProxy проксирует запросы под AdminPrefix в Budibase.
+// WebSocket-соединения httputil.ReverseProxy поднимает сам по заголовку Upgrade.
+func (g *Gateway) AdminProxy() gin.HandlerFunc {
+	target := g.BudibaseURL
+	prefix := g.AdminPrefix
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			for _, header := range adminProxyStripRequestHeaders {
+				r.Out.Header.Del(header)
+			}
+			stripCookies(r.Out, g.GatewayCookies)
+
+			// /admin/builder/app -> /builder/app
+			path := strings.TrimPrefix(r.In.URL.Path, prefix)
+			if path == "" {
+				path = "/"
+			}
+			r.Out.URL.Path = path
+			r.Out.URL.RawPath = ""
+
+			r.SetURL(target)
+			r.SetXForwarded()
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			for _, header := range adminProxyStripResponseHeaders {
+				resp.Header.Del(header)
+			}
+
+			// Редиректы Budibase на свои абсолютные пути возвращаем под префикс
+			if location := resp.Header.Get("Location"); strings.HasPrefix(location, "/") && !strings.HasPrefix(location, "//") {
+				resp.Header.Set("Location", prefix+location)
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logger.ErrorLogger.Println("Ошибка проксирования в Budibase:", err)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(`{"error":"Админка недоступна"}`))
+		},
+	}
+
+	return func(c *gin.Context) {
+		proxy.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// setupAdminRoutes регистрирует сессию админки и прокси в Budibase.
+// Браузер не может добавить Bearer-заголовок к страницам, ресурсам и
+// WebSocket админки, поэтому прокси принимает и cookie сессии: её выдаёт
+// POST /api/auth/admin-session по действующему access-токену администратора.
+func (g *Gateway) setupAdminRoutes(router gin.IRouter) {
+	session := router.Group("/api/auth/admin-session", g.RateLimit(RatePolicyDefault))
+	{
+		session.POST("", g.Middleware(), g.RequireRole(g.AdminRole), g.CreateAdminSession)
+		session.DELETE("", g.DeleteAdminSession)
+	}
+
+	router.Any(g.AdminPrefix+"/*path", g.AdminMiddleware(), g.RequireRole(g.AdminRole), g.AdminProxy())
+}
+
+// AdminMiddleware проверяет токен из заголовка Authorization, а без него —
+// из cookie сессии админки
+func (g *Gateway) AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			if token, err := c.Cookie(g.AdminSessionCookie); err == nil && token != "" {
+				authHeader = "Bearer " + token
+			}
+		}
+
+		principal, claims, errMsg := g.authenticate(c.Request.Context(), authHeader)
+		if errMsg != "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": errMsg})
+			return
+		}
+
+		setPrincipal(c, principal, claims)
+		c.Next()
+	}
+}
+
+// CreateAdminSession выставляет HttpOnly cookie с access-токеном администратора.
+// Cookie живёт не дольше токена и отправляется только на пути админки;
+// SameSite=Strict не даёт чужим сайтам слать запросы в админку от имени сотрудника.
+func (g *Gateway) CreateAdminSession(c *gin.Context) {
+	principal, _ := auth.PrincipalFrom(c)
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+	maxAge := 0
+	if principal != nil && !principal.ExpiresAt.IsZero() {
+		maxAge = int(time.Until(principal.ExpiresAt).Seconds())
+	}
+	if maxAge <= 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Токен без срока действия не подходит для сессии админки"})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(g.AdminSessionCookie, token, maxAge, g.AdminPrefix, "", g.AdminSessionCookieSecure, true)
+	c.JSON(http.StatusOK, gin.H{"message": "Сессия админки открыта", "expiresAt": principal.ExpiresAt})
+}
+
+// DeleteAdminSession удаляет cookie сессии админки
+func (g *Gateway) DeleteAdminSession(c *gin.Context) {
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(g.AdminSessionCookie, "", -1, g.AdminPrefix, "", g.AdminSessionCookieSecure, true)
+	c.JSON(http.StatusOK, gin.H{"message": "Сессия админки закрыта"})
+}
+
+// stripCookies убирает из запроса cookie с указанными именами. Заголовок
+// разбирается вручную: net/http отбрасывает имена вроде "budibase:auth",
+// а такие cookie Budibase должны дойти до него без изменений.
+func stripCookies(r *http.Request, names []string) {
+	var kept []string
+	for _, header := range r.Header.Values("Cookie") {
+		for _, pair := range strings.Split(header, ";") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			name, _, _ := strings.Cut(pair, "=")
+			strip := false
+			for _, gatewayCookie := range names {
+				if gatewayCookie != "" && name == gatewayCookie {
+					strip = true
+					break
+				}
+			}
+			if !strip {
+				kept = append(kept, pair)
+			}
+		}
+	}
+
+	r.Header.Del("Cookie")
+	if len(kept) > 0 {
+		r.Header.Set("Cookie", strings.Join(kept, "; "))
+	}
+}
@@ -0,0 +1,202 @@
+// internal/gateway/admin_proxy_test.go
+package gateway
+
+import (
+	"backend/internal/auth"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ulule/limiter/v3"
+	"github.com/ulule/limiter/v3/drivers/store/memory"
+)
+
+// budibaseEcho отвечает тем, что дошло до Budibase
+type budibaseEcho struct {
+	Path          string `json:"path"`
+	Authorization string `json:"authorization"`
+	Cookie        string `json:"cookie"`
+}
+
+// newAdminTestRouter поднимает шлюз настоящим сервером: ReverseProxy
+// требует CloseNotifier, которого нет у httptest.ResponseRecorder
+func newAdminTestRouter(t *testing.T) (*adminClient, *auth.Issuer) {
+	t.Helper()
+
+	budibase := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/builder", http.StatusFound)
+			return
+		}
+		w.Header().Set("Server", "budibase/1.0")
+		_ = json.NewEncoder(w).Encode(budibaseEcho{
+			Path:          r.URL.Path,
+			Authorization: r.Header.Get("Authorization"),
+			Cookie:        r.Header.Get("Cookie"),
+		})
+	}))
+	t.Cleanup(budibase.Close)
+	target, _ := url.Parse(budibase.URL)
+
+	g, issuer := newTestGateway(t)
+	g.BudibaseURL = target
+	g.AdminPrefix = "/admin"
+	g.AdminRole = "admin"
+	g.AdminSessionCookie = "admin_session"
+	g.AdminSessionCookieSecure = true
+	g.GatewayCookies = []string{"admin_session", "guest_cart"}
+	g.RateLimiters = map[string]*limiter.Limiter{
+		RatePolicyDefault: limiter.New(memory.NewStore(), limiter.Rate{Period: time.Minute, Limit: 1000}),
+	}
+
+	router := gin.New()
+	g.setupAdminRoutes(router)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return &adminClient{t: t, base: server.URL}, issuer
+}
+
+func issue(t *testing.T, issuer *auth.Issuer, userID int, role string) string {
+	t.Helper()
+	token, _, err := issuer.IssueAccessToken(auth.Subject{UserID: userID, Role: role})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+type adminClient struct {
+	t    *testing.T
+	base string
+}
+
+// response — ответ шлюза с прочитанным телом
+type response struct {
+	*http.Response
+	Body []byte
+}
+
+func (a *adminClient) do(method, target string, prepare func(*http.Request)) response {
+	a.t.Helper()
+
+	req, err := http.NewRequest(method, a.base+target, nil)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	if prepare != nil {
+		prepare(req)
+	}
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Do(req)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return response{Response: resp, Body: body}
+}
+
+func TestAdminProxyRequiresAdmin(t *testing.T) {
+	router, issuer := newAdminTestRouter(t)
+	customer := issue(t, issuer, 7, "customer")
+
+	if rec := router.do(http.MethodGet, "/admin/builder", nil); rec.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("anonymous: status = %d, want 401", rec.StatusCode)
+	}
+	rec := router.do(http.MethodGet, "/admin/builder", func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+customer)
+	})
+	if rec.StatusCode != http.StatusForbidden {
+		t.Fatalf("customer: status = %d, want 403", rec.StatusCode)
+	}
+	rec = router.do(http.MethodGet, "/admin/builder", func(r *http.Request) {
+		r.AddCookie(&http.Cookie{Name: "admin_session", Value: customer})
+	})
+	if rec.StatusCode != http.StatusForbidden {
+		t.Fatalf("customer cookie: status = %d, want 403", rec.StatusCode)
+	}
+}
+
+func TestAdminProxyStripsGatewayCredentials(t *testing.T) {
+	router, issuer := newAdminTestRouter(t)
+	admin := issue(t, issuer, 1, "admin")
+
+	cases := map[string]func(*http.Request){
+		"bearer": func(r *http.Request) {
+			r.Header.Set("Authorization", "Bearer "+admin)
+			r.AddCookie(&http.Cookie{Name: "budibase:auth", Value: "bb"})
+		},
+		"cookie": func(r *http.Request) {
+			r.AddCookie(&http.Cookie{Name: "admin_session", Value: admin})
+			r.AddCookie(&http.Cookie{Name: "guest_cart", Value: "g.sig"})
+			r.AddCookie(&http.Cookie{Name: "budibase:auth", Value: "bb"})
+		},
+	}
+	for name, prepare := range cases {
+		rec := router.do(http.MethodGet, "/admin/builder/app", prepare)
+		if rec.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200: %s", name, rec.StatusCode, rec.Body)
+		}
+		var echo budibaseEcho
+		if err := json.Unmarshal(rec.Body, &echo); err != nil {
+			t.Fatal(err)
+		}
+		if echo.Path != "/builder/app" {
+			t.Errorf("%s: path = %q, want prefix stripped", name, echo.Path)
+		}
+		if echo.Authorization != "" || echo.Cookie != "budibase:auth=bb" {
+			t.Errorf("%s: Budibase got authorization %q, cookie %q", name, echo.Authorization, echo.Cookie)
+		}
+		if rec.Header.Get("Server") != "" {
+			t.Errorf("%s: Server header leaked", name)
+		}
+	}
+
+	rec := router.do(http.MethodGet, "/admin/redirect", cases["cookie"])
+	if location := rec.Header.Get("Location"); location != "/admin/builder" {
+		t.Fatalf("Location = %q, want it under the prefix", location)
+	}
+}
+
+func TestAdminSessionCookie(t *testing.T) {
+	router, issuer := newAdminTestRouter(t)
+
+	rec := router.do(http.MethodPost, "/api/auth/admin-session", func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+issue(t, issuer, 7, "customer"))
+	})
+	if rec.StatusCode != http.StatusForbidden || rec.Header.Get("Set-Cookie") != "" {
+		t.Fatalf("customer: status = %d, cookie %q", rec.StatusCode, rec.Header.Get("Set-Cookie"))
+	}
+
+	admin := issue(t, issuer, 1, "admin")
+	rec = router.do(http.MethodPost, "/api/auth/admin-session", func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+admin)
+	})
+	if rec.StatusCode != http.StatusOK {
+		t.Fatalf("admin: status = %d: %s", rec.StatusCode, rec.Body)
+	}
+	cookies := rec.Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("cookies = %v", cookies)
+	}
+	cookie := cookies[0]
+	if cookie.Value != admin || !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteStrictMode ||
+		cookie.Path != "/admin" || cookie.MaxAge <= 0 || cookie.MaxAge > 60 {
+		t.Fatalf("cookie = %+v", cookie)
+	}
+
+	rec = router.do(http.MethodDelete, "/api/auth/admin-session", nil)
+	if cookies := rec.Cookies(); len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Fatalf("logout cookies = %v, want the cookie expired", cookies)
+	}
+}
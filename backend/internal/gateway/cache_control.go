@@ -0,0 +1,19 @@
+// internal/gateway/cache_control.go
+package gateway
+
+import (
+	"backend/internal/gateway/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheControl задаёт Cache-Control для успешных ответов маршрута.
+// Заголовок ставит сам хендлер, чтобы ошибки не попадали в кеш браузера и CDN.
+func (g *Gateway) CacheControl(value string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if value != "" {
+			c.Set(handlers.CacheControlKey, value)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,132 @@
+// internal/gateway/cors.go
+package gateway
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Методы и заголовки, которые браузер может использовать в кросс-доменных запросах
+var (
+	corsAllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	corsAllowHeaders = []string{"Authorization", "Content-Type", "X-Request-ID", "If-None-Match", "If-Modified-Since", "X-Guest-Cart"}
+)
+
+// loadCORSOrigins собирает список разрешённых источников из перечня и файла
+// (по одному источнику на строку, строки с # пропускаются)
+func loadCORSOrigins(origins []string, file string) (map[string]bool, error) {
+	allowed := make(map[string]bool)
+	for _, origin := range origins {
+		if origin = normalizeOrigin(origin); origin != "" {
+			allowed[origin] = true
+		}
+	}
+
+	if file == "" {
+		return allowed, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("чтение списка CORS: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		allowed[normalizeOrigin(line)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("чтение списка CORS: %w", err)
+	}
+	return allowed, nil
+}
+
+// normalizeOrigin приводит источник к виду, в котором его присылает браузер
+func normalizeOrigin(origin string) string {
+	return strings.ToLower(strings.TrimRight(strings.TrimSpace(origin), "/"))
+}
+
+// CORS разрешает кросс-доменные запросы с учётными данными от источников
+// из списка и отвечает на preflight-запросы без обращения к маршрутам
+func (g *Gateway) CORS() gin.HandlerFunc {
+	maxAge := strconv.Itoa(int(g.CORSMaxAge.Seconds()))
+	allowMethods := strings.Join(corsAllowMethods, ", ")
+	allowHeaders := strings.Join(corsAllowHeaders, ", ")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		if !g.CORSOrigins[normalizeOrigin(origin)] {
+			// Чужому источнику не выдаём CORS-заголовки, браузер сам заблокирует ответ
+			if isPreflight(c) {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		c.Header("Access-Control-Allow-Credentials", "true")
+
+		if isPreflight(c) {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			c.Header("Access-Control-Allow-Methods", allowMethods)
+			c.Header("Access-Control-Allow-Headers", allowHeaders)
+			c.Header("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ExposeHeaders открывает браузеру заголовки ответа конкретных маршрутов
+func (g *Gateway) ExposeHeaders(headers ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		exposeHeaders(c, headers...)
+		c.Next()
+	}
+}
+
+// exposeHeaders дописывает заголовки в Access-Control-Expose-Headers без повторов
+func exposeHeaders(c *gin.Context, headers ...string) {
+	current := c.Writer.Header().Get("Access-Control-Expose-Headers")
+	seen := make(map[string]bool)
+	var list []string
+	for _, h := range strings.Split(current, ",") {
+		if h = strings.TrimSpace(h); h != "" && !seen[strings.ToLower(h)] {
+			seen[strings.ToLower(h)] = true
+			list = append(list, h)
+		}
+	}
+	for _, h := range headers {
+		if !seen[strings.ToLower(h)] {
+			seen[strings.ToLower(h)] = true
+			list = append(list, h)
+		}
+	}
+	c.Header("Access-Control-Expose-Headers", strings.Join(list, ", "))
+}
+
+func isPreflight(c *gin.Context) bool {
+	return c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+}
@@ -0,0 +1,155 @@
+// internal/gateway/cors_test.go
+package gateway
+
+import (
+	"backend/internal/gateway/handlers"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newCORSTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "origins.txt")
+	if err := os.WriteFile(file, []byte("# партнёры\nhttps://Partner.Example.com/\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	router, _ := newTestRouter(t, map[string]string{
+		"FRONTEND_URL":      "https://shop.example.com, https://m.example.com",
+		"CORS_ORIGINS_FILE": file,
+		"CORS_MAX_AGE":      "5m",
+	})
+	return router
+}
+
+func preflight(origin, method string) map[string]string {
+	return map[string]string{
+		"Origin":                         origin,
+		"Access-Control-Request-Method":  method,
+		"Access-Control-Request-Headers": "authorization, content-type",
+	}
+}
+
+func TestCORSAllowList(t *testing.T) {
+	router := newCORSTestRouter(t)
+
+	for origin, allowed := range map[string]bool{
+		"https://shop.example.com":    true,
+		"https://m.example.com":       true,
+		"https://partner.example.com": true,
+		"https://evil.example.com":    false,
+		"http://shop.example.com":     false,
+		"null":                        false,
+	} {
+		rec := call(router, http.MethodGet, "/api/catalog/products", "", map[string]string{"Origin": origin})
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", origin, rec.Code)
+		}
+
+		got := rec.Header().Get("Access-Control-Allow-Origin")
+		if allowed && got != origin {
+			t.Errorf("%s: Allow-Origin = %q", origin, got)
+		}
+		if !allowed && got != "" {
+			t.Errorf("%s: foreign origin got Allow-Origin %q", origin, got)
+		}
+		if creds := rec.Header().Get("Access-Control-Allow-Credentials"); (creds == "true") != allowed {
+			t.Errorf("%s: Allow-Credentials = %q", origin, creds)
+		}
+		// Ответ зависит от Origin и для чужих источников — иначе кеш отдаст его не тому
+		if !hasVary(rec.Header(), "Origin") {
+			t.Errorf("%s: Vary = %v, want Origin", origin, rec.Header().Values("Vary"))
+		}
+	}
+
+	// Запросы не из браузера CORS не касаются
+	rec := call(router, http.MethodGet, "/api/catalog/products", "", nil)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" || hasVary(rec.Header(), "Origin") {
+		t.Errorf("request without Origin got CORS headers: %v", rec.Header())
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	router := newCORSTestRouter(t)
+
+	rec := call(router, http.MethodOptions, "/api/cart/items", "", preflight("https://shop.example.com", http.MethodPost))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("allowed preflight: status = %d, want 204", rec.Code)
+	}
+	for header, want := range map[string]string{
+		"Access-Control-Allow-Origin":      "https://shop.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Max-Age":           "300",
+	} {
+		if got := rec.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+	if methods := rec.Header().Get("Access-Control-Allow-Methods"); !strings.Contains(methods, "POST") {
+		t.Errorf("Allow-Methods = %q", methods)
+	}
+	if headers := rec.Header().Get("Access-Control-Allow-Headers"); !strings.Contains(headers, "Authorization") || !strings.Contains(headers, "X-Guest-Cart") {
+		t.Errorf("Allow-Headers = %q", headers)
+	}
+	for _, vary := range []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"} {
+		if !hasVary(rec.Header(), vary) {
+			t.Errorf("Vary = %v, want %s", rec.Header().Values("Vary"), vary)
+		}
+	}
+	// Preflight не расходует лимиты и не доходит до маршрута
+	if rec.Header().Get("RateLimit-Limit") != "" {
+		t.Error("preflight passed through the rate limiter")
+	}
+
+	rec = call(router, http.MethodOptions, "/api/cart/items", "", preflight("https://evil.example.com", http.MethodPost))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("foreign preflight: status = %d, want 403", rec.Code)
+	}
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" || rec.Header().Get("Access-Control-Allow-Methods") != "" {
+		t.Errorf("foreign preflight got CORS headers: %v", rec.Header())
+	}
+}
+
+func TestCORSExposeHeaders(t *testing.T) {
+	router := newCORSTestRouter(t)
+	origin := map[string]string{"Origin": "https://shop.example.com"}
+
+	cases := []struct {
+		target string
+		want   []string
+	}{
+		{"/api/catalog/products", []string{"X-Request-ID", "RateLimit-Remaining", "ETag", "Last-Modified"}},
+		{"/api/cart/", []string{"X-Request-ID", "RateLimit-Remaining", handlers.GuestCartHeader}},
+	}
+	for _, tc := range cases {
+		rec := call(router, http.MethodGet, tc.target, "", origin)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d", tc.target, rec.Code)
+		}
+		exposed := strings.Split(rec.Header().Get("Access-Control-Expose-Headers"), ", ")
+		seen := map[string]int{}
+		for _, h := range exposed {
+			seen[strings.ToLower(h)]++
+		}
+		for _, h := range tc.want {
+			if seen[strings.ToLower(h)] != 1 {
+				t.Errorf("%s: Expose-Headers = %q, want %s exactly once", tc.target, exposed, h)
+			}
+		}
+	}
+}
+
+func hasVary(header http.Header, value string) bool {
+	for _, vary := range header.Values("Vary") {
+		for _, v := range strings.Split(vary, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
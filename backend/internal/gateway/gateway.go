@@ -2,29 +2,66 @@
 package gateway
 
 import (
+	"backend/internal/auth"
 	"backend/internal/config"
+	"backend/internal/feeds"
 	"backend/internal/gateway/handlers"
+	"backend/internal/guestcart"
+	"backend/internal/media"
+	"backend/internal/strapi"
 	"backend/pkg/logger"
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/redis/go-redis/v9"
 	"github.com/ulule/limiter/v3"
-	ginmiddleware "github.com/ulule/limiter/v3/drivers/middleware/gin"
-	memory "github.com/ulule/limiter/v3/drivers/store/memory"
 )
 
 type Gateway struct {
 	StrapiURL   *url.URL
 	BudibaseURL *url.URL
-	JWTSecret   []byte
+	Verifier    *auth.Verifier
+	Revocations auth.RevocationStore
+	Redis       *redis.Client
+
+	RateLimiters map[string]*limiter.Limiter
+	// TrustedProxies — прокси, которым ClientIP верит в X-Forwarded-For
+	TrustedProxies []string
+
+	CORSOrigins map[string]bool
+	CORSMaxAge  time.Duration
+
+	CacheControlProducts string
+	CacheControlProduct  string
+	CacheControlTaxonomy string
+	CacheControlFeeds    string
+	CacheControlMedia    string
+
+	AdminPrefix string
+	AdminRole   string
+	// AdminSessionCookie хранит access-токен для страниц и WebSocket админки
+	AdminSessionCookie       string
+	AdminSessionCookieSecure bool
+	// GatewayCookies — cookie шлюза, которые не передаются в Budibase
+	GatewayCookies []string
+
+	RoleClaim       string
+	RolePermissions auth.RolePermissions
 
 	AuthHandler    *handlers.AuthHandler
 	CatalogHandler *handlers.CatalogHandler
 	CartHandler    *handlers.CartHandler
 	OrderHandler   *handlers.OrderHandler
+	SearchHandler  *handlers.SearchHandler
+	FeedHandler    *handlers.FeedHandler
+	MediaHandler   *handlers.MediaHandler
+	WebhookHandler *handlers.WebhookHandler
 }
 
 // NewGateway инициализирует новый API Gateway
@@ -34,68 +71,303 @@ func NewGateway(cfg *config.Config) (*Gateway, error) {
 		return nil, err
 	}
 
-	budibaseURL, err := url.Parse("http://budibase:80") // Адрес Budibase внутри Docker сети
+	budibaseURL, err := url.Parse(cfg.BudibaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if budibaseURL.Scheme == "" || budibaseURL.Host == "" {
+		return nil, fmt.Errorf("некорректный BUDIBASE_URL: %q", cfg.BudibaseURL)
+	}
+
+	rolePermissions, err := auth.ParseRolePermissions(cfg.RolePermissions)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, err := auth.NewVerifier(auth.VerifierConfig{
+		HMACSecret:     []byte(cfg.JWTSecret),
+		PublicKeyFiles: cfg.JWTPublicKeyFiles,
+		JWKSURL:        cfg.JWKSURL,
+		JWKSCacheTTL:   cfg.JWKSCacheTTL,
+		Algorithms:     cfg.JWTAlgorithms,
+		Issuer:         cfg.JWTIssuer,
+		Audience:       cfg.JWTAudience,
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	corsOrigins, err := loadCORSOrigins(strings.Split(cfg.FrontendURL, ","), cfg.CORSOriginsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Redis опционален: без него refresh-токены, отзыв токенов и гостевые
+	// корзины живут в памяти одного экземпляра
+	var redisClient *redis.Client
+	var revocations auth.RevocationStore = auth.NewMemoryRevocationStore()
+	var sessions auth.RefreshStore = auth.NewMemoryRefreshStore()
+	var guestCarts guestcart.Store = guestcart.NewMemoryStore()
+	if cfg.RedisURL != "" {
+		opts, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный REDIS_URL: %w", err)
+		}
+		redisClient = redis.NewClient(opts)
+		revocations = auth.NewRedisRevocationStore(redisClient, cfg.RedisPrefix+"revoked:")
+		sessions = auth.NewRedisRefreshStore(redisClient, cfg.RedisPrefix+"refresh:")
+		guestCarts = guestcart.NewRedisStore(redisClient, cfg.RedisPrefix+"guest-cart:")
+	}
+
+	// Без отдельного секрета гостевые токены подписываются секретом JWT
+	guestSecret := cfg.GuestCartSecret
+	if guestSecret == "" {
+		guestSecret = cfg.JWTSecret
+	}
+	// Корзины в Redis переживают перезапуск и видны всем репликам: токены
+	// к ним должны подписываться одним постоянным секретом
+	if cfg.RedisURL != "" && (guestSecret == "" || guestSecret == config.InsecureJWTSecret) {
+		return nil, fmt.Errorf("при REDIS_URL нужен GUEST_CART_SECRET или JWT_SECRET, одинаковый на всех экземплярах")
+	}
+	guestSigner, err := guestcart.NewSigner([]byte(guestSecret))
+	if err != nil {
+		return nil, err
+	}
+	guestSameSite, err := parseSameSite(cfg.GuestCartCookieSameSite)
+	if err != nil {
+		return nil, err
+	}
+	if guestSameSite == http.SameSiteNoneMode && !cfg.GuestCartCookieSecure {
+		return nil, fmt.Errorf("GUEST_CART_COOKIE_SAMESITE=none требует GUEST_CART_COOKIE_SECURE=true")
+	}
+
+	limiterStore, err := newLimiterStore(redisClient, cfg.RedisPrefix)
+	if err != nil {
+		return nil, err
+	}
+	rateLimiters, err := newRateLimiters(cfg.RateLimits, limiterStore)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, err
+	}
+	loginGuard := handlers.NewLoginGuard(limiterStore, handlers.LoginGuardConfig{
+		MaxAccountFailures: cfg.LoginMaxAttempts,
+		MaxIPFailures:      cfg.LoginMaxIPAttempts,
+		LockWindow:         cfg.LoginLockWindow,
+		BaseDelay:          cfg.LoginBackoffBase,
+		MaxDelay:           cfg.LoginBackoffMax,
+	})
+
+	strapiClient := strapi.NewClient(cfg)
+	mediaLimits := media.Limits{
+		MaxDimension:   cfg.MediaMaxDimension,
+		DefaultQuality: cfg.MediaDefaultQuality,
+		Widths:         cfg.MediaWidths,
+		Qualities:      cfg.MediaQualities,
+	}
+	mediaProcessor, err := media.NewProcessor(media.Config{
+		Origin:         cfg.StrapiURL,
+		CacheDir:       cfg.MediaCacheDir,
+		CacheSize:      int64(cfg.MediaCacheSizeMB) << 20,
+		Limits:         mediaLimits,
+		CWebP:          cfg.MediaCWebP,
+		MaxSourceBytes: int64(cfg.MediaMaxSourceMB) << 20,
+	})
+	if err != nil {
+		return nil, err
+	}
+	mediaRewriter, err := media.NewRewriter(cfg.MediaPublicURL, cfg.StrapiURL)
+	if err != nil {
+		return nil, err
+	}
+	issuer := auth.NewIssuer([]byte(cfg.JWTSecret), cfg.AccessTokenTTL, cfg.JWTIssuer, cfg.JWTAudience)
+
 	gw := &Gateway{
 		StrapiURL:   strapiURL,
 		BudibaseURL: budibaseURL,
-		JWTSecret:   []byte(cfg.JWTSecret),
+		Verifier:    verifier,
+		Revocations: revocations,
+		Redis:       redisClient,
+
+		RateLimiters:   rateLimiters,
+		TrustedProxies: cfg.TrustedProxies,
+
+		CORSOrigins: corsOrigins,
+		CORSMaxAge:  cfg.CORSMaxAge,
+
+		CacheControlProducts: cfg.CacheControlProducts,
+		CacheControlProduct:  cfg.CacheControlProduct,
+		CacheControlTaxonomy: cfg.CacheControlTaxonomy,
+		CacheControlFeeds:    cfg.CacheControlFeeds,
+		CacheControlMedia:    cfg.CacheControlMedia,
+
+		AdminPrefix: "/" + strings.Trim(cfg.AdminPrefix, "/"),
+		AdminRole:   cfg.AdminRole,
+
+		AdminSessionCookie:       cfg.AdminSessionCookie,
+		AdminSessionCookieSecure: cfg.AdminSessionCookieSecure,
+		GatewayCookies:           []string{cfg.AdminSessionCookie, cfg.GuestCartCookie},
 
-		AuthHandler:    handlers.NewAuthHandler(cfg),
-		CatalogHandler: handlers.NewCatalogHandler(cfg),
-		CartHandler:    handlers.NewCartHandler(cfg),
-		OrderHandler:   handlers.NewOrderHandler(cfg),
+		RoleClaim:       cfg.RoleClaim,
+		RolePermissions: rolePermissions,
+
+		AuthHandler: handlers.NewAuthHandler(strapiClient, issuer, sessions, revocations, loginGuard, cfg.RefreshTokenTTL),
+		CatalogHandler: handlers.NewCatalogHandler(strapiClient, handlers.CatalogConfig{
+			TaxonomyTTL:       cfg.CatalogTaxonomyTTL,
+			CategorySeparator: cfg.CatalogCategorySep,
+			TaxonomyPopulate:  cfg.CatalogTaxonomyPopulate,
+			CacheTTL:          cfg.CatalogCacheTTL,
+			CacheStale:        cfg.CatalogCacheStale,
+			CacheSize:         cfg.CatalogCacheSize,
+			PriceBuckets:      cfg.CatalogPriceBuckets,
+			Media:             mediaRewriter,
+		}),
+		CartHandler: handlers.NewCartHandler(strapiClient, handlers.CartConfig{
+			ProductPopulate:     cfg.CartProductPopulate,
+			MaxItemQuantity:     cfg.CartMaxItemQuantity,
+			StorePrice:          cfg.CartStorePrice,
+			Currency:            cfg.CartCurrency,
+			ShippingCost:        cfg.CartShippingCost,
+			FreeShippingFrom:    cfg.CartFreeShipping,
+			GuestStore:          guestCarts,
+			GuestSigner:         guestSigner,
+			GuestTTL:            cfg.GuestCartTTL,
+			GuestMaxItems:       cfg.GuestCartMaxItems,
+			GuestCookie:         cfg.GuestCartCookie,
+			GuestCookieSecure:   cfg.GuestCartCookieSecure,
+			GuestCookieSameSite: guestSameSite,
+		}),
+		SearchHandler: handlers.NewSearchHandler(strapiClient, handlers.SearchConfig{
+			ReindexInterval: cfg.SearchReindexInterval,
+			Populate:        cfg.SearchPopulate,
+			Media:           mediaRewriter,
+		}),
+		FeedHandler: handlers.NewFeedHandler(feeds.NewGenerator(strapiClient, feeds.ConfigOptions(cfg, strapiClient.BaseURL())), handlers.FeedConfig{
+			Dir:      cfg.FeedsDir,
+			Interval: cfg.FeedsInterval,
+		}),
+		MediaHandler:   handlers.NewMediaHandler(mediaProcessor, mediaLimits),
+		WebhookHandler: handlers.NewWebhookHandler(cfg.StrapiWebhookSecret),
 	}
 
+	// При входе гостевая корзина переносится в корзину пользователя
+	gw.AuthHandler.Carts = gw.CartHandler
+	// Заказ собирается из корзины и очищает её под той же блокировкой
+	gw.OrderHandler = handlers.NewOrderHandler(strapiClient, gw.CartHandler)
+
+	// Изменения в Strapi сбрасывают кеш каталога и обновляют поисковый индекс
+	gw.WebhookHandler.Subscribe(gw.CatalogHandler.HandleStrapiEvent)
+	gw.WebhookHandler.Subscribe(gw.SearchHandler.HandleStrapiEvent)
+
 	return gw, nil
 }
 
-// Middleware проверяет JWT токен
+// guestCartSweepInterval — как часто удалять истёкшие гостевые корзины из памяти
+const guestCartSweepInterval = 5 * time.Minute
+
+// Start запускает фоновые задачи Gateway; они останавливаются вместе с ctx
+func (g *Gateway) Start(ctx context.Context) {
+	go g.SearchHandler.Run(ctx)
+	go g.FeedHandler.Run(ctx)
+	// Redis удаляет истёкшие корзины сам, в памяти их нужно вычищать
+	if carts, ok := g.CartHandler.Config.GuestStore.(*guestcart.MemoryStore); ok {
+		go carts.Run(ctx, guestCartSweepInterval)
+	}
+}
+
+// Middleware проверяет JWT токен и отклоняет запросы без него
 func (g *Gateway) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+		principal, claims, errMsg := g.authenticate(c.Request.Context(), c.GetHeader("Authorization"))
+		if errMsg != "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": errMsg})
 			return
 		}
 
-		//parts := gin.H{}
-		partsSlice := splitN(authHeader, " ", 2)
-		if len(partsSlice) != 2 || partsSlice[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer {token}"})
-			return
-		}
-
-		tokenString := partsSlice[1]
+		setPrincipal(c, principal, claims)
+		c.Next()
+	}
+}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Проверка метода подписи
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+// OptionalMiddleware заполняет userID, если передан валидный токен,
+// но пропускает анонимные запросы и запросы с невалидным токеном как гостевые
+func (g *Gateway) OptionalMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			if principal, claims, errMsg := g.authenticate(c.Request.Context(), authHeader); errMsg == "" {
+				setPrincipal(c, principal, claims)
 			}
-			return g.JWTSecret, nil
-		})
-
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			return
 		}
 
-		// Извлечение информации из токена
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			c.Set("userID", claims["id"])
-		} else {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			return
+		c.Next()
+	}
+}
+
+// GuestMiddleware пропускает запросы без заголовка Authorization как гостевые,
+// а неверный или отозванный токен отклоняет с 401. Иначе пользователь с
+// истёкшим токеном молча получил бы пустую гостевую корзину вместо своей
+// и не узнал бы, что нужно обновить токен.
+func (g *Gateway) GuestMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			principal, claims, errMsg := g.authenticate(c.Request.Context(), authHeader)
+			if errMsg != "" {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": errMsg})
+				return
+			}
+			setPrincipal(c, principal, claims)
 		}
 
 		c.Next()
 	}
 }
 
+// authenticate разбирает заголовок Authorization и возвращает пользователя
+// с claims либо текст ошибки для ответа клиенту
+func (g *Gateway) authenticate(ctx context.Context, authHeader string) (*auth.Principal, jwt.MapClaims, string) {
+	if authHeader == "" {
+		return nil, nil, "Authorization header required"
+	}
+
+	partsSlice := splitN(authHeader, " ", 2)
+	if len(partsSlice) != 2 || partsSlice[0] != "Bearer" {
+		return nil, nil, "Authorization header format must be Bearer {token}"
+	}
+
+	tokenString := partsSlice[1]
+
+	// Подпись, алгоритм и exp/nbf/iss/aud проверяет Verifier
+	claims, err := g.Verifier.Verify(ctx, tokenString)
+	if err != nil {
+		return nil, nil, "Invalid token"
+	}
+
+	principal, err := auth.PrincipalFromClaims(claims, g.RoleClaim, g.RolePermissions)
+	if err != nil {
+		return nil, nil, "Invalid token claims"
+	}
+
+	revoked, err := auth.IsRevoked(ctx, g.Revocations, principal)
+	if err != nil {
+		// Хранилище отзыва недоступно — не пускаем, чтобы не принять отозванный токен
+		logger.ErrorLogger.Println("Ошибка проверки отзыва токена:", err)
+		return nil, nil, "Invalid token"
+	}
+	if revoked {
+		return nil, nil, "Token revoked"
+	}
+	return principal, claims, ""
+}
+
+// setPrincipal кладёт данные пользователя из токена в контекст запроса
+func setPrincipal(c *gin.Context, principal *auth.Principal, claims jwt.MapClaims) {
+	c.Set(auth.PrincipalKey, principal)
+	c.Set("userID", principal.UserID)
+	c.Set("claims", claims)
+}
+
 // splitN разделяет строку по разделителю и возвращает массив строк
 func splitN(s, sep string, n int) []string {
 	if n <= 0 {
@@ -108,50 +380,95 @@ func splitN(s, sep string, n int) []string {
 func (g *Gateway) SetupRouter() *gin.Engine {
 	router := gin.Default()
 
-	// Настройка ограничения скорости
-	rate, err := limiter.NewRateFromFormatted("100-M") // 100 запросов в минуту
-	if err != nil {
-		logger.ErrorLogger.Fatal("Ошибка создания лимитера:", err)
+	// Лимиты и блокировка входа считаются по ClientIP: без доверенных прокси
+	// он берётся из соединения, иначе клиент подставит любой X-Forwarded-For
+	if err := router.SetTrustedProxies(g.TrustedProxies); err != nil {
+		logger.ErrorLogger.Println("Некорректный TRUSTED_PROXIES, заголовки прокси игнорируются:", err)
+		_ = router.SetTrustedProxies(nil)
 	}
 
-	store := memory.NewStore()
-	rateLimiter := ginmiddleware.NewMiddleware(limiter.New(store, rate))
+	// CORS стоит первым, чтобы preflight-запросы не расходовали лимиты
+	router.Use(g.CORS())
+	router.Use(RequestID())
 
-	router.Use(rateLimiter)
+	// Общий лимит по IP срабатывает до аутентификации,
+	// политики маршрутов ниже считают запросы по пользователю
+	router.Use(g.RateLimit(RatePolicyGlobal))
 
-	// Применение middleware для аутентификации
-	router.Use(g.Middleware())
+	// Маршруты делятся на четыре уровня доступа: публичные, с необязательной
+	// авторизацией, гостевые (без токена или с валидным токеном) и требующие токен
+	public := router.Group("/")
+	optionalAuth := router.Group("/", g.OptionalMiddleware())
+	guestOrUser := router.Group("/", g.GuestMiddleware())
+	requiredAuth := router.Group("/", g.Middleware())
 
 	// Регистрация маршрутов для авторизации
-	authRoutes := router.Group("/api/auth")
+	publicAuthRoutes := public.Group("/api/auth", g.RateLimit(RatePolicyLogin))
+	{
+		publicAuthRoutes.POST("/login", g.AuthHandler.Login)
+		publicAuthRoutes.POST("/register", g.AuthHandler.Register)
+		publicAuthRoutes.POST("/refresh", g.AuthHandler.Refresh)
+	}
+	// Выход работает и с истёкшим access-токеном: достаточно refresh-токена
+	optionalAuth.POST("/api/auth/logout", g.RateLimit(RatePolicyDefault), g.AuthHandler.Logout)
+	authRoutes := requiredAuth.Group("/api/auth", g.RateLimit(RatePolicyDefault))
 	{
 		authRoutes.GET("/me", g.AuthHandler.GetCurrentUser)
-		// Добавьте другие маршруты авторизации
 	}
 
-	// Регистрация маршрутов для каталога
-	catalogRoutes := router.Group("/api/catalog")
+	// Регистрация маршрутов для каталога: доступен гостям,
+	// токен учитывается, если он есть
+	catalogRoutes := optionalAuth.Group("/api/catalog", g.RateLimit(RatePolicyCatalog), g.ExposeHeaders("ETag", "Last-Modified"))
 	{
-		catalogRoutes.GET("/products", g.CatalogHandler.GetProducts)
-		// Добавьте другие маршруты каталога
+		catalogRoutes.GET("/products", g.CacheControl(g.CacheControlProducts), g.CatalogHandler.GetProducts)
+		catalogRoutes.GET("/products/:id", g.CacheControl(g.CacheControlProduct), g.CatalogHandler.GetProduct)
+		catalogRoutes.GET("/categories", g.CacheControl(g.CacheControlTaxonomy), g.CatalogHandler.GetCategories)
+		catalogRoutes.GET("/brands", g.CacheControl(g.CacheControlTaxonomy), g.CatalogHandler.GetBrands)
+		catalogRoutes.GET("/search", g.SearchHandler.Search)
+		catalogRoutes.GET("/search/suggest", g.SearchHandler.Suggest)
 	}
 
-	// Регистрация маршрутов для корзины
-	cartRoutes := router.Group("/api/cart")
+	// Фиды для маркетплейсов доступны без авторизации
+	feedRoutes := public.Group("/feeds", g.RateLimit(RatePolicyDefault), g.CacheControl(g.CacheControlFeeds))
 	{
-		cartRoutes.GET("/", g.CartHandler.GetCart)
-		cartRoutes.POST("/add", g.CartHandler.AddToCart)
-		cartRoutes.POST("/remove", g.CartHandler.RemoveFromCart)
-		// Добавьте другие маршруты корзины
+		feedRoutes.GET("/yml", g.FeedHandler.YML)
+		feedRoutes.GET("/google.xml", g.FeedHandler.Google)
+	}
+
+	// Изображения из Strapi с изменением размера и формата
+	public.GET("/media/*path", g.RateLimit(RatePolicyCatalog), g.CacheControl(g.CacheControlMedia), g.MediaHandler.Serve)
+
+	// Вебхуки Strapi проверяют собственную подпись вместо JWT
+	public.POST("/webhooks/strapi", g.RateLimit(RatePolicyDefault), g.WebhookHandler.HandleStrapi)
+
+	// Регистрация маршрутов для корзины: гость работает с корзиной
+	// по токену X-Guest-Cart, после входа она переносится в Strapi
+	cartRoutes := guestOrUser.Group("/api/cart", g.ExposeHeaders(handlers.GuestCartHeader))
+	{
+		cartRoutes.GET("/", g.RateLimit(RatePolicyDefault), g.CartHandler.GetCart)
+		cartRoutes.POST("/add", g.RateLimit(RatePolicyCart), g.CartHandler.AddToCart)
+		cartRoutes.POST("/remove", g.RateLimit(RatePolicyCart), g.CartHandler.RemoveFromCart)
+		cartRoutes.PATCH("/items/:id", g.RateLimit(RatePolicyCart), g.CartHandler.UpdateCartItem)
+		cartRoutes.DELETE("/", g.RateLimit(RatePolicyCart), g.CartHandler.ClearCart)
 	}
 
 	// Регистрация маршрутов для заказов
-	orderRoutes := router.Group("/api/orders")
+	orderRoutes := requiredAuth.Group("/api/orders")
+	{
+		orderRoutes.POST("/", g.RateLimit(RatePolicyOrders), g.OrderHandler.CreateOrder)
+		orderRoutes.GET("/", g.RateLimit(RatePolicyDefault), g.OrderHandler.GetOrders)
+	}
+
+	// Служебные маршруты для сотрудников магазина
+	staffOrderRoutes := requiredAuth.Group("/api/staff/orders", g.RateLimit(RatePolicyDefault))
 	{
-		orderRoutes.POST("/", g.OrderHandler.CreateOrder)
-		orderRoutes.GET("/", g.OrderHandler.GetOrders)
-		// Добавьте другие маршруты заказов
+		staffOrderRoutes.GET("/", g.RequirePermission(auth.PermOrdersRead), g.OrderHandler.ListAllOrders)
+		staffOrderRoutes.PATCH("/:id/status", g.RequirePermission(auth.PermOrdersManage), g.OrderHandler.UpdateOrderStatus)
 	}
+	requiredAuth.DELETE("/api/staff/users/:id/sessions", g.RateLimit(RatePolicyDefault), g.RequirePermission(auth.PermSessionsRevoke), g.AuthHandler.RevokeUserSessions)
+
+	// Проксирование админки Budibase только для администраторов
+	g.setupAdminRoutes(router)
 
 	// Регистрация Swagger (если используется)
 	//router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -0,0 +1,87 @@
+// internal/gateway/gateway_test.go
+package gateway
+
+import (
+	"backend/internal/auth"
+	"backend/pkg/logger"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	logger.Init()
+}
+
+func newTestGateway(t *testing.T) (*Gateway, *auth.Issuer) {
+	t.Helper()
+
+	secret := []byte("test-secret")
+	verifier, err := auth.NewVerifier(auth.VerifierConfig{HMACSecret: secret, Algorithms: []string{"HS256"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &Gateway{
+		Verifier:    verifier,
+		Revocations: auth.NewMemoryRevocationStore(),
+		RoleClaim:   "role",
+	}
+	return g, auth.NewIssuer(secret, time.Minute, "", "")
+}
+
+// whoami отвечает идентификатором пользователя из контекста или "guest"
+func whoami(c *gin.Context) {
+	if uid, ok := c.Get("userID"); ok {
+		c.String(http.StatusOK, strconv.Itoa(uid.(int)))
+		return
+	}
+	c.String(http.StatusOK, "guest")
+}
+
+func TestGuestMiddleware(t *testing.T) {
+	g, issuer := newTestGateway(t)
+	valid, _, err := issuer.IssueAccessToken(auth.Subject{UserID: 7, Role: "customer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, _, err := auth.NewIssuer([]byte("test-secret"), -time.Minute, "", "").IssueAccessToken(auth.Subject{UserID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.GET("/guest", g.GuestMiddleware(), whoami)
+	router.GET("/optional", g.OptionalMiddleware(), whoami)
+
+	cases := []struct {
+		route  string
+		header string
+		status int
+		body   string
+	}{
+		{"/guest", "", http.StatusOK, "guest"},
+		{"/guest", "Bearer " + valid, http.StatusOK, "7"},
+		{"/guest", "Bearer " + expired, http.StatusUnauthorized, ""},
+		{"/guest", "Bearer garbage", http.StatusUnauthorized, ""},
+		{"/guest", "Basic dXNlcjpwYXNz", http.StatusUnauthorized, ""},
+		// Каталог по-прежнему отдаётся гостю и с негодным токеном
+		{"/optional", "Bearer " + expired, http.StatusOK, "guest"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.route, nil)
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tc.status || (tc.body != "" && rec.Body.String() != tc.body) {
+			t.Errorf("%s %q: %d %s, want %d %s", tc.route, tc.header, rec.Code, rec.Body, tc.status, tc.body)
+		}
+	}
+}
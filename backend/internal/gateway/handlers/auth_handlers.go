@@ -2,27 +2,51 @@
 package handlers
 
 import (
-	"backend/internal/config"
+	"backend/internal/auth"
+	"backend/internal/strapi"
 	"backend/pkg/logger"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AuthHandler struct {
-	StrapiURL string
+	Strapi      StrapiAPI
+	Tokens      *auth.Issuer
+	Sessions    auth.RefreshStore
+	Revocations auth.RevocationStore
+	Guard       *LoginGuard
+	RefreshTTL  time.Duration
+
+	// Carts переносит гостевую корзину в корзину пользователя при входе; nil — не переносить
+	Carts *CartHandler
 }
 
-func NewAuthHandler(cfg *config.Config) *AuthHandler {
+func NewAuthHandler(client StrapiAPI, tokens *auth.Issuer, sessions auth.RefreshStore, revocations auth.RevocationStore, guard *LoginGuard, refreshTTL time.Duration) *AuthHandler {
 	return &AuthHandler{
-		StrapiURL: cfg.StrapiURL,
+		Strapi:      client,
+		Tokens:      tokens,
+		Sessions:    sessions,
+		Revocations: revocations,
+		Guard:       guard,
+		RefreshTTL:  refreshTTL,
 	}
 }
 
+// tokenResponse — пара токенов, которую получает клиент после входа или обновления
+type tokenResponse struct {
+	AccessToken  string       `json:"accessToken"`
+	TokenType    string       `json:"tokenType"`
+	ExpiresIn    int          `json:"expiresIn"`
+	RefreshToken string       `json:"refreshToken"`
+	User         *strapi.User `json:"user,omitempty"`
+	// CartAdjustments — строки гостевой корзины, перенесённые не полностью
+	CartAdjustments []cartAdjustment `json:"cartAdjustments,omitempty"`
+}
+
 // GetCurrentUser godoc
 // @Summary Получить информацию о текущем пользователе
 // @Description Возвращает данные текущего пользователя
@@ -34,50 +58,325 @@ func NewAuthHandler(cfg *config.Config) *AuthHandler {
 // @Failure 500 {object} gin.H{"error": "Ошибка сервера"}
 // @Router /api/auth/me [get]
 func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
+	uid, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	// Предполагается, что userID это числовой ID пользователя
-	uid, err := strconv.Atoi(fmt.Sprintf("%v", userID))
+	user, err := h.Strapi.GetUser(c.Request.Context(), uid)
 	if err != nil {
-		logger.ErrorLogger.Println("Неверный формат userID:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID пользователя"})
+		strapiFailed(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// Login godoc
+// @Summary Вход по логину и паролю
+// @Description Проверяет учётные данные в Strapi и выдаёт access- и refresh-токены шлюза
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} tokenResponse
+// @Failure 400 {object} gin.H{"error": "Неверные данные"}
+// @Failure 401 {object} gin.H{"error": "Неверный логин или пароль"}
+// @Failure 429 {object} gin.H{"error": "Слишком много попыток входа"}
+// @Failure 500 {object} gin.H{"error": "Ошибка сервера"}
+// @Router /api/auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var loginData struct {
+		Identifier string `json:"identifier" binding:"required"`
+		Password   string `json:"password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&loginData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	// ClientIP верит X-Forwarded-For только от доверенных прокси роутера
+	// (TRUSTED_PROXIES), поэтому подменой заголовка блокировку не обойти
+	ip := c.ClientIP()
+
+	lock, err := h.Guard.Check(ctx, loginData.Identifier, ip)
+	if err != nil {
+		// Хранилище счётчиков недоступно — не блокируем вход, но фиксируем
+		logger.ErrorLogger.Println("Ошибка проверки блокировки входа:", err)
+	}
+	if lock.Locked {
+		logger.AuditLogger.Printf("event=login_rejected_locked account=%q ip=%s", loginData.Identifier, ip)
+		c.Header("Retry-After", strconv.Itoa(int(lock.RetryAfter.Seconds())))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Слишком много попыток входа"})
+		return
+	}
+
+	resp, err := h.Strapi.Login(ctx, loginData.Identifier, loginData.Password)
+	if err != nil {
+		if status := strapi.StatusCode(err); status == http.StatusBadRequest || status == http.StatusUnauthorized {
+			delay, guardErr := h.Guard.Failure(ctx, loginData.Identifier, ip)
+			if guardErr != nil {
+				logger.ErrorLogger.Println("Ошибка учёта неудачного входа:", guardErr)
+			}
+			sleepContext(ctx, delay)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный логин или пароль"})
+			return
+		}
+		strapiFailed(c, err)
+		return
+	}
+
+	if err := h.Guard.Success(ctx, loginData.Identifier, ip); err != nil {
+		logger.ErrorLogger.Println("Ошибка сброса счётчика входа:", err)
+	}
+
+	h.issueTokens(c, http.StatusOK, resp.User.ID)
+}
+
+// Register godoc
+// @Summary Регистрация пользователя
+// @Description Создаёт пользователя в Strapi и сразу выдаёт токены шлюза
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 201 {object} tokenResponse
+// @Failure 400 {object} gin.H{"error": "Неверные данные"}
+// @Failure 500 {object} gin.H{"error": "Ошибка сервера"}
+// @Router /api/auth/register [post]
+func (h *AuthHandler) Register(c *gin.Context) {
+	var registerData struct {
+		Username string `json:"username" binding:"required"`
+		Email    string `json:"email" binding:"required,email"`
+		Password string `json:"password" binding:"required,min=6"`
+	}
+
+	if err := c.ShouldBindJSON(&registerData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	resp, err := h.Strapi.Register(c.Request.Context(), registerData.Username, registerData.Email, registerData.Password)
+	if err != nil {
+		var se *strapi.Error
+		if errors.As(err, &se) && se.StatusCode == http.StatusBadRequest {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось зарегистрироваться", "details": se.Message})
+			return
+		}
+		strapiFailed(c, err)
+		return
+	}
+
+	h.issueTokens(c, http.StatusCreated, resp.User.ID)
+}
+
+// Refresh godoc
+// @Summary Обновить токены
+// @Description Обменивает refresh-токен на новую пару; старый refresh-токен гасится
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} tokenResponse
+// @Failure 400 {object} gin.H{"error": "Неверные данные"}
+// @Failure 401 {object} gin.H{"error": "Недействительный refresh-токен"}
+// @Failure 500 {object} gin.H{"error": "Ошибка сервера"}
+// @Failure 503 {object} gin.H{"error": "Сервис временно недоступен"}
+// @Router /api/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var refreshData struct {
+		RefreshToken string `json:"refreshToken" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&refreshData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Роль и блокировку перечитываем из Strapi до ротации: если Strapi
+	// недоступен, предъявленный токен остаётся в силе и запрос можно повторить
+	session, err := h.Sessions.Lookup(ctx, refreshData.RefreshToken)
+	if err != nil {
+		h.refreshRejected(c, refreshData.RefreshToken, err)
+		return
+	}
+	user, err := h.Strapi.GetUser(ctx, session.UserID)
+	if err != nil {
+		if strapi.IsNotFound(err) {
+			_ = h.Sessions.Revoke(ctx, refreshData.RefreshToken)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Недействительный refresh-токен"})
+			return
+		}
+		logger.ErrorLogger.Println("Ошибка запроса к Strapi при обновлении токенов:", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Сервис временно недоступен"})
+		return
+	}
+	if user.Blocked {
+		_ = h.Sessions.Revoke(ctx, refreshData.RefreshToken)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь заблокирован"})
 		return
 	}
 
-	resp, err := http.Get(fmt.Sprintf("%s/api/users/%d", h.StrapiURL, uid))
+	_, refreshToken, err := h.Sessions.Rotate(ctx, refreshData.RefreshToken, h.RefreshTTL)
 	if err != nil {
-		logger.ErrorLogger.Println("Ошибка запроса к Strapi:", err)
+		h.refreshRejected(c, refreshData.RefreshToken, err)
+		return
+	}
+
+	accessToken, expiresAt, err := h.Tokens.IssueAccessToken(auth.Subject{UserID: user.ID, Role: roleType(user)})
+	if err != nil {
+		logger.ErrorLogger.Println("Ошибка выпуска access-токена:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		logger.ErrorLogger.Printf("Strapi ответил с ошибкой %d: %s", resp.StatusCode, string(body))
+	c.JSON(http.StatusOK, tokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(time.Until(expiresAt).Seconds()),
+		RefreshToken: refreshToken,
+	})
+}
+
+// refreshRejected отвечает 401 на недействительный refresh-токен;
+// повторное предъявление погашенного токена отзывает всю семью
+func (h *AuthHandler) refreshRejected(c *gin.Context, token string, err error) {
+	if errors.Is(err, auth.ErrRefreshReused) {
+		_ = h.Sessions.Revoke(c.Request.Context(), token)
+		logger.ErrorLogger.Println("Повторное использование refresh-токена, семья отозвана")
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "Недействительный refresh-токен"})
+}
+
+// Logout godoc
+// @Summary Выход
+// @Description Отзывает текущий access-токен (если передан) и refresh-токен со всей цепочкой его ротации
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} gin.H{"message": "Вы вышли из системы"}
+// @Failure 400 {object} gin.H{"error": "Неверные данные"}
+// @Failure 500 {object} gin.H{"error": "Ошибка сервера"}
+// @Router /api/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var logoutData struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&logoutData); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+			return
+		}
+	}
+
+	principal, hasPrincipal := auth.PrincipalFrom(c)
+	if !hasPrincipal && logoutData.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	if hasPrincipal && principal.TokenID != "" {
+		if err := h.Revocations.RevokeToken(c.Request.Context(), principal.TokenID, principal.ExpiresAt); err != nil {
+			logger.ErrorLogger.Println("Ошибка отзыва access-токена:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+			return
+		}
+	}
+
+	// Неизвестный refresh-токен не считаем ошибкой: результат для клиента тот же
+	if logoutData.RefreshToken != "" {
+		_ = h.Sessions.Revoke(c.Request.Context(), logoutData.RefreshToken)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Вы вышли из системы"})
+}
+
+// RevokeUserSessions godoc
+// @Summary Завершить все сессии пользователя
+// @Description Отзывает все access- и refresh-токены пользователя, выпущенные до текущего момента
+// @Tags Staff
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} gin.H{"message": "Сессии пользователя завершены"}
+// @Failure 400 {object} gin.H{"error": "Неверный ID пользователя"}
+// @Failure 403 {object} gin.H{"error": "Доступ запрещён"}
+// @Failure 500 {object} gin.H{"error": "Ошибка сервера"}
+// @Router /api/staff/users/{id}/sessions [delete]
+func (h *AuthHandler) RevokeUserSessions(c *gin.Context) {
+	uid, err := strconv.Atoi(c.Param("id"))
+	if err != nil || uid <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID пользователя"})
+		return
+	}
+
+	// Отметку держим столько же, сколько живёт самый долгий токен — refresh
+	if err := h.Revocations.RevokeUser(c.Request.Context(), uid, time.Now(), h.RefreshTTL); err != nil {
+		logger.ErrorLogger.Println("Ошибка отзыва сессий пользователя:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
 		return
 	}
+	if err := h.Sessions.RevokeUser(c.Request.Context(), uid); err != nil {
+		logger.ErrorLogger.Println("Ошибка отзыва refresh-токенов пользователя:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+		return
+	}
+
+	if principal, ok := auth.PrincipalFrom(c); ok {
+		logger.InfoLogger.Printf("Пользователь %d завершил все сессии пользователя %d", principal.UserID, uid)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Сессии пользователя завершены"})
+}
+
+// issueTokens загружает пользователя с ролью и отвечает новой парой токенов
+func (h *AuthHandler) issueTokens(c *gin.Context, status int, userID int) {
+	user, err := h.Strapi.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		strapiFailed(c, err)
+		return
+	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	subject := auth.Subject{UserID: user.ID, Role: roleType(user)}
+
+	accessToken, expiresAt, err := h.Tokens.IssueAccessToken(subject)
 	if err != nil {
-		logger.ErrorLogger.Println("Ошибка чтения ответа от Strapi:", err)
+		logger.ErrorLogger.Println("Ошибка выпуска access-токена:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
 		return
 	}
 
-	var user interface{}
-	if err := json.Unmarshal(body, &user); err != nil {
-		logger.ErrorLogger.Println("Ошибка парсинга ответа Strapi:", err)
+	refreshToken, err := h.Sessions.Create(c.Request.Context(), auth.RefreshSession{
+		UserID:    subject.UserID,
+		Role:      subject.Role,
+		ExpiresAt: time.Now().Add(h.RefreshTTL),
+	})
+	if err != nil {
+		logger.ErrorLogger.Println("Ошибка выпуска refresh-токена:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	var adjusted []cartAdjustment
+	if h.Carts != nil {
+		adjusted = h.Carts.MergeGuestCart(c, user.ID)
+	}
+
+	c.JSON(status, tokenResponse{
+		AccessToken:     accessToken,
+		TokenType:       "Bearer",
+		ExpiresIn:       int(time.Until(expiresAt).Seconds()),
+		RefreshToken:    refreshToken,
+		User:            user,
+		CartAdjustments: adjusted,
+	})
 }
 
-// Дополнительные хендлеры для регистрации и входа можно добавить здесь
+// roleType возвращает тип роли users-permissions ("authenticated", "admin", ...)
+func roleType(user *strapi.User) string {
+	if user.Role == nil {
+		return ""
+	}
+	return user.Role.Type
+}
@@ -0,0 +1,83 @@
+// internal/gateway/handlers/auth_handlers_test.go
+package handlers
+
+import (
+	"backend/internal/auth"
+	"backend/internal/strapi"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTestAuthHandler(fake *fakeStrapi) *AuthHandler {
+	return NewAuthHandler(fake,
+		auth.NewIssuer([]byte("test-secret"), time.Minute, "", ""),
+		auth.NewMemoryRefreshStore(), auth.NewMemoryRevocationStore(), nil, time.Hour)
+}
+
+func newRefreshToken(t *testing.T, h *AuthHandler, userID int) string {
+	t.Helper()
+	token, err := h.Sessions.Create(context.Background(), auth.RefreshSession{
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func refresh(t *testing.T, h *AuthHandler, token string) int {
+	t.Helper()
+	rec := serve(t, h.Refresh, http.MethodPost, "/refresh", "/refresh", 0, map[string]string{"refreshToken": token})
+	return rec.Code
+}
+
+func TestRefreshKeepsTokenWhenStrapiUnavailable(t *testing.T) {
+	fake := newFakeStrapi()
+	fake.users[7] = strapi.User{ID: 7}
+	h := newTestAuthHandler(fake)
+	token := newRefreshToken(t, h, 7)
+
+	fake.err = errors.New("strapi: 502")
+	if code := refresh(t, h, token); code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", code)
+	}
+
+	fake.err = nil
+	if code := refresh(t, h, token); code != http.StatusOK {
+		t.Fatalf("retry status = %d, want 200", code)
+	}
+}
+
+func TestRefreshRevokesDeletedOrBlockedUser(t *testing.T) {
+	fake := newFakeStrapi()
+	fake.users[8] = strapi.User{ID: 8, Blocked: true}
+	h := newTestAuthHandler(fake)
+
+	for _, userID := range []int{7, 8} {
+		token := newRefreshToken(t, h, userID)
+		if code := refresh(t, h, token); code != http.StatusUnauthorized {
+			t.Fatalf("user %d: status = %d, want 401", userID, code)
+		}
+		if _, err := h.Sessions.Lookup(context.Background(), token); !errors.Is(err, auth.ErrRefreshInvalid) {
+			t.Fatalf("user %d: token not revoked: %v", userID, err)
+		}
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	fake := newFakeStrapi()
+	fake.users[7] = strapi.User{ID: 7}
+	h := newTestAuthHandler(fake)
+	token := newRefreshToken(t, h, 7)
+
+	if code := refresh(t, h, token); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if code := refresh(t, h, token); code != http.StatusUnauthorized {
+		t.Fatalf("reuse status = %d, want 401", code)
+	}
+}
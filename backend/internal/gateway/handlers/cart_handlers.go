@@ -2,78 +2,105 @@
 package handlers
 
 import (
-	"backend/internal/config"
+	"backend/internal/guestcart"
+	"backend/internal/strapi"
 	"backend/pkg/logger"
-	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type CartHandler struct {
-	StrapiURL string
+	Strapi StrapiAPI
+	Config CartConfig
+
+	// locks упорядочивают изменения корзины одного пользователя
+	locks userLocks
 }
 
-func NewCartHandler(cfg *config.Config) *CartHandler {
+// CartConfig — настройки корзины
+type CartConfig struct {
+	// ProductPopulate — связи товара, которые нужны корзине (изображения, варианты с остатками)
+	ProductPopulate []string
+	// MaxItemQuantity — сколько единиц одного товара можно положить в корзину; 0 — без ограничения
+	MaxItemQuantity int
+	// StorePrice сохраняет цену в строке корзины Strapi, чтобы замечать её изменение;
+	// требует поля price в коллекции carts
+	StorePrice bool
+
+	// Currency — валюта цен каталога (ISO 4217)
+	Currency string
+	// ShippingCost — стоимость доставки в единицах валюты каталога
+	ShippingCost int
+	// FreeShippingFrom — сумма заказа, с которой доставка бесплатна; 0 — всегда платная
+	FreeShippingFrom int
+
+	// GuestStore хранит корзины гостей; nil — корзина только для вошедших
+	GuestStore guestcart.Store
+	// GuestSigner подписывает токены гостевых корзин
+	GuestSigner *guestcart.Signer
+	// GuestTTL — сколько живёт гостевая корзина с последнего изменения
+	GuestTTL time.Duration
+	// GuestMaxItems ограничивает число строк в гостевой корзине
+	GuestMaxItems int
+
+	// Cookie с токеном гостевой корзины
+	GuestCookie         string
+	GuestCookieSecure   bool
+	GuestCookieSameSite http.SameSite
+}
+
+func NewCartHandler(client StrapiAPI, cfg CartConfig) *CartHandler {
 	return &CartHandler{
-		StrapiURL: cfg.StrapiURL,
+		Strapi: client,
+		Config: cfg,
 	}
 }
 
 // Получение содержимого корзины
 func (h *CartHandler) GetCart(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
+	if isGuest(c) {
+		h.getGuestCart(c)
 		return
 	}
 
-	resp, err := http.Get(fmt.Sprintf("%s/api/carts?filters[user][$eq]=%d&populate=*", h.StrapiURL, userID))
-	if err != nil {
-		logger.ErrorLogger.Println("Ошибка запроса к Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+	uid, ok := currentUserID(c)
+	if !ok {
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		logger.ErrorLogger.Printf("Strapi ответил с ошибкой %d: %s", resp.StatusCode, string(body))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
-		return
-	}
-
-	body, err := ioutil.ReadAll(resp.Body)
+	ctx := c.Request.Context()
+	cart, err := h.Strapi.GetCartItems(ctx, uid)
 	if err != nil {
-		logger.ErrorLogger.Println("Ошибка чтения ответа от Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+		strapiFailed(c, err)
 		return
 	}
 
-	var cart interface{}
-	if err := json.Unmarshal(body, &cart); err != nil {
-		logger.ErrorLogger.Println("Ошибка парсинга ответа Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+	lines := userCartLines(cart.Data)
+
+	// Цены и остатки берём из каталога, а не из связи в строке корзины
+	products, err := h.loadCartProducts(ctx, productIDs(lines))
+	if err != nil {
+		strapiFailed(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, cart)
+	c.JSON(http.StatusOK, gin.H{"data": h.buildCartView(lines, products)})
 }
 
 // Добавление товара в корзину
 func (h *CartHandler) AddToCart(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
-		return
-	}
-
 	var addData struct {
-		ProductID int `json:"productId"`
-		Quantity  int `json:"quantity"`
+		ProductID int    `json:"productId"`
+		Quantity  int    `json:"quantity"`
+		Size      string `json:"size"`
+		Color     string `json:"color"`
 	}
 
 	if err := c.ShouldBindJSON(&addData); err != nil {
@@ -81,92 +108,378 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 		return
 	}
 
-	payload := map[string]interface{}{
-		"data": map[string]interface{}{
-			"user":     userID,
-			"product":  addData.ProductID,
-			"quantity": addData.Quantity,
-		},
+	if addData.ProductID <= 0 || addData.Quantity <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные", "details": "productId и quantity должны быть положительными"})
+		return
 	}
 
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		logger.ErrorLogger.Println("Ошибка маршалинга данных корзины:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+	if isGuest(c) && !h.guestCartsEnabled(c) {
 		return
 	}
 
-	resp, err := http.Post(fmt.Sprintf("%s/api/carts", h.StrapiURL), "application/json", bytes.NewBuffer(payloadBytes))
+	product, ok := h.cartProduct(c, addData.ProductID)
+	if !ok {
+		return
+	}
+	variant, err := resolveVariant(product, addData.Size, addData.Color)
 	if err != nil {
-		logger.ErrorLogger.Println("Ошибка отправки запроса к Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+		cartFailed(c, err)
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated {
-		body, _ := ioutil.ReadAll(resp.Body)
-		logger.ErrorLogger.Printf("Strapi ответил с ошибкой %d: %s", resp.StatusCode, string(body))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+	if isGuest(c) {
+		h.addToGuestCart(c, product, variant, addData.Quantity)
 		return
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	unlock := h.locks.lock(uid)
+	defer unlock()
+
+	// Повторное добавление того же товара в том же размере и цвете
+	// увеличивает существующую строку
+	cart, err := h.Strapi.GetCartItems(ctx, uid)
 	if err != nil {
-		logger.ErrorLogger.Println("Ошибка чтения ответа от Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+		strapiFailed(c, err)
+		return
+	}
+	if lines := findCartLines(cart.Data, addData.ProductID, variant); len(lines) > 0 {
+		// Дубли, созданные параллельно на другом экземпляре, сливаются в первую строку
+		quantity := addData.Quantity
+		for _, line := range lines {
+			quantity += line.Quantity
+		}
+		if err := h.validateQuantity(product, variant, quantity); err != nil {
+			cartFailed(c, err)
+			return
+		}
+		item, err := h.Strapi.UpdateCartItem(ctx, lines[0].DocumentID, strapi.CartItemUpdate{
+			Quantity: quantity,
+			Price:    h.priceSnapshot(product, variant),
+		})
+		if err != nil {
+			strapiFailed(c, err)
+			return
+		}
+		for _, duplicate := range lines[1:] {
+			if err := h.Strapi.DeleteCartItem(ctx, duplicate.DocumentID); err != nil && !strapi.IsNotFound(err) {
+				strapiFailed(c, err)
+				return
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"data": item})
 		return
 	}
 
-	var addedCart interface{}
-	if err := json.Unmarshal(body, &addedCart); err != nil {
-		logger.ErrorLogger.Println("Ошибка парсинга ответа Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+	if err := h.validateQuantity(product, variant, addData.Quantity); err != nil {
+		cartFailed(c, err)
+		return
+	}
+	item, err := h.Strapi.CreateCartItem(ctx, strapi.CartItemInput{
+		User:     uid,
+		Product:  addData.ProductID,
+		Quantity: addData.Quantity,
+		Size:     variant.Size,
+		Color:    variant.Color,
+		Price:    h.priceSnapshot(product, variant),
+	})
+	if err != nil {
+		strapiFailed(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, addedCart)
+	c.JSON(http.StatusCreated, gin.H{"data": item})
 }
 
-// Удаление товара из корзины
-func (h *CartHandler) RemoveFromCart(c *gin.Context) {
-	_, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
+// Изменение количества товара в строке корзины
+func (h *CartHandler) UpdateCartItem(c *gin.Context) {
+	var updateData struct {
+		Quantity int `json:"quantity"`
+	}
+
+	if err := c.ShouldBindJSON(&updateData); err != nil || updateData.Quantity <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные", "details": "quantity должно быть положительным"})
+		return
+	}
+	itemID := c.Param("id")
+	if itemID == "" || len(itemID) > 64 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Товар в корзине не найден"})
 		return
 	}
 
-	var removeData struct {
-		CartItemID int `json:"cartItemId"`
+	if isGuest(c) {
+		h.updateGuestCartItem(c, itemID, updateData.Quantity)
+		return
 	}
 
-	if err := c.ShouldBindJSON(&removeData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+	uid, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/api/carts/%d", h.StrapiURL, removeData.CartItemID), nil)
+	unlock := h.locks.lock(uid)
+	defer unlock()
+
+	item, ok := h.userCartItem(c, uid, itemID)
+	if !ok {
+		return
+	}
+	if item.Product == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Товар не найден"})
+		return
+	}
+	product, ok := h.cartProduct(c, item.Product.ID)
+	if !ok {
+		return
+	}
+	variant := cartVariant{Size: item.Size, Color: item.Color}
+	if err := h.validateQuantity(product, variant, updateData.Quantity); err != nil {
+		cartFailed(c, err)
+		return
+	}
+
+	updated, err := h.Strapi.UpdateCartItem(c.Request.Context(), item.DocumentID, strapi.CartItemUpdate{
+		Quantity: updateData.Quantity,
+		Price:    h.priceSnapshot(product, variant),
+	})
 	if err != nil {
-		logger.ErrorLogger.Println("Ошибка создания запроса к Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+		if strapi.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Товар в корзине не найден"})
+			return
+		}
+		strapiFailed(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": updated})
+}
+
+// Очистка корзины
+func (h *CartHandler) ClearCart(c *gin.Context) {
+	if isGuest(c) {
+		h.clearGuestCart(c)
+		return
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	unlock := h.locks.lock(uid)
+	defer unlock()
+
+	ctx := c.Request.Context()
+	cart, err := h.Strapi.GetCartItems(ctx, uid)
 	if err != nil {
-		logger.ErrorLogger.Println("Ошибка отправки запроса к Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+		strapiFailed(c, err)
 		return
 	}
-	defer resp.Body.Close()
+	for _, item := range cart.Data {
+		// Строку мог удалить параллельный запрос — это не ошибка
+		if err := h.Strapi.DeleteCartItem(ctx, item.DocumentID); err != nil && !strapi.IsNotFound(err) {
+			strapiFailed(c, err)
+			return
+		}
+	}
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		body, _ := ioutil.ReadAll(resp.Body)
-		logger.ErrorLogger.Printf("Strapi ответил с ошибкой %d: %s", resp.StatusCode, string(body))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+	c.JSON(http.StatusOK, gin.H{"message": "Корзина очищена"})
+}
+
+// Удаление товара из корзины
+func (h *CartHandler) RemoveFromCart(c *gin.Context) {
+	var removeData struct {
+		CartItemID cartItemRef `json:"cartItemId"`
+	}
+
+	if err := c.ShouldBindJSON(&removeData); err != nil || removeData.CartItemID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	if isGuest(c) {
+		h.removeFromGuestCart(c, string(removeData.CartItemID))
+		return
+	}
+
+	uid, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	unlock := h.locks.lock(uid)
+	defer unlock()
+
+	item, ok := h.userCartItem(c, uid, string(removeData.CartItemID))
+	if !ok {
+		return
+	}
+
+	if err := h.Strapi.DeleteCartItem(c.Request.Context(), item.DocumentID); err != nil {
+		if strapi.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Товар в корзине не найден"})
+			return
+		}
+		strapiFailed(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Товар удалён из корзины"})
 }
+
+// cartProduct загружает опубликованный товар для проверки остатка;
+// отвечает 404, если товара нет
+func (h *CartHandler) cartProduct(c *gin.Context, productID int) (strapi.Product, bool) {
+	products, err := h.loadCartProducts(c.Request.Context(), []int{productID})
+	if err != nil {
+		strapiFailed(c, err)
+		return strapi.Product{}, false
+	}
+	product, ok := products[productID]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Товар не найден"})
+		return strapi.Product{}, false
+	}
+	return product, true
+}
+
+// validateQuantity проверяет итоговое количество товара в строке
+// по ограничению на строку и остатку выбранного варианта
+func (h *CartHandler) validateQuantity(product strapi.Product, variant cartVariant, quantity int) error {
+	if limit := h.Config.MaxItemQuantity; limit > 0 && quantity > limit {
+		return &quantityLimitError{Max: limit}
+	}
+	if stock, tracked := product.VariantStock(variant.Size, variant.Color); tracked && quantity > stock {
+		return &stockError{Available: stock}
+	}
+	return nil
+}
+
+// priceSnapshot — цена для записи в строку корзины: клиент видел её,
+// когда менял строку, и с ней сравнивается цена при показе корзины
+func (h *CartHandler) priceSnapshot(product strapi.Product, variant cartVariant) *int {
+	if !h.Config.StorePrice {
+		return nil
+	}
+	price := product.VariantPrice(variant.Size, variant.Color)
+	return &price
+}
+
+// findCartLine возвращает строку корзины с товаром в данном размере и цвете или nil
+func findCartLine(items []strapi.CartItem, productID int, variant cartVariant) *strapi.CartItem {
+	if lines := findCartLines(items, productID, variant); len(lines) > 0 {
+		return &lines[0]
+	}
+	return nil
+}
+
+// findCartLines возвращает все строки корзины с товаром в данном размере и цвете
+func findCartLines(items []strapi.CartItem, productID int, variant cartVariant) []strapi.CartItem {
+	var lines []strapi.CartItem
+	for _, item := range items {
+		if item.Product != nil && item.Product.ID == productID &&
+			strings.EqualFold(item.Size, variant.Size) && strings.EqualFold(item.Color, variant.Color) {
+			lines = append(lines, item)
+		}
+	}
+	return lines
+}
+
+var (
+	errGuestCartFull    = errors.New("гостевая корзина заполнена")
+	errCartItemNotFound = errors.New("строка корзины не найдена")
+)
+
+// stockError — запрошено больше, чем есть на складе
+type stockError struct {
+	Available int
+}
+
+func (e *stockError) Error() string {
+	return "недостаточно товара на складе: доступно " + strconv.Itoa(e.Available)
+}
+
+// quantityLimitError — превышено ограничение на количество одного товара
+type quantityLimitError struct {
+	Max int
+}
+
+func (e *quantityLimitError) Error() string {
+	return "превышено количество товара в строке: максимум " + strconv.Itoa(e.Max)
+}
+
+// cartFailed отвечает клиенту по ошибке изменения корзины
+func cartFailed(c *gin.Context, err error) {
+	var stockErr *stockError
+	var limitErr *quantityLimitError
+	var variantErr *variantError
+	switch {
+	case errors.As(err, &variantErr):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Неверный вариант товара",
+			"details": variantErr.Message,
+			"sizes":   variantErr.Sizes,
+			"colors":  variantErr.Colors,
+		})
+	case errors.As(err, &stockErr):
+		c.JSON(http.StatusConflict, gin.H{"error": "Недостаточно товара на складе", "available": stockErr.Available})
+	case errors.As(err, &limitErr):
+		c.JSON(http.StatusConflict, gin.H{"error": "Превышено количество товара", "max": limitErr.Max})
+	case errors.Is(err, errGuestCartFull):
+		c.JSON(http.StatusConflict, gin.H{"error": "В корзине слишком много товаров"})
+	case errors.Is(err, errCartItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Товар в корзине не найден"})
+	case strapi.StatusCode(err) != 0:
+		strapiFailed(c, err)
+	default:
+		logger.ErrorLogger.Println("Ошибка изменения корзины:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+	}
+}
+
+// userCartItem находит строку корзины текущего пользователя. Любое изменение
+// строки должно идти через эту проверку: чужая строка неотличима от
+// несуществующей, чтобы по ответу нельзя было перебирать чужие корзины.
+func (h *CartHandler) userCartItem(c *gin.Context, uid int, id string) (*strapi.CartItem, bool) {
+	item, err := h.Strapi.GetUserCartItem(c.Request.Context(), uid, id)
+	if err != nil {
+		if strapi.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Товар в корзине не найден"})
+			return nil, false
+		}
+		strapiFailed(c, err)
+		return nil, false
+	}
+	return item, true
+}
+
+// cartItemRef — идентификатор строки корзины: числовой id или documentId.
+// Клиенты присылают его и числом, и строкой.
+type cartItemRef string
+
+func (r *cartItemRef) UnmarshalJSON(data []byte) error {
+	var id int
+	if err := json.Unmarshal(data, &id); err == nil {
+		if id <= 0 {
+			return fmt.Errorf("некорректный id строки корзины: %d", id)
+		}
+		*r = cartItemRef(strconv.Itoa(id))
+		return nil
+	}
+
+	var documentID string
+	if err := json.Unmarshal(data, &documentID); err != nil {
+		return err
+	}
+	documentID = strings.TrimSpace(documentID)
+	if len(documentID) > 64 {
+		return fmt.Errorf("слишком длинный id строки корзины")
+	}
+	*r = cartItemRef(documentID)
+	return nil
+}
@@ -0,0 +1,201 @@
+// internal/gateway/handlers/cart_handlers_test.go
+package handlers
+
+import (
+	"backend/internal/strapi"
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newCartTest возвращает хендлер корзины и фейк с товаром 1 и строкой
+// пользователя 7 с этим товаром
+func newCartTest() (*CartHandler, *fakeStrapi, strapi.CartItem) {
+	fake := newFakeStrapi()
+	fake.products[1] = strapi.Product{ID: 1, DocumentID: "p1", Name: "Футболка", Price: 1000}
+	line := fake.addCartLine(7, 1, 2, "")
+	return NewCartHandler(fake, CartConfig{}), fake, line
+}
+
+func TestRemoveForeignCartItemIsNotFound(t *testing.T) {
+	h, fake, line := newCartTest()
+
+	for _, ref := range []interface{}{line.ID, line.DocumentID} {
+		rec := serve(t, h.RemoveFromCart, http.MethodDelete, "/cart", "/cart", 8, map[string]interface{}{"cartItemId": ref})
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("cartItemId %v: status = %d, want 404: %s", ref, rec.Code, rec.Body)
+		}
+	}
+	if fake.called("DeleteCartItem") {
+		t.Fatal("DeleteCartItem called for another user's line")
+	}
+	if len(fake.carts) != 1 {
+		t.Fatalf("cart lines = %d, want the owner's line intact", len(fake.carts))
+	}
+
+	rec := serve(t, h.RemoveFromCart, http.MethodDelete, "/cart", "/cart", 7, map[string]interface{}{"cartItemId": line.DocumentID})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("owner: status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestUpdateForeignCartItemIsNotFound(t *testing.T) {
+	h, fake, line := newCartTest()
+
+	rec := serve(t, h.UpdateCartItem, http.MethodPut, "/cart/:id", "/cart/"+line.DocumentID, 8, map[string]int{"quantity": 5})
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404: %s", rec.Code, rec.Body)
+	}
+	if fake.called("UpdateCartItem") {
+		t.Fatal("UpdateCartItem called for another user's line")
+	}
+	if fake.carts[0].Quantity != 2 {
+		t.Fatalf("quantity = %d, want 2", fake.carts[0].Quantity)
+	}
+
+	rec = serve(t, h.UpdateCartItem, http.MethodPut, "/cart/:id", "/cart/"+line.DocumentID, 7, map[string]int{"quantity": 5})
+	if rec.Code != http.StatusOK || fake.carts[0].Quantity != 5 {
+		t.Fatalf("owner: status = %d, quantity = %d: %s", rec.Code, fake.carts[0].Quantity, rec.Body)
+	}
+}
+
+func TestAddToCartNeverIncrementsForeignLine(t *testing.T) {
+	h, fake, _ := newCartTest()
+
+	rec := serve(t, h.AddToCart, http.MethodPost, "/cart", "/cart", 8, map[string]int{"productId": 1, "quantity": 1})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body)
+	}
+	if fake.called("UpdateCartItem") {
+		t.Fatal("UpdateCartItem called: another user's line was incremented")
+	}
+	if fake.carts[0].Quantity != 2 {
+		t.Fatalf("owner's quantity = %d, want 2", fake.carts[0].Quantity)
+	}
+	if len(fake.carts) != 2 || fake.carts[1].User.ID != 8 {
+		t.Fatalf("carts = %+v, want a new line for user 8", fake.carts)
+	}
+
+	rec = serve(t, h.AddToCart, http.MethodPost, "/cart", "/cart", 7, map[string]int{"productId": 1, "quantity": 1})
+	if rec.Code != http.StatusOK || fake.carts[0].Quantity != 3 {
+		t.Fatalf("owner: status = %d, quantity = %d, want the line incremented: %s", rec.Code, fake.carts[0].Quantity, rec.Body)
+	}
+}
+
+func TestConcurrentAddToCartCreatesOneLine(t *testing.T) {
+	h, fake, _ := newCartTest()
+	fake.latency = 5 * time.Millisecond
+
+	const requests = 20
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serve(t, h.AddToCart, http.MethodPost, "/cart", "/cart", 8, map[string]int{"productId": 1, "quantity": 1})
+		}()
+	}
+	wg.Wait()
+
+	var lines []strapi.CartItem
+	for _, item := range fake.carts {
+		if item.User.ID == 8 {
+			lines = append(lines, item)
+		}
+	}
+	if len(lines) != 1 || lines[0].Quantity != requests {
+		t.Fatalf("user 8 lines = %+v, want one line with quantity %d", lines, requests)
+	}
+	if len(h.locks.locks) != 0 {
+		t.Fatalf("locks = %d, want released", len(h.locks.locks))
+	}
+}
+
+func TestAddToCartMergesDuplicateLines(t *testing.T) {
+	h, fake, line := newCartTest()
+	fake.addCartLine(7, 1, 3, "")
+
+	rec := serve(t, h.AddToCart, http.MethodPost, "/cart", "/cart", 7, map[string]int{"productId": 1, "quantity": 1})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if len(fake.carts) != 1 || fake.carts[0].DocumentID != line.DocumentID || fake.carts[0].Quantity != 6 {
+		t.Fatalf("carts = %+v, want one line with quantity 6", fake.carts)
+	}
+}
+
+func TestCartListPriceIgnoresOldPriceForVariantPrice(t *testing.T) {
+	oldPrice, xlPrice := 1500, 1200
+	product := strapi.Product{
+		ID:       1,
+		Price:    1000,
+		OldPrice: &oldPrice,
+		Variants: []strapi.Variant{{Size: "M"}, {Size: "XL", Price: &xlPrice}},
+	}
+	h := NewCartHandler(newFakeStrapi(), CartConfig{})
+
+	view := h.buildCartView([]cartLine{
+		{ID: "m", ProductID: 1, Quantity: 1, Variant: cartVariant{Size: "M"}},
+		{ID: "xl", ProductID: 1, Quantity: 1, Variant: cartVariant{Size: "XL"}},
+	}, map[int]strapi.Product{1: product})
+
+	m, xl := view.Items[0], view.Items[1]
+	scale := int64(view.Totals.MinorUnits)
+	if m.UnitPrice != 1000*scale || m.ListPrice != 1500*scale || m.Discount != 500*scale {
+		t.Fatalf("M: %+v, want the product discount", m)
+	}
+	if xl.UnitPrice != 1200*scale || xl.ListPrice != 1200*scale || xl.Discount != 0 {
+		t.Fatalf("XL: %+v, want no discount on the variant's own price", xl)
+	}
+}
+
+func TestLoadProductsByIDChunksRequests(t *testing.T) {
+	fake := newFakeStrapi()
+	var ids []int
+	for id := 1; id <= 250; id++ {
+		fake.products[id] = strapi.Product{ID: id}
+		ids = append(ids, id)
+	}
+	ids = append(ids, 1, 2, 999)
+
+	products, err := loadProductsByID(context.Background(), fake, nil, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 250 {
+		t.Fatalf("loaded %d products, want 250", len(products))
+	}
+	if calls := fake.count("GetProducts"); calls != 3 {
+		t.Fatalf("GetProducts calls = %d, want 3", calls)
+	}
+}
+
+func TestClearCartSerializesWithAddToCart(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		h, fake, _ := newCartTest()
+		fake.latency = 5 * time.Millisecond
+
+		var wg sync.WaitGroup
+		var added int
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			serve(t, h.ClearCart, http.MethodDelete, "/cart/clear", "/cart/clear", 7, nil)
+		}()
+		go func() {
+			defer wg.Done()
+			added = serve(t, h.AddToCart, http.MethodPost, "/cart", "/cart", 7, map[string]int{"productId": 1, "quantity": 1}).Code
+		}()
+		wg.Wait()
+
+		// Очистка либо раньше добавления — остаётся новая строка, либо позже — корзина пуста
+		if added != http.StatusOK && added != http.StatusCreated {
+			t.Fatalf("add status = %d, want success", added)
+		}
+		if len(fake.carts) > 1 || len(fake.carts) == 1 && fake.carts[0].Quantity != 1 {
+			t.Fatalf("carts = %+v, want empty or one line with quantity 1", fake.carts)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// internal/gateway/handlers/cart_lock.go
+package handlers
+
+import "sync"
+
+// userLocks выдаёт мьютекс на пользователя; под ним выполняется любое
+// изменение корзины. Добавление сначала ищет строку, а потом создаёт её:
+// без блокировки два одновременных запроса не находят строку и создают
+// две одинаковые, а очистка удаляет строку, которую добавление уже нашло.
+//
+// Блокировка действует в пределах одного экземпляра шлюза, поэтому
+// AddToCart вдобавок сливает уже появившиеся дубли.
+type userLocks struct {
+	mu    sync.Mutex
+	locks map[int]*userLock
+}
+
+type userLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock захватывает мьютекс пользователя и возвращает функцию освобождения.
+// Мьютекс удаляется из карты, когда его больше никто не ждёт.
+func (l *userLocks) lock(userID int) (unlock func()) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[int]*userLock)
+	}
+	entry := l.locks[userID]
+	if entry == nil {
+		entry = &userLock{}
+		l.locks[userID] = entry
+	}
+	entry.refs++
+	l.mu.Unlock()
+
+	entry.mu.Lock()
+	return func() {
+		entry.mu.Unlock()
+
+		l.mu.Lock()
+		if entry.refs--; entry.refs == 0 {
+			delete(l.locks, userID)
+		}
+		l.mu.Unlock()
+	}
+}
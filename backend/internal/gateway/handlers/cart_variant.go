@@ -0,0 +1,77 @@
+// internal/gateway/handlers/cart_variant.go
+package handlers
+
+import (
+	"backend/internal/strapi"
+	"strings"
+)
+
+// cartVariant — выбранный размер и цвет товара в строке корзины или заказа
+type cartVariant struct {
+	Size  string `json:"size,omitempty"`
+	Color string `json:"color,omitempty"`
+}
+
+// variantError — размер или цвет не подходят товару
+type variantError struct {
+	Message string
+	Sizes   []string
+	Colors  []string
+}
+
+func (e *variantError) Error() string {
+	return e.Message
+}
+
+// resolveVariant проверяет выбранный размер и цвет по товару и возвращает их
+// в написании каталога. Размер обязателен, если у товара есть размеры; цвет —
+// если цветов несколько. Сочетание размера и цвета должно быть среди вариантов.
+func resolveVariant(product strapi.Product, size, color string) (cartVariant, error) {
+	sizes, colors := product.Sizes(), product.Colors()
+	fail := func(message string) (cartVariant, error) {
+		return cartVariant{}, &variantError{Message: message, Sizes: sizes, Colors: colors}
+	}
+
+	var variant cartVariant
+	size, color = strings.TrimSpace(size), strings.TrimSpace(color)
+
+	switch {
+	case len(sizes) == 0 && size != "":
+		return fail("у товара нет размеров")
+	case len(sizes) > 0 && size == "":
+		return fail("нужно выбрать размер")
+	case size != "":
+		if variant.Size = matchValue(sizes, size); variant.Size == "" {
+			return fail("размер " + size + " недоступен")
+		}
+	}
+
+	switch {
+	case len(colors) == 0 && color != "":
+		return fail("у товара нет цветов")
+	case len(colors) > 1 && color == "":
+		return fail("нужно выбрать цвет")
+	case color != "":
+		if variant.Color = matchValue(colors, color); variant.Color == "" {
+			return fail("цвет " + color + " недоступен")
+		}
+	}
+
+	// Размеры из поля size товара не привязаны к вариантам — проверяем
+	// сочетание, только когда выбранный размер есть среди вариантов
+	if variant.Color != "" && len(product.MatchVariants(variant.Size, "")) > 0 &&
+		len(product.MatchVariants(variant.Size, variant.Color)) == 0 {
+		return fail("нет размера " + variant.Size + " в цвете " + variant.Color)
+	}
+	return variant, nil
+}
+
+// matchValue находит значение в списке без учёта регистра и пробелов
+func matchValue(values []string, value string) string {
+	for _, v := range values {
+		if normalizeSizeValue(v) == normalizeSizeValue(value) {
+			return v
+		}
+	}
+	return ""
+}
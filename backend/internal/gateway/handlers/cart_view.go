@@ -0,0 +1,213 @@
+// internal/gateway/handlers/cart_view.go
+package handlers
+
+import (
+	"backend/internal/strapi"
+	"strings"
+)
+
+// Причины, по которым строку корзины нельзя оформить
+const (
+	unavailableUnpublished = "unpublished"
+	unavailableOutOfStock  = "out_of_stock"
+	unavailableShortStock  = "insufficient_stock"
+	// unavailableVariant — выбранного размера или цвета больше нет, либо он не выбран
+	unavailableVariant = "variant_unavailable"
+)
+
+// cartView — корзина с ценами из каталога и итогами, посчитанными шлюзом.
+// Все суммы — в минимальных единицах валюты (копейках для RUB).
+type cartView struct {
+	Items  []cartLineView `json:"items"`
+	Totals cartTotals     `json:"totals"`
+}
+
+type cartLineView struct {
+	ID        string          `json:"id"`
+	ProductID int             `json:"productId"`
+	Name      string          `json:"name"`
+	ImageURL  string          `json:"imageUrl,omitempty"`
+	Quantity  int             `json:"quantity"`
+	Size      string          `json:"size,omitempty"`
+	Color     string          `json:"color,omitempty"`
+	Product   *strapi.Product `json:"product,omitempty"`
+
+	// UnitPrice — текущая цена из каталога, ListPrice — цена до скидки
+	UnitPrice    int64 `json:"unitPrice"`
+	ListPrice    int64 `json:"listPrice"`
+	Subtotal     int64 `json:"subtotal"`
+	Discount     int64 `json:"discount"`
+	Total        int64 `json:"total"`
+	PriceChanged bool  `json:"priceChanged"`
+	// PreviousUnitPrice — цена на момент добавления, если она изменилась
+	PreviousUnitPrice *int64 `json:"previousUnitPrice,omitempty"`
+
+	Available         bool   `json:"available"`
+	UnavailableReason string `json:"unavailableReason,omitempty"`
+	// AvailableQuantity — остаток на складе, если он ведётся
+	AvailableQuantity *int `json:"availableQuantity,omitempty"`
+}
+
+// cartTotals считаются только по доступным строкам
+type cartTotals struct {
+	ItemCount int    `json:"itemCount"`
+	Subtotal  int64  `json:"subtotal"`
+	Discount  int64  `json:"discount"`
+	Shipping  int64  `json:"shipping"`
+	Total     int64  `json:"total"`
+	Currency  string `json:"currency"`
+	// MinorUnits — во сколько раз суммы больше цен в каталоге (100 для RUB)
+	MinorUnits int `json:"minorUnits"`
+	// HasUnavailable — в корзине есть строки, которые нельзя оформить
+	HasUnavailable bool `json:"hasUnavailable"`
+	PriceChanged   bool `json:"priceChanged"`
+}
+
+// cartLine — строка корзины пользователя или гостя в общем виде
+type cartLine struct {
+	ID        string
+	ProductID int
+	Quantity  int
+	Variant   cartVariant
+	// Price — цена на момент добавления; 0 — неизвестна
+	Price int
+}
+
+// userCartLines переводит строки корзины из Strapi в общий вид
+func userCartLines(items []strapi.CartItem) []cartLine {
+	lines := make([]cartLine, 0, len(items))
+	for _, item := range items {
+		line := cartLine{
+			ID:       item.DocumentID,
+			Quantity: item.Quantity,
+			Variant:  cartVariant{Size: item.Size, Color: item.Color},
+		}
+		if item.Product != nil {
+			line.ProductID = item.Product.ID
+		}
+		if item.Price != nil {
+			line.Price = *item.Price
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// buildCartView пересчитывает корзину по актуальным товарам каталога.
+// Товары, которых нет в products, считаются снятыми с продажи.
+func (h *CartHandler) buildCartView(lines []cartLine, products map[int]strapi.Product) cartView {
+	scale := int64(currencyMinorUnits(h.Config.Currency))
+	view := cartView{
+		Items: make([]cartLineView, 0, len(lines)),
+		Totals: cartTotals{
+			Currency:   h.Config.Currency,
+			MinorUnits: int(scale),
+		},
+	}
+
+	for _, line := range lines {
+		item := cartLineView{
+			ID:        line.ID,
+			ProductID: line.ProductID,
+			Quantity:  line.Quantity,
+			Size:      line.Variant.Size,
+			Color:     line.Variant.Color,
+		}
+
+		product, ok := products[line.ProductID]
+		if !ok {
+			item.UnavailableReason = unavailableUnpublished
+			view.Items = append(view.Items, item)
+			view.Totals.HasUnavailable = true
+			continue
+		}
+
+		item.Name = product.Name
+		item.ImageURL = product.ImageURL
+		item.Product = &product
+
+		variant, variantErr := resolveVariant(product, line.Variant.Size, line.Variant.Color)
+		if variantErr != nil {
+			variant = line.Variant
+		}
+
+		price := product.VariantPrice(variant.Size, variant.Color)
+		listPrice := product.ListPrice(variant.Size, variant.Color)
+		item.UnitPrice = int64(price) * scale
+		item.ListPrice = int64(listPrice) * scale
+		item.Subtotal = item.ListPrice * int64(line.Quantity)
+		item.Discount = (item.ListPrice - item.UnitPrice) * int64(line.Quantity)
+		item.Total = item.Subtotal - item.Discount
+
+		if line.Price > 0 && line.Price != price {
+			previous := int64(line.Price) * scale
+			item.PriceChanged = true
+			item.PreviousUnitPrice = &previous
+			view.Totals.PriceChanged = true
+		}
+
+		item.Available = true
+		if variantErr != nil {
+			item.Available, item.UnavailableReason = false, unavailableVariant
+		} else if stock, tracked := product.VariantStock(variant.Size, variant.Color); tracked {
+			item.AvailableQuantity = &stock
+			switch {
+			case stock <= 0:
+				item.Available, item.UnavailableReason = false, unavailableOutOfStock
+			case stock < line.Quantity:
+				item.Available, item.UnavailableReason = false, unavailableShortStock
+			}
+		}
+
+		if item.Available {
+			view.Totals.ItemCount += line.Quantity
+			view.Totals.Subtotal += item.Subtotal
+			view.Totals.Discount += item.Discount
+		} else {
+			view.Totals.HasUnavailable = true
+		}
+		view.Items = append(view.Items, item)
+	}
+
+	goods := view.Totals.Subtotal - view.Totals.Discount
+	view.Totals.Shipping = h.shippingCost(goods, scale)
+	view.Totals.Total = goods + view.Totals.Shipping
+	return view
+}
+
+// shippingCost — оценка доставки: фиксированная ставка,
+// бесплатно для пустой корзины и от порога FreeShippingFrom
+func (h *CartHandler) shippingCost(goods, scale int64) int64 {
+	if goods <= 0 {
+		return 0
+	}
+	if from := h.Config.FreeShippingFrom; from > 0 && goods >= int64(from)*scale {
+		return 0
+	}
+	return int64(h.Config.ShippingCost) * scale
+}
+
+// currencyMinorUnits возвращает число минимальных единиц в единице валюты (ISO 4217)
+func currencyMinorUnits(currency string) int {
+	switch strings.ToUpper(currency) {
+	case "JPY", "KRW", "VND", "CLP", "ISK":
+		return 1
+	case "KWD", "BHD", "OMR", "JOD", "TND":
+		return 1000
+	default:
+		return 100
+	}
+}
+
+// productIDs возвращает товары строк корзины без повторов
+func productIDs(lines []cartLine) []int {
+	seen := map[int]bool{}
+	ids := make([]int, 0, len(lines))
+	for _, line := range lines {
+		if line.ProductID > 0 && !seen[line.ProductID] {
+			seen[line.ProductID] = true
+			ids = append(ids, line.ProductID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,227 @@
+// internal/gateway/handlers/catalog_facets.go
+package handlers
+
+import (
+	"backend/internal/strapi"
+	"context"
+	"math"
+	"sort"
+	"strings"
+)
+
+// defaultPriceBuckets — число интервалов гистограммы цен, если не задано в конфиге
+const defaultPriceBuckets = 5
+
+// catalogFacets — агрегаты по товарам, подходящим под текущие фильтры;
+// каждый фасет считается без учёта собственного фильтра
+type catalogFacets struct {
+	Brands     []facetCount `json:"brands"`
+	Categories []facetCount `json:"categories"`
+	Sizes      []facetCount `json:"sizes"`
+	Price      *priceFacet  `json:"price,omitempty"`
+}
+
+// facetCount — значение фильтра и число товаров с ним; Value можно
+// передать обратно в соответствующий параметр листинга
+type facetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type priceFacet struct {
+	Min     int           `json:"min"`
+	Max     int           `json:"max"`
+	Buckets []priceBucket `json:"buckets"`
+}
+
+// priceBucket — интервал цен [From, To) и число товаров в нём
+type priceBucket struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Count int `json:"count"`
+}
+
+// loadFacets считает агрегаты по снимку всего каталога, который кешируется
+// вместе с деревом категорий. Фильтры применяются в памяти, поэтому любая
+// их комбинация не стоит ни одного запроса к Strapi.
+func (h *CatalogHandler) loadFacets(ctx context.Context, query ProductQuery) (*catalogFacets, error) {
+	tax, err := h.loadTaxonomy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildFacets(tax.products, query, h.Config.PriceBuckets), nil
+}
+
+// buildFacets считает каждый фасет по товарам, подходящим под все фильтры,
+// кроме его собственного: при выбранном размере M фасет размеров
+// по-прежнему показывает остальные размеры с их количеством
+func buildFacets(products []strapi.Product, query ProductQuery, buckets int) *catalogFacets {
+	brands := newFacetCounter()
+	categories := newFacetCounter()
+	sizes := newFacetCounter()
+	prices := make([]int, 0, len(products))
+
+	for _, product := range products {
+		productSizes := facetSizes(product)
+		byCategory := matchesTerm(product.Category, query.Categories)
+		byBrand := matchesTerm(product.Brand, query.Brands)
+		bySize := matchesSize(productSizes, query.Sizes)
+		byPrice := matchesPrice(product.Price, query.MinPrice, query.MaxPrice)
+
+		if byCategory && bySize && byPrice {
+			brands.add(product.Brand.Name)
+		}
+		if byBrand && bySize && byPrice {
+			categories.add(product.Category.Name)
+		}
+		if byCategory && byBrand && byPrice {
+			for _, size := range productSizes {
+				sizes.add(size)
+			}
+		}
+		if byCategory && byBrand && bySize {
+			prices = append(prices, product.Price)
+		}
+	}
+
+	return &catalogFacets{
+		Brands:     brands.sorted(),
+		Categories: categories.sorted(),
+		Sizes:      sizes.sorted(),
+		Price:      priceHistogram(prices, buckets),
+	}
+}
+
+// facetSizes возвращает размеры товара в нормализованном написании без повторов
+func facetSizes(product strapi.Product) []string {
+	list, _ := normalizeSizes(product.Size).([]string)
+	var sizes []string
+	seen := map[string]bool{}
+	for _, size := range list {
+		size = normalizeSizeValue(size)
+		if size != "" && !seen[size] {
+			seen[size] = true
+			sizes = append(sizes, size)
+		}
+	}
+	return sizes
+}
+
+// matchesTerm повторяет фильтр листинга (ProductQuery.filterValues): значение
+// совпадает с названием или slug термина без учёта регистра; пустой фильтр
+// пропускает всё. У строкового поля slug пуст, поэтому сравнивается только название.
+func matchesTerm(term strapi.Term, values []string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, value := range values {
+		if strings.EqualFold(term.Name, value) || (term.Slug != "" && strings.EqualFold(term.Slug, value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesSize(sizes, values []string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, value := range values {
+		value = normalizeSizeValue(value)
+		for _, size := range sizes {
+			if size == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesPrice(price int, minPrice, maxPrice *int) bool {
+	return (minPrice == nil || price >= *minPrice) && (maxPrice == nil || price <= *maxPrice)
+}
+
+// normalizeSizeValue приводит размер к одному написанию: " xl" и "XL" — один размер
+func normalizeSizeValue(size string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(size), " "))
+}
+
+// facetCounter считает значения без учёта регистра,
+// сохраняя написание первого встреченного
+type facetCounter struct {
+	counts map[string]*facetCount
+}
+
+func newFacetCounter() *facetCounter {
+	return &facetCounter{counts: map[string]*facetCount{}}
+}
+
+func (f *facetCounter) add(value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	key := strings.ToLower(value)
+	if f.counts[key] == nil {
+		f.counts[key] = &facetCount{Value: value}
+	}
+	f.counts[key].Count++
+}
+
+// sorted возвращает значения по убыванию числа товаров, при равенстве — по алфавиту
+func (f *facetCounter) sorted() []facetCount {
+	list := make([]facetCount, 0, len(f.counts))
+	for _, count := range f.counts {
+		list = append(list, *count)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return strings.ToLower(list[i].Value) < strings.ToLower(list[j].Value)
+	})
+	return list
+}
+
+// priceHistogram делит диапазон цен на интервалы «круглой» ширины
+// (1, 2 или 5 × 10ⁿ), чтобы границы хорошо смотрелись в фильтре
+func priceHistogram(prices []int, buckets int) *priceFacet {
+	if len(prices) == 0 {
+		return nil
+	}
+	if buckets <= 0 {
+		buckets = defaultPriceBuckets
+	}
+
+	facet := &priceFacet{Min: prices[0], Max: prices[0]}
+	for _, price := range prices {
+		facet.Min = min(facet.Min, price)
+		facet.Max = max(facet.Max, price)
+	}
+
+	step := niceStep(float64(facet.Max-facet.Min) / float64(buckets))
+	start := facet.Min / step * step
+	count := (facet.Max-start)/step + 1
+
+	facet.Buckets = make([]priceBucket, count)
+	for i := range facet.Buckets {
+		facet.Buckets[i] = priceBucket{From: start + i*step, To: start + (i+1)*step}
+	}
+	for _, price := range prices {
+		facet.Buckets[(price-start)/step].Count++
+	}
+	return facet
+}
+
+func niceStep(raw float64) int {
+	if raw < 1 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if step := m * magnitude; step >= raw {
+			return int(step)
+		}
+	}
+	return int(10 * magnitude)
+}
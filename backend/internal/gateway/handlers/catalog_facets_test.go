@@ -0,0 +1,143 @@
+// internal/gateway/handlers/catalog_facets_test.go
+package handlers
+
+import (
+	"backend/internal/strapi"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFacetSizesAreNormalised(t *testing.T) {
+	facets := buildFacets([]strapi.Product{
+		{ID: 1, Price: 100, Size: []interface{}{" xl", "XL", "m"}},
+		{ID: 2, Price: 200, Size: "Xl"},
+		{ID: 3, Price: 300, Size: []interface{}{"one  size"}},
+		{ID: 4, Price: 400},
+	}, ProductQuery{}, 0)
+
+	want := []facetCount{{Value: "XL", Count: 2}, {Value: "M", Count: 1}, {Value: "ONE SIZE", Count: 1}}
+	if len(facets.Sizes) != len(want) {
+		t.Fatalf("sizes = %+v, want %+v", facets.Sizes, want)
+	}
+	for i := range want {
+		if facets.Sizes[i] != want[i] {
+			t.Errorf("sizes[%d] = %+v, want %+v", i, facets.Sizes[i], want[i])
+		}
+	}
+
+	// Фильтр размера сравнивается в том же написании
+	facets = buildFacets([]strapi.Product{
+		{ID: 1, Price: 100, Brand: strapi.Term{Name: "Nord"}, Size: []interface{}{"xl"}},
+		{ID: 2, Price: 200, Brand: strapi.Term{Name: "Sud"}, Size: "M"},
+	}, ProductQuery{Sizes: []string{" Xl "}}, 0)
+	if len(facets.Brands) != 1 || facets.Brands[0] != (facetCount{Value: "Nord", Count: 1}) {
+		t.Errorf("brands for size XL = %+v", facets.Brands)
+	}
+}
+
+func TestFacetCountsIgnoreCase(t *testing.T) {
+	facets := buildFacets([]strapi.Product{
+		{ID: 1, Brand: strapi.Term{Name: "Nord"}, Category: strapi.Term{Name: "Куртки", Slug: "kurtki"}},
+		{ID: 2, Brand: strapi.Term{Name: "NORD"}, Category: strapi.Term{Name: "куртки", Slug: "kurtki"}},
+		{ID: 3, Brand: strapi.Term{Name: "Alpha"}, Category: strapi.Term{Name: "Шапки", Slug: "shapki"}},
+		{ID: 4, Brand: strapi.Term{Name: "Beta"}},
+	}, ProductQuery{Categories: []string{"KURTKI", "shapki"}}, 0)
+
+	// Первое встреченное написание, по убыванию числа, при равенстве — по алфавиту
+	want := []facetCount{{Value: "Nord", Count: 2}, {Value: "Alpha", Count: 1}}
+	if len(facets.Brands) != len(want) || facets.Brands[0] != want[0] || facets.Brands[1] != want[1] {
+		t.Errorf("brands = %+v, want %+v", facets.Brands, want)
+	}
+	if len(facets.Categories) != 2 || facets.Categories[0] != (facetCount{Value: "Куртки", Count: 2}) {
+		t.Errorf("categories = %+v", facets.Categories)
+	}
+}
+
+func TestPriceHistogram(t *testing.T) {
+	if priceHistogram(nil, 5) != nil {
+		t.Error("histogram of no prices")
+	}
+
+	facet := priceHistogram([]int{990, 1500, 2490, 4990, 5000}, 4)
+	if facet.Min != 990 || facet.Max != 5000 {
+		t.Fatalf("range = %d..%d", facet.Min, facet.Max)
+	}
+	// (5000-990)/4 ≈ 1002 → шаг 2000, интервалы от 0
+	want := []priceBucket{{0, 2000, 2}, {2000, 4000, 1}, {4000, 6000, 2}}
+	if len(facet.Buckets) != len(want) {
+		t.Fatalf("buckets = %+v, want %+v", facet.Buckets, want)
+	}
+	total := 0
+	for i := range want {
+		if facet.Buckets[i] != want[i] {
+			t.Errorf("bucket[%d] = %+v, want %+v", i, facet.Buckets[i], want[i])
+		}
+		total += facet.Buckets[i].Count
+	}
+	if total != 5 {
+		t.Errorf("bucket counts sum to %d, want 5", total)
+	}
+
+	// Одна цена — один интервал шириной 1
+	facet = priceHistogram([]int{700, 700}, 0)
+	if len(facet.Buckets) != 1 || facet.Buckets[0] != (priceBucket{700, 701, 2}) {
+		t.Errorf("single price buckets = %+v", facet.Buckets)
+	}
+
+	for raw, want := range map[float64]int{0.4: 1, 1: 1, 1.5: 2, 3: 5, 7: 10, 120: 200, 1002.5: 2000, 5000: 5000} {
+		if got := niceStep(raw); got != want {
+			t.Errorf("niceStep(%v) = %d, want %d", raw, got, want)
+		}
+	}
+}
+
+func TestListingFacetsDoNotLeakIntoCache(t *testing.T) {
+	fake := newFakeStrapi()
+	fake.products[1] = strapi.Product{ID: 1, Price: 1000, Brand: strapi.Term{Name: "Nord"}, Size: "M"}
+	fake.products[2] = strapi.Product{ID: 2, Price: 3000, Brand: strapi.Term{Name: "Sud"}, Size: "L"}
+	h := NewCatalogHandler(fake, CatalogConfig{CacheSize: 10, CacheTTL: time.Hour, TaxonomyTTL: time.Hour, PriceBuckets: 2})
+
+	router := gin.New()
+	router.GET("/products", h.GetProducts)
+	list := func(target string) productListResponse {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d", target, rec.Code)
+		}
+		var resp productListResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	withFacets := list("/products?facets=true")
+	facets := withFacets.Meta.Facets
+	if facets == nil || len(facets.Brands) != 2 || len(facets.Sizes) != 2 || facets.Price == nil || facets.Price.Max != 3000 {
+		t.Fatalf("facets = %+v", facets)
+	}
+	if len(withFacets.Data) != 2 {
+		t.Fatalf("data = %d products", len(withFacets.Data))
+	}
+
+	// Та же страница без facets берётся из кеша и агрегатов не содержит
+	if plain := list("/products"); plain.Meta.Facets != nil {
+		t.Errorf("cached listing carries facets: %+v", plain.Meta.Facets)
+	}
+	if n := fake.count("GetProducts"); n != 1 {
+		t.Errorf("GetProducts called %d times, want 1", n)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products?facets=maybe", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("facets=maybe: status = %d, want 400", rec.Code)
+	}
+}
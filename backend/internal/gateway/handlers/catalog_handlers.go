@@ -2,109 +2,241 @@
 package handlers
 
 import (
-	"backend/internal/config"
+	"backend/internal/cache"
+	"backend/internal/media"
+	"backend/internal/strapi"
 	"backend/pkg/logger"
-	"encoding/json"
-	"fmt"
-	"io"
-	"io/ioutil"
+	"context"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type CatalogHandler struct {
-	StrapiURL string
-}
+	Strapi StrapiAPI
+	Config CatalogConfig
 
-type Product struct {
-	ID          int         `json:"id"`
-	DocumentID  string      `json:"documentId"`
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	Price       int         `json:"price"`
-	Category    string      `json:"category"`
-	Brand       string      `json:"brand"`
-	Size        interface{} `json:"size"`
-	CreatedAt   string      `json:"createdAt"`
-	UpdatedAt   string      `json:"updatedAt"`
-	PublishedAt string      `json:"publishedAt"`
-	ImageURL    string      `json:"imageUrl"` // Добавляем поле для URL изображения
-	Images      []Image     `json:"image"`    // Полный массив изображений
-}
+	// relations — какие из полей category и brand являются связями
+	relations termRelations
 
-type Image struct {
-	ID      int     `json:"id"`
-	Name    string  `json:"name"`
-	URL     string  `json:"url"`
-	Formats Formats `json:"formats"`
+	listings *cache.Cache[*productListResponse]
+	products *cache.Cache[*strapi.Product]
+	taxonomy *cache.Cache[*taxonomy]
 }
 
-type Formats struct {
-	Thumbnail Thumbnail `json:"thumbnail"`
-}
+// CatalogConfig — настройки каталога, не связанные с транспортом
+type CatalogConfig struct {
+	// TaxonomyTTL — как долго держать агрегаты категорий и брендов
+	TaxonomyTTL time.Duration
+	// CategorySeparator разделяет уровни в строковой категории
+	CategorySeparator string
+	// TaxonomyPopulate — связи для агрегатов, если категория и бренд — отдельные коллекции;
+	// перечисленные здесь поля фильтруются по slug и названию связи
+	TaxonomyPopulate []string
 
-type Thumbnail struct {
-	URL string `json:"url"`
+	// CacheTTL — сколько ответ Strapi считается свежим, CacheStale — сколько
+	// после этого его ещё можно отдавать, обновляя в фоне
+	CacheTTL   time.Duration
+	CacheStale time.Duration
+	// CacheSize — максимум листингов и карточек в кеше
+	CacheSize int
+
+	// PriceBuckets — число интервалов гистограммы цен в фасетах
+	PriceBuckets int
+
+	// Media переписывает ссылки на изображения на прокси /media; nil — не переписывать
+	Media *media.Rewriter
 }
 
-func NewCatalogHandler(cfg *config.Config) *CatalogHandler {
+func NewCatalogHandler(client StrapiAPI, cfg CatalogConfig) *CatalogHandler {
 	return &CatalogHandler{
-		StrapiURL: cfg.StrapiURL,
+		Strapi: client,
+		Config: cfg,
+
+		relations: newTermRelations(cfg.TaxonomyPopulate),
+
+		listings: cache.New[*productListResponse](cfg.CacheSize, cfg.CacheTTL, cfg.CacheStale),
+		products: cache.New[*strapi.Product](cfg.CacheSize, cfg.CacheTTL, cfg.CacheStale),
+		taxonomy: cache.New[*taxonomy](1, cfg.TaxonomyTTL, cfg.CacheStale),
 	}
 }
 
-// Получение списка товаров
+// productListResponse — страница каталога с итогами для построения листинга
+type productListResponse struct {
+	Data []strapi.Product `json:"data"`
+	Meta catalogMeta      `json:"meta"`
+}
+
+type catalogMeta struct {
+	Pagination *strapi.Pagination `json:"pagination"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+	Facets     *catalogFacets     `json:"facets,omitempty"`
+}
+
+// GetProducts godoc
+// @Summary Получить список товаров
+// @Description Страница каталога с фильтрами по категории, бренду, размеру и цене
+// @Tags Catalog
+// @Produce json
+// @Param page query int false "Номер страницы"
+// @Param pageSize query int false "Размер страницы (до 100)"
+// @Param cursor query string false "Курсор следующей порции вместо page"
+// @Param category query string false "Категории через запятую"
+// @Param brand query string false "Бренды через запятую"
+// @Param size query string false "Размеры через запятую"
+// @Param minPrice query int false "Минимальная цена"
+// @Param maxPrice query int false "Максимальная цена"
+// @Param sort query string false "newest, oldest, price_asc, price_desc, name_asc, name_desc"
+// @Param facets query bool false "Добавить в meta агрегаты по брендам, категориям, размерам и ценам"
+// @Success 200 {object} productListResponse
+// @Success 304 "Не изменилось с версии клиента"
+// @Failure 400 {object} gin.H{"error": "Неверные параметры запроса"}
+// @Failure 500 {object} gin.H{"error": "Ошибка сервера"}
+// @Router /api/catalog/products [get]
 func (h *CatalogHandler) GetProducts(c *gin.Context) {
-	resp, err := http.Get(fmt.Sprintf("%s/api/products?populate=image", h.StrapiURL))
+	query, err := parseProductQuery(c)
 	if err != nil {
-		logger.ErrorLogger.Println("Ошибка запроса к Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры запроса", "details": err.Error()})
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		logger.ErrorLogger.Printf("Strapi ответил с ошибкой %d: %s", resp.StatusCode, string(body))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
-		return
-	}
+	values := query.strapiValues(h.relations)
+	resp, err := h.listings.GetOrLoad(c.Request.Context(), values.Encode(), func(ctx context.Context) (*productListResponse, error) {
+		list, err := h.Strapi.GetProducts(ctx, values)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range list.Data {
+			normalizeProduct(&list.Data[i])
+			h.Config.Media.Product(&list.Data[i])
+		}
 
-	body, err := io.ReadAll(resp.Body)
+		pagination := list.Meta.Pagination
+		if pagination == nil {
+			pagination = &strapi.Pagination{Total: len(list.Data)}
+		}
+
+		return &productListResponse{
+			Data: list.Data,
+			Meta: catalogMeta{
+				Pagination: pagination,
+				NextCursor: query.nextCursor(pagination.Total),
+			},
+		}, nil
+	})
 	if err != nil {
-		logger.ErrorLogger.Println("Ошибка чтения ответа от Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+		strapiFailed(c, err)
 		return
 	}
 
-	var rawResponse struct {
-		Data []Product `json:"data"`
+	if query.Facets {
+		facets, err := h.loadFacets(c.Request.Context(), query)
+		if err != nil {
+			strapiFailed(c, err)
+			return
+		}
+		// Ответ из кеша общий для всех запросов, фасеты добавляются в копию
+		withFacets := *resp
+		withFacets.Meta.Facets = facets
+		resp = &withFacets
 	}
-	if err := json.Unmarshal(body, &rawResponse); err != nil {
-		logger.ErrorLogger.Println("Ошибка парсинга ответа Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+
+	// Last-Modified у листинга не ставим: удаление или снятие товара с публикации
+	// не сдвигает updatedAt оставшихся, и If-Modified-Since дал бы ложный 304.
+	// Версию листинга определяет ETag по телу ответа.
+	writeConditionalJSON(c, resp, time.Time{})
+}
+
+// GetProduct godoc
+// @Summary Получить карточку товара
+// @Description Товар по числовому ID или documentId со всеми изображениями, связанными товарами и вариантами размеров
+// @Tags Catalog
+// @Produce json
+// @Param id path string true "ID или documentId товара"
+// @Success 200 {object} interface{}
+// @Success 304 "Не изменилось с версии клиента"
+// @Failure 404 {object} gin.H{"error": "Товар не найден"}
+// @Failure 500 {object} gin.H{"error": "Ошибка сервера"}
+// @Router /api/catalog/products/{id} [get]
+func (h *CatalogHandler) GetProduct(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" || len(id) > 64 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Товар не найден"})
 		return
 	}
 
-	for i := range rawResponse.Data {
-		product := &rawResponse.Data[i]
-		if len(product.Images) > 0 {
-			product.ImageURL = product.Images[0].URL // URL первого изображения
+	product, err := h.products.GetOrLoad(c.Request.Context(), id, func(ctx context.Context) (*strapi.Product, error) {
+		product, err := h.Strapi.GetProduct(ctx, id)
+		if err != nil {
+			return nil, err
 		}
-		switch v := product.Size.(type) {
-		case string:
-			product.Size = []string{v} // Одиночный размер
-		case []interface{}:
-			var sizes []string
-			for _, size := range v {
-				if sizeStr, ok := size.(string); ok {
-					sizes = append(sizes, sizeStr)
-				}
-			}
-			product.Size = sizes
+
+		normalizeProduct(product)
+		for i := range product.Related {
+			normalizeProduct(&product.Related[i])
+		}
+		h.Config.Media.Product(product)
+		return product, nil
+	})
+	if err != nil {
+		if strapi.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Товар не найден"})
+			return
 		}
+		strapiFailed(c, err)
+		return
+	}
+
+	lastModified := productsLastModified(append([]strapi.Product{*product}, product.Related...)...)
+	writeConditionalJSON(c, gin.H{"data": product}, lastModified)
+}
+
+// taxonomyModels — коллекции Strapi, из которых берутся категории и бренды,
+// если в схеме они связи
+var taxonomyModels = map[string]bool{"category": true, "brand": true}
+
+// HandleStrapiEvent сбрасывает кеш каталога по событию вебхука Strapi.
+// Карточки сбрасываются целиком: изменённый товар встроен в Related других
+// карточек, а название категории или бренда — в каждую карточку с ними.
+func (h *CatalogHandler) HandleStrapiEvent(_ context.Context, event StrapiEvent) {
+	switch {
+	case event.Model == "product", taxonomyModels[event.Model]:
+	case strings.HasPrefix(event.Event, "media."):
+		// Изменение файла затрагивает любые карточки с этим изображением
+	default:
+		return
+	}
+
+	h.products.Purge()
+	h.listings.Purge()
+	h.taxonomy.Purge()
+	logger.InfoLogger.Printf("Кеш каталога сброшен: %s %s %d", event.Event, event.Model, event.Entry.ID)
+}
+
+// normalizeProduct заполняет ImageURL и приводит Size к []string
+func normalizeProduct(product *strapi.Product) {
+	if len(product.Images) > 0 {
+		product.ImageURL = product.Images[0].URL // URL первого изображения
 	}
+	product.Size = normalizeSizes(product.Size)
+}
 
-	c.JSON(http.StatusOK, rawResponse)
+// normalizeSizes приводит размер из Strapi (строка или массив) к []string
+func normalizeSizes(size interface{}) interface{} {
+	switch v := size.(type) {
+	case string:
+		return []string{v} // Одиночный размер
+	case []interface{}:
+		var sizes []string
+		for _, size := range v {
+			if sizeStr, ok := size.(string); ok {
+				sizes = append(sizes, sizeStr)
+			}
+		}
+		return sizes
+	}
+	return size
 }
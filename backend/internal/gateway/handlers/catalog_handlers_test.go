@@ -0,0 +1,253 @@
+// internal/gateway/handlers/catalog_handlers_test.go
+package handlers
+
+import (
+	"backend/internal/strapi"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func catalogRequest(router *gin.Engine, header, value string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/products", nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestProductListingUsesETagOnly(t *testing.T) {
+	fake := newFakeStrapi()
+	fake.products[1] = strapi.Product{ID: 1, Name: "Куртка", UpdatedAt: "2026-01-01T00:00:00Z"}
+	fake.products[2] = strapi.Product{ID: 2, Name: "Шапка", UpdatedAt: "2026-01-02T00:00:00Z"}
+	h := NewCatalogHandler(fake, CatalogConfig{CacheSize: 10})
+
+	router := gin.New()
+	router.GET("/products", h.GetProducts)
+
+	rec := catalogRequest(router, "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if lm := rec.Header().Get("Last-Modified"); lm != "" {
+		t.Fatalf("listing has Last-Modified %q", lm)
+	}
+	etag := rec.Header().Get("ETag")
+
+	if rec := catalogRequest(router, "If-None-Match", etag); rec.Code != http.StatusNotModified {
+		t.Fatalf("If-None-Match: status = %d, want 304", rec.Code)
+	}
+
+	// Снятие товара с публикации не сдвигает updatedAt оставшихся,
+	// поэтому If-Modified-Since не должен давать 304
+	delete(fake.products, 2)
+	h.listings.Purge()
+	since := time.Now().UTC().Format(http.TimeFormat)
+	if rec := catalogRequest(router, "If-Modified-Since", since); rec.Code != http.StatusOK {
+		t.Fatalf("If-Modified-Since: status = %d, want 200", rec.Code)
+	}
+	if rec := catalogRequest(router, "If-None-Match", etag); rec.Code != http.StatusOK {
+		t.Fatalf("stale ETag: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestFacetsExcludeOwnFilterAndShareSnapshot(t *testing.T) {
+	fake := newFakeStrapi()
+	fake.products[1] = strapi.Product{ID: 1, Price: 1000, Brand: strapi.Term{Name: "Nord"}, Category: strapi.Term{Name: "Куртки"}, Size: []interface{}{"M", "L"}}
+	fake.products[2] = strapi.Product{ID: 2, Price: 2000, Brand: strapi.Term{Name: "Nord"}, Category: strapi.Term{Name: "Шапки"}, Size: []interface{}{"S"}}
+	fake.products[3] = strapi.Product{ID: 3, Price: 3000, Brand: strapi.Term{Name: "Sud"}, Category: strapi.Term{Name: "Куртки"}, Size: "m"}
+	h := NewCatalogHandler(fake, CatalogConfig{CacheSize: 10, TaxonomyTTL: time.Minute})
+
+	router := gin.New()
+	router.GET("/products", func(c *gin.Context) {
+		query, err := parseProductQuery(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		facets, err := h.loadFacets(c.Request.Context(), query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.JSON(http.StatusOK, facets)
+	})
+	facetsFor := func(target string) catalogFacets {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		var facets catalogFacets
+		if err := json.Unmarshal(rec.Body.Bytes(), &facets); err != nil {
+			t.Fatal(err)
+		}
+		return facets
+	}
+	counts := func(list []facetCount) map[string]int {
+		m := map[string]int{}
+		for _, f := range list {
+			m[f.Value] = f.Count
+		}
+		return m
+	}
+
+	facets := facetsFor("/products?size=M")
+	if got := counts(facets.Sizes); got["M"] != 2 || got["L"] != 1 || got["S"] != 1 {
+		t.Fatalf("sizes = %v, want M:2 L:1 S:1", got)
+	}
+	if got := counts(facets.Brands); got["Nord"] != 1 || got["Sud"] != 1 {
+		t.Fatalf("brands = %v, want Nord:1 Sud:1", got)
+	}
+
+	facets = facetsFor("/products?brand=Nord&maxPrice=1500")
+	if got := counts(facets.Brands); got["Nord"] != 1 || got["Sud"] != 0 {
+		t.Fatalf("brands = %v, want Nord:1", got)
+	}
+	if got := counts(facets.Categories); got["Куртки"] != 1 || got["Шапки"] != 0 {
+		t.Fatalf("categories = %v, want Куртки:1", got)
+	}
+	if facets.Price == nil || facets.Price.Min != 1000 || facets.Price.Max != 2000 {
+		t.Fatalf("price = %+v, want range of Nord without price filter", facets.Price)
+	}
+
+	facetsFor("/products?category=Шапки&size=S")
+	if n := fake.count("ListAllProducts"); n != 1 {
+		t.Fatalf("ListAllProducts called %d times, want 1", n)
+	}
+}
+
+func TestTermFiltersFollowSchema(t *testing.T) {
+	query := ProductQuery{Categories: []string{"kurtki", "Шапки"}, Brands: []string{"NORD"}, Sizes: []string{"M"}}
+
+	plain := query.filterValues(newTermRelations(nil))
+	want := map[string]string{
+		"filters[$and][0][$or][0][category][$eqi]":   "kurtki",
+		"filters[$and][0][$or][1][category][$eqi]":   "Шапки",
+		"filters[$and][1][$or][0][brand][$eqi]":      "NORD",
+		"filters[$and][2][$or][0][size][$eqi]":       "M",
+		"filters[$and][2][$or][1][size][$containsi]": `"M"`,
+	}
+	assertValues(t, "string fields", plain, want)
+
+	relation := query.filterValues(newTermRelations([]string{"category.parent", "brand"}))
+	want = map[string]string{
+		"filters[$and][0][$or][0][category][slug][$eqi]": "kurtki",
+		"filters[$and][0][$or][1][category][name][$eqi]": "kurtki",
+		"filters[$and][0][$or][2][category][slug][$eqi]": "Шапки",
+		"filters[$and][0][$or][3][category][name][$eqi]": "Шапки",
+		"filters[$and][1][$or][0][brand][slug][$eqi]":    "NORD",
+		"filters[$and][1][$or][1][brand][name][$eqi]":    "NORD",
+		"filters[$and][2][$or][0][size][$eqi]":           "M",
+		"filters[$and][2][$or][1][size][$containsi]":     `"M"`,
+	}
+	assertValues(t, "relations", relation, want)
+
+	// Фасеты сравнивают по тому же правилу: название или slug без учёта регистра
+	cases := []struct {
+		term  strapi.Term
+		value string
+		want  bool
+	}{
+		{strapi.Term{Name: "Куртки", Slug: "kurtki"}, "KURTKI", true},
+		{strapi.Term{Name: "Куртки", Slug: "kurtki"}, "куртки", true},
+		{strapi.Term{Name: "Куртки"}, "куртки", true},
+		{strapi.Term{Name: "Куртки"}, "kurtki", false},
+		{strapi.Term{Name: "Куртки", Slug: "kurtki"}, "shapki", false},
+	}
+	for _, tc := range cases {
+		if got := matchesTerm(tc.term, []string{tc.value}); got != tc.want {
+			t.Errorf("matchesTerm(%+v, %q) = %v, want %v", tc.term, tc.value, got, tc.want)
+		}
+	}
+}
+
+func assertValues(t *testing.T, name string, got url.Values, want map[string]string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s: got %d filters, want %d: %v", name, len(got), len(want), got)
+	}
+	for key, value := range want {
+		if got.Get(key) != value {
+			t.Errorf("%s: %s = %q, want %q", name, key, got.Get(key), value)
+		}
+	}
+}
+
+func TestStrapiEventsInvalidateDependentEntries(t *testing.T) {
+	fake := newFakeStrapi()
+	fake.products[1] = strapi.Product{ID: 1, Name: "Куртка", Category: strapi.Term{Name: "Куртки"}, Related: []strapi.Product{{ID: 2}}}
+	fake.products[2] = strapi.Product{ID: 2, Name: "Шапка", Category: strapi.Term{Name: "Шапки"}}
+	h := NewCatalogHandler(fake, CatalogConfig{CacheSize: 10, CacheTTL: time.Hour, TaxonomyTTL: time.Hour, CategorySeparator: "/"})
+
+	router := gin.New()
+	router.GET("/products/:id", h.GetProduct)
+	router.GET("/categories", h.GetCategories)
+	get := func(target string, out any) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d", target, rec.Code)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	relatedName := func() string {
+		var card struct{ Data strapi.Product }
+		get("/products/1", &card)
+		if len(card.Data.Related) != 1 {
+			t.Fatalf("related = %+v", card.Data.Related)
+		}
+		return card.Data.Related[0].Name
+	}
+	categoryNames := func() []string {
+		var tree struct{ Data []categoryNode }
+		get("/categories", &tree)
+		var names []string
+		for _, node := range tree.Data {
+			names = append(names, node.Name)
+		}
+		return names
+	}
+
+	if name := relatedName(); name != "Шапка" {
+		t.Fatalf("related name = %q", name)
+	}
+	categoryNames()
+
+	// Событие о другой модели кеш не трогает
+	h.HandleStrapiEvent(context.Background(), strapiEvent("entry.update", "order", 5))
+	if n := fake.count("GetProduct"); n != 1 {
+		t.Fatalf("GetProduct called %d times after unrelated event", n)
+	}
+
+	// Изменение товара 2 обновляет карточку товара 1, где он в Related
+	fake.mu.Lock()
+	fake.products[2] = strapi.Product{ID: 2, Name: "Шапка зимняя", Category: strapi.Term{Name: "Шапки"}}
+	fake.mu.Unlock()
+	h.HandleStrapiEvent(context.Background(), strapiEvent("entry.update", "product", 2))
+	if name := relatedName(); name != "Шапка зимняя" {
+		t.Errorf("related name after product event = %q", name)
+	}
+
+	// Переименование категории сбрасывает дерево категорий
+	fake.mu.Lock()
+	fake.products[2] = strapi.Product{ID: 2, Name: "Шапка зимняя", Category: strapi.Term{Name: "Головные уборы"}}
+	fake.mu.Unlock()
+	categoryNames()
+	h.HandleStrapiEvent(context.Background(), strapiEvent("entry.update", "category", 9))
+	if names := categoryNames(); len(names) != 2 || names[0] != "Головные уборы" {
+		t.Errorf("categories after category event = %v", names)
+	}
+}
+
+func strapiEvent(name, model string, id int) StrapiEvent {
+	event := StrapiEvent{Event: name, Model: model}
+	event.Entry.ID = id
+	return event
+}
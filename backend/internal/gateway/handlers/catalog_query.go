@@ -0,0 +1,272 @@
+// internal/gateway/handlers/catalog_query.go
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 24
+	maxPageSize     = 100
+	maxFilterValues = 20
+)
+
+// productSorts — допустимые значения параметра sort и их аналоги в Strapi
+var productSorts = map[string][]string{
+	"newest":     {"publishedAt:desc", "id:desc"},
+	"oldest":     {"publishedAt:asc", "id:asc"},
+	"price_asc":  {"price:asc", "id:asc"},
+	"price_desc": {"price:desc", "id:desc"},
+	"name_asc":   {"name:asc", "id:asc"},
+	"name_desc":  {"name:desc", "id:desc"},
+}
+
+// ProductQuery — параметры листинга каталога из строки запроса
+type ProductQuery struct {
+	Page       int
+	PageSize   int
+	Cursor     *productCursor
+	Categories []string
+	Brands     []string
+	Sizes      []string
+	MinPrice   *int
+	MaxPrice   *int
+	Sort       string
+	// Facets — добавить к странице агрегаты по всей выборке
+	Facets bool
+}
+
+// productCursor — непрозрачный курсор для бесконечной ленты; внутри смещение
+type productCursor struct {
+	Offset int `json:"o"`
+}
+
+func (c productCursor) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string) (*productCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный cursor")
+	}
+	var cursor productCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.Offset < 0 {
+		return nil, fmt.Errorf("некорректный cursor")
+	}
+	return &cursor, nil
+}
+
+// parseProductQuery читает и проверяет параметры листинга.
+// Наружу уходят только известные параметры — ключи фильтров Strapi
+// формируются здесь, а значения пользователя попадают лишь в значения.
+func parseProductQuery(c *gin.Context) (ProductQuery, error) {
+	q := ProductQuery{
+		Page:     1,
+		PageSize: defaultPageSize,
+		Sort:     "newest",
+	}
+
+	var err error
+	if q.Page, err = intParam(c, "page", 1, 1, 1<<20); err != nil {
+		return q, err
+	}
+	if q.PageSize, err = intParam(c, "pageSize", defaultPageSize, 1, maxPageSize); err != nil {
+		return q, err
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		if c.Query("page") != "" {
+			return q, fmt.Errorf("page и cursor нельзя использовать вместе")
+		}
+		if q.Cursor, err = decodeCursor(cursor); err != nil {
+			return q, err
+		}
+	}
+
+	if q.Categories, err = listParam(c, "category"); err != nil {
+		return q, err
+	}
+	if q.Brands, err = listParam(c, "brand"); err != nil {
+		return q, err
+	}
+	if q.Sizes, err = listParam(c, "size"); err != nil {
+		return q, err
+	}
+
+	if q.MinPrice, err = optionalIntParam(c, "minPrice"); err != nil {
+		return q, err
+	}
+	if q.MaxPrice, err = optionalIntParam(c, "maxPrice"); err != nil {
+		return q, err
+	}
+	if q.MinPrice != nil && q.MaxPrice != nil && *q.MinPrice > *q.MaxPrice {
+		return q, fmt.Errorf("minPrice больше maxPrice")
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		if _, ok := productSorts[sort]; !ok {
+			return q, fmt.Errorf("неизвестная сортировка %q", sort)
+		}
+		q.Sort = sort
+	}
+
+	switch c.Query("facets") {
+	case "", "false", "0":
+	case "true", "1":
+		q.Facets = true
+	default:
+		return q, fmt.Errorf("параметр facets должен быть true или false")
+	}
+
+	return q, nil
+}
+
+// termRelations отмечает поля category и brand, которые в схеме Strapi —
+// связи с коллекциями {name, slug}, а не строковые поля
+type termRelations map[string]bool
+
+// newTermRelations считает связью поле, которое подгружается через populate
+func newTermRelations(populate []string) termRelations {
+	relations := termRelations{}
+	for _, path := range populate {
+		field, _, _ := strings.Cut(strings.TrimSpace(path), ".")
+		relations[field] = true
+	}
+	return relations
+}
+
+// filterValues переводит фильтры в синтаксис filters[...] Strapi.
+// Каждый фильтр — отдельная группа $or внутри $and, чтобы условия
+// разных фильтров не смешивались в одном $or.
+func (q ProductQuery) filterValues(relations termRelations) url.Values {
+	v := url.Values{}
+	group := 0
+
+	// Категория и бренд совпадают со значением без учёта регистра по названию,
+	// а у связи — ещё и по slug; фасеты считаются по тому же правилу (matchesTerm)
+	setTerm := func(field string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		paths := []string{"[" + field + "]"}
+		if relations[field] {
+			paths = []string{"[" + field + "][slug]", "[" + field + "][name]"}
+		}
+		i := 0
+		for _, value := range values {
+			for _, path := range paths {
+				v.Set(fmt.Sprintf("filters[$and][%d][$or][%d]%s[$eqi]", group, i, path), value)
+				i++
+			}
+		}
+		group++
+	}
+	setTerm("category", q.Categories)
+	setTerm("brand", q.Brands)
+
+	// Размер хранится строкой или JSON-массивом: совпадение со строкой
+	// целиком либо с элементом массива в кавычках
+	if len(q.Sizes) > 0 {
+		for i, size := range q.Sizes {
+			v.Set(fmt.Sprintf("filters[$and][%d][$or][%d][size][$eqi]", group, 2*i), size)
+			v.Set(fmt.Sprintf("filters[$and][%d][$or][%d][size][$containsi]", group, 2*i+1), strconv.Quote(size))
+		}
+	}
+
+	if q.MinPrice != nil {
+		v.Set("filters[price][$gte]", strconv.Itoa(*q.MinPrice))
+	}
+	if q.MaxPrice != nil {
+		v.Set("filters[price][$lte]", strconv.Itoa(*q.MaxPrice))
+	}
+
+	return v
+}
+
+// strapiValues — фильтры вместе с сортировкой и пагинацией
+func (q ProductQuery) strapiValues(relations termRelations) url.Values {
+	v := q.filterValues(relations)
+
+	for i, sort := range productSorts[q.Sort] {
+		v.Set(fmt.Sprintf("sort[%d]", i), sort)
+	}
+
+	if q.Cursor != nil {
+		v.Set("pagination[start]", strconv.Itoa(q.Cursor.Offset))
+		v.Set("pagination[limit]", strconv.Itoa(q.PageSize))
+	} else {
+		v.Set("pagination[page]", strconv.Itoa(q.Page))
+		v.Set("pagination[pageSize]", strconv.Itoa(q.PageSize))
+	}
+	v.Set("pagination[withCount]", "true")
+
+	return v
+}
+
+// nextCursor возвращает курсор следующей порции или пустую строку, если её нет
+func (q ProductQuery) nextCursor(total int) string {
+	offset := (q.Page - 1) * q.PageSize
+	if q.Cursor != nil {
+		offset = q.Cursor.Offset
+	}
+	if offset+q.PageSize >= total {
+		return ""
+	}
+	return productCursor{Offset: offset + q.PageSize}.encode()
+}
+
+func intParam(c *gin.Context, name string, def, min, max int) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("параметр %s должен быть числом от %d до %d", name, min, max)
+	}
+	return n, nil
+}
+
+func optionalIntParam(c *gin.Context, name string) (*int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("параметр %s должен быть неотрицательным числом", name)
+	}
+	return &n, nil
+}
+
+// listParam собирает значения из повторяющихся параметров и списков через запятую
+func listParam(c *gin.Context, name string) ([]string, error) {
+	var values []string
+	seen := map[string]bool{}
+	for _, raw := range c.QueryArray(name) {
+		for _, value := range strings.Split(raw, ",") {
+			value = strings.TrimSpace(value)
+			if value == "" || seen[value] {
+				continue
+			}
+			if len(value) > 100 {
+				return nil, fmt.Errorf("слишком длинное значение %s", name)
+			}
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	if len(values) > maxFilterValues {
+		return nil, fmt.Errorf("слишком много значений %s", name)
+	}
+	return values, nil
+}
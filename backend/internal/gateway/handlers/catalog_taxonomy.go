@@ -0,0 +1,167 @@
+// internal/gateway/handlers/catalog_taxonomy.go
+package handlers
+
+import (
+	"backend/internal/strapi"
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// categoryNode — узел дерева категорий; Count учитывает товары всех потомков
+type categoryNode struct {
+	Name     string          `json:"name"`
+	Slug     string          `json:"slug,omitempty"`
+	Path     string          `json:"path"`
+	Count    int             `json:"count"`
+	Children []*categoryNode `json:"children,omitempty"`
+}
+
+type brandCount struct {
+	Name  string `json:"name"`
+	Slug  string `json:"slug,omitempty"`
+	Count int    `json:"count"`
+}
+
+// taxonomy — агрегаты по всему каталогу, которые кешируются вместе.
+// products — снимок каталога, по которому в памяти считаются фасеты листинга.
+type taxonomy struct {
+	Categories []*categoryNode
+	Brands     []brandCount
+
+	products []strapi.Product
+}
+
+// GetCategories godoc
+// @Summary Получить дерево категорий
+// @Description Категории каталога с количеством товаров; flat=true возвращает плоский список
+// @Tags Catalog
+// @Produce json
+// @Param flat query bool false "Плоский список вместо дерева"
+// @Success 200 {object} interface{}
+// @Failure 500 {object} gin.H{"error": "Ошибка сервера"}
+// @Router /api/catalog/categories [get]
+func (h *CatalogHandler) GetCategories(c *gin.Context) {
+	tax, err := h.loadTaxonomy(c.Request.Context())
+	if err != nil {
+		strapiFailed(c, err)
+		return
+	}
+
+	if c.Query("flat") == "true" {
+		writeConditionalJSON(c, gin.H{"data": flattenCategories(tax.Categories)}, time.Time{})
+		return
+	}
+	writeConditionalJSON(c, gin.H{"data": tax.Categories}, time.Time{})
+}
+
+// GetBrands godoc
+// @Summary Получить список брендов
+// @Description Бренды каталога с количеством товаров
+// @Tags Catalog
+// @Produce json
+// @Success 200 {object} interface{}
+// @Failure 500 {object} gin.H{"error": "Ошибка сервера"}
+// @Router /api/catalog/brands [get]
+func (h *CatalogHandler) GetBrands(c *gin.Context) {
+	tax, err := h.loadTaxonomy(c.Request.Context())
+	if err != nil {
+		strapiFailed(c, err)
+		return
+	}
+
+	writeConditionalJSON(c, gin.H{"data": tax.Brands}, time.Time{})
+}
+
+// loadTaxonomy возвращает агрегаты из кеша или пересчитывает их по всему каталогу
+func (h *CatalogHandler) loadTaxonomy(ctx context.Context) (*taxonomy, error) {
+	return h.taxonomy.GetOrLoad(ctx, "taxonomy", func(ctx context.Context) (*taxonomy, error) {
+		products, err := h.Strapi.ListAllProducts(ctx, strapi.PopulateValues(h.Config.TaxonomyPopulate))
+		if err != nil {
+			return nil, err
+		}
+		return buildTaxonomy(products, h.Config.CategorySeparator), nil
+	})
+}
+
+func buildTaxonomy(products []strapi.Product, separator string) *taxonomy {
+	roots := &categoryNode{}
+	brands := map[string]*brandCount{}
+
+	for _, product := range products {
+		if path := product.Category.Levels(separator); len(path) > 0 {
+			addCategoryPath(roots, path, product.Category, separator)
+		}
+
+		if name := strings.TrimSpace(product.Brand.Name); name != "" {
+			key := strings.ToLower(name)
+			if brands[key] == nil {
+				brands[key] = &brandCount{Name: name, Slug: product.Brand.Slug}
+			}
+			brands[key].Count++
+		}
+	}
+
+	sortCategories(roots.Children)
+
+	brandList := make([]brandCount, 0, len(brands))
+	for _, b := range brands {
+		brandList = append(brandList, *b)
+	}
+	sort.Slice(brandList, func(i, j int) bool {
+		return strings.ToLower(brandList[i].Name) < strings.ToLower(brandList[j].Name)
+	})
+
+	return &taxonomy{Categories: roots.Children, Brands: brandList, products: products}
+}
+
+func addCategoryPath(root *categoryNode, path []string, term strapi.Term, separator string) {
+	node := root
+	for _, name := range path {
+		var child *categoryNode
+		for _, existing := range node.Children {
+			if strings.EqualFold(existing.Name, name) {
+				child = existing
+				break
+			}
+		}
+		if child == nil {
+			// Путь строится от уже найденного родителя, чтобы в нём было
+			// написание первого встреченного уровня, как и в Name
+			child = &categoryNode{Name: name, Path: name}
+			if node != root {
+				child.Path = node.Path + separator + name
+			}
+			node.Children = append(node.Children, child)
+		}
+		child.Count++
+		node = child
+	}
+	if node.Slug == "" {
+		node.Slug = term.Slug
+	}
+}
+
+func sortCategories(nodes []*categoryNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return strings.ToLower(nodes[i].Name) < strings.ToLower(nodes[j].Name)
+	})
+	for _, node := range nodes {
+		sortCategories(node.Children)
+	}
+}
+
+// flattenCategories разворачивает дерево в список без вложенности
+func flattenCategories(nodes []*categoryNode) []categoryNode {
+	var flat []categoryNode
+	for _, node := range nodes {
+		item := *node
+		item.Children = nil
+		flat = append(flat, item)
+		flat = append(flat, flattenCategories(node.Children)...)
+	}
+	return flat
+}
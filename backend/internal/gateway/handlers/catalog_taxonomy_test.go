@@ -0,0 +1,135 @@
+// internal/gateway/handlers/catalog_taxonomy_test.go
+package handlers
+
+import (
+	"backend/internal/strapi"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBuildTaxonomyFromStrings(t *testing.T) {
+	tax := buildTaxonomy([]strapi.Product{
+		{ID: 1, Category: strapi.Term{Name: "Одежда/Куртки"}, Brand: strapi.Term{Name: "Nord"}},
+		{ID: 2, Category: strapi.Term{Name: "Одежда / Куртки"}, Brand: strapi.Term{Name: "nord "}},
+		{ID: 3, Category: strapi.Term{Name: "одежда/Шапки"}, Brand: strapi.Term{Name: "Sud"}},
+		{ID: 4, Category: strapi.Term{Name: "Обувь"}},
+		{ID: 5},
+	}, "/")
+
+	if len(tax.Categories) != 2 {
+		t.Fatalf("roots = %d, want 2: %+v", len(tax.Categories), tax.Categories)
+	}
+	shoes, clothes := tax.Categories[0], tax.Categories[1]
+	if shoes.Name != "Обувь" || shoes.Count != 1 || len(shoes.Children) != 0 {
+		t.Errorf("shoes = %+v", shoes)
+	}
+	// Регистр уровней не порождает дублей, счётчик родителя включает потомков
+	if clothes.Name != "Одежда" || clothes.Count != 3 || len(clothes.Children) != 2 {
+		t.Fatalf("clothes = %+v", clothes)
+	}
+	jackets, hats := clothes.Children[0], clothes.Children[1]
+	if jackets.Name != "Куртки" || jackets.Count != 2 || jackets.Path != "Одежда/Куртки" {
+		t.Errorf("jackets = %+v", jackets)
+	}
+	if hats.Name != "Шапки" || hats.Count != 1 {
+		t.Errorf("hats = %+v", hats)
+	}
+
+	want := []brandCount{{Name: "Nord", Count: 2}, {Name: "Sud", Count: 1}}
+	if len(tax.Brands) != len(want) {
+		t.Fatalf("brands = %+v, want %+v", tax.Brands, want)
+	}
+	for i := range want {
+		if tax.Brands[i] != want[i] {
+			t.Errorf("brands[%d] = %+v, want %+v", i, tax.Brands[i], want[i])
+		}
+	}
+
+	flat := flattenCategories(tax.Categories)
+	var names []string
+	for _, node := range flat {
+		if len(node.Children) != 0 {
+			t.Errorf("flat node %q has children", node.Name)
+		}
+		names = append(names, node.Path)
+	}
+	if len(names) != 4 || names[0] != "Обувь" || names[1] != "Одежда" || names[2] != "Одежда/Куртки" || names[3] != "Одежда/Шапки" {
+		t.Errorf("flat = %v", names)
+	}
+}
+
+func TestBuildTaxonomyFromRelations(t *testing.T) {
+	clothes := &strapi.Term{Name: "Одежда", Slug: "odezhda"}
+	tax := buildTaxonomy([]strapi.Product{
+		{ID: 1, Category: strapi.Term{Name: "Куртки", Slug: "kurtki", Parent: clothes}, Brand: strapi.Term{Name: "Nord", Slug: "nord"}},
+		// Название со слешем у связи — одно название, а не путь
+		{ID: 2, Category: strapi.Term{Name: "Шапки/шарфы", Slug: "shapki", Parent: clothes}, Brand: strapi.Term{Name: "Nord", Slug: "nord"}},
+		{ID: 3, Category: strapi.Term{Name: "Одежда", Slug: "odezhda"}},
+	}, "/")
+
+	if len(tax.Categories) != 1 {
+		t.Fatalf("roots = %+v", tax.Categories)
+	}
+	root := tax.Categories[0]
+	if root.Slug != "odezhda" || root.Count != 3 || len(root.Children) != 2 {
+		t.Fatalf("root = %+v", root)
+	}
+	if child := root.Children[0]; child.Slug != "kurtki" || child.Count != 1 {
+		t.Errorf("child = %+v", child)
+	}
+	if child := root.Children[1]; child.Name != "Шапки/шарфы" || child.Slug != "shapki" {
+		t.Errorf("child = %+v", child)
+	}
+	if len(tax.Brands) != 1 || tax.Brands[0] != (brandCount{Name: "Nord", Slug: "nord", Count: 2}) {
+		t.Errorf("brands = %+v", tax.Brands)
+	}
+}
+
+func TestTaxonomyEndpointsShareCache(t *testing.T) {
+	fake := newFakeStrapi()
+	fake.products[1] = strapi.Product{ID: 1, Category: strapi.Term{Name: "Одежда/Куртки"}, Brand: strapi.Term{Name: "Nord"}}
+	fake.products[2] = strapi.Product{ID: 2, Category: strapi.Term{Name: "Обувь"}, Brand: strapi.Term{Name: "Sud"}}
+	h := NewCatalogHandler(fake, CatalogConfig{CacheSize: 10, TaxonomyTTL: time.Minute, CategorySeparator: "/"})
+
+	router := gin.New()
+	router.GET("/categories", h.GetCategories)
+	router.GET("/brands", h.GetBrands)
+	get := func(target string, out any) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d", target, rec.Code)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var tree struct{ Data []categoryNode }
+	get("/categories", &tree)
+	if len(tree.Data) != 2 || tree.Data[1].Name != "Одежда" || len(tree.Data[1].Children) != 1 {
+		t.Fatalf("tree = %+v", tree.Data)
+	}
+
+	var flat struct{ Data []categoryNode }
+	get("/categories?flat=true", &flat)
+	if len(flat.Data) != 3 {
+		t.Fatalf("flat = %+v", flat.Data)
+	}
+
+	var brands struct{ Data []brandCount }
+	get("/brands", &brands)
+	if len(brands.Data) != 2 || brands.Data[0].Name != "Nord" || brands.Data[0].Count != 1 {
+		t.Fatalf("brands = %+v", brands.Data)
+	}
+
+	if n := fake.count("ListAllProducts"); n != 1 {
+		t.Errorf("ListAllProducts called %d times, want 1", n)
+	}
+}
@@ -0,0 +1,90 @@
+// internal/gateway/handlers/conditional.go
+package handlers
+
+import (
+	"backend/internal/strapi"
+	"backend/pkg/logger"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheControlKey — ключ gin.Context, под которым маршрут задаёт свой Cache-Control
+const CacheControlKey = "cacheControl"
+
+// writeConditionalJSON отвечает JSON с валидаторами ETag и Last-Modified.
+// Если версия клиента совпадает (If-None-Match / If-Modified-Since),
+// тело не отправляется и клиент получает 304.
+func writeConditionalJSON(c *gin.Context, payload interface{}, lastModified time.Time) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logger.ErrorLogger.Println("Ошибка сериализации ответа:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	header := c.Writer.Header()
+	header.Set("ETag", etag)
+	if !lastModified.IsZero() {
+		header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if cacheControl := c.GetString(CacheControlKey); cacheControl != "" {
+		header.Set("Cache-Control", cacheControl)
+	}
+
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// notModified реализует проверку условных заголовков из RFC 9110:
+// If-None-Match важнее If-Modified-Since
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			// Для GET допустимо слабое сравнение: W/"x" совпадает с "x"
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		return !lastModified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
+
+// productsLastModified возвращает самую позднюю дату изменения среди товаров
+func productsLastModified(products ...strapi.Product) time.Time {
+	var latest time.Time
+	for _, product := range products {
+		updated, err := time.Parse(time.RFC3339, product.UpdatedAt)
+		if err == nil && updated.After(latest) {
+			latest = updated
+		}
+	}
+	return latest
+}
@@ -0,0 +1,353 @@
+// internal/gateway/handlers/fake_strapi_test.go
+package handlers
+
+import (
+	"backend/internal/strapi"
+	"backend/pkg/logger"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	logger.Init()
+}
+
+// fakeStrapi — StrapiAPI в памяти: товары, строки корзин с владельцами и заказы.
+// Методы, которые тесту не нужны, паникуют через встроенный nil-интерфейс.
+type fakeStrapi struct {
+	StrapiAPI
+
+	mu       sync.Mutex
+	products map[int]strapi.Product
+	users    map[int]strapi.User
+	carts    []strapi.CartItem
+	orders   []strapi.Order
+	nextID   int
+	calls    []string
+	// err, если задан, возвращается из всех методов
+	err error
+	// latency задерживает ответ с корзиной, чтобы параллельные запросы пересекались
+	latency time.Duration
+	// listGate, если задан, задерживает ответ ListAllProducts уже после снимка каталога
+	listGate chan struct{}
+}
+
+func newFakeStrapi() *fakeStrapi {
+	return &fakeStrapi{
+		products: map[int]strapi.Product{},
+		users:    map[int]strapi.User{},
+		nextID:   100,
+	}
+}
+
+// addCartLine кладёт строку в корзину пользователя и возвращает её
+func (f *fakeStrapi) addCartLine(userID, productID, quantity int, size string) strapi.CartItem {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextID++
+	product := f.products[productID]
+	item := strapi.CartItem{
+		ID:         f.nextID,
+		DocumentID: "doc" + strconv.Itoa(f.nextID),
+		Quantity:   quantity,
+		Size:       size,
+		Product:    &product,
+		User:       &strapi.User{ID: userID},
+	}
+	f.carts = append(f.carts, item)
+	return item
+}
+
+// called сообщает, вызывался ли метод
+func (f *fakeStrapi) called(method string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, call := range f.calls {
+		if call == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeStrapi) record(method string) error {
+	f.calls = append(f.calls, method)
+	return f.err
+}
+
+// Login принимает пароль "secret" для любого известного пользователя
+func (f *fakeStrapi) Login(_ context.Context, identifier, password string) (*strapi.AuthResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("Login"); err != nil {
+		return nil, err
+	}
+	for _, user := range f.users {
+		if user.Username == identifier && password == "secret" {
+			return &strapi.AuthResponse{User: user}, nil
+		}
+	}
+	return nil, &strapi.Error{StatusCode: http.StatusBadRequest, Message: "Invalid identifier or password"}
+}
+
+func (f *fakeStrapi) GetUser(_ context.Context, id int) (*strapi.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("GetUser"); err != nil {
+		return nil, err
+	}
+	user, ok := f.users[id]
+	if !ok {
+		return nil, &strapi.Error{StatusCode: http.StatusNotFound}
+	}
+	return &user, nil
+}
+
+func (f *fakeStrapi) GetProducts(_ context.Context, query url.Values) (*strapi.ProductList, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("GetProducts"); err != nil {
+		return nil, err
+	}
+	list := &strapi.ProductList{}
+	filtered := false
+	for key, values := range query {
+		if !strings.HasPrefix(key, "filters[id][$in]") {
+			continue
+		}
+		filtered = true
+		id, _ := strconv.Atoi(values[0])
+		if product, ok := f.products[id]; ok {
+			list.Data = append(list.Data, product)
+		}
+	}
+	if !filtered {
+		list.Data = f.sortedProducts()
+	}
+	// Как Strapi с maxLimit по умолчанию: больше 100 записей за раз не отдаётся
+	if len(list.Data) > 100 {
+		list.Data = list.Data[:100]
+	}
+	return list, nil
+}
+
+func (f *fakeStrapi) ListAllProducts(_ context.Context, _ url.Values) ([]strapi.Product, error) {
+	f.mu.Lock()
+
+	if err := f.record("ListAllProducts"); err != nil {
+		f.mu.Unlock()
+		return nil, err
+	}
+	products, gate := f.sortedProducts(), f.listGate
+	f.mu.Unlock()
+
+	if gate != nil {
+		<-gate
+	}
+	return products, nil
+}
+
+// GetProduct отдаёт товар по ID; Related подставляются в текущем состоянии,
+// как при populate в Strapi
+func (f *fakeStrapi) GetProduct(_ context.Context, id string) (*strapi.Product, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("GetProduct"); err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(id)
+	product, ok := f.products[n]
+	if !ok {
+		return nil, &strapi.Error{StatusCode: http.StatusNotFound, Name: "NotFoundError"}
+	}
+	related := make([]strapi.Product, 0, len(product.Related))
+	for _, r := range product.Related {
+		if current, ok := f.products[r.ID]; ok {
+			related = append(related, current)
+		}
+	}
+	product.Related = related
+	return &product, nil
+}
+
+// count возвращает, сколько раз вызывался метод
+func (f *fakeStrapi) count(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for _, call := range f.calls {
+		if call == method {
+			n++
+		}
+	}
+	return n
+}
+
+// sortedProducts возвращает все товары по возрастанию ID
+func (f *fakeStrapi) sortedProducts() []strapi.Product {
+	products := make([]strapi.Product, 0, len(f.products))
+	for _, product := range f.products {
+		products = append(products, product)
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products
+}
+
+func (f *fakeStrapi) GetCartItems(_ context.Context, userID int) (*strapi.CartList, error) {
+	f.mu.Lock()
+	if err := f.record("GetCartItems"); err != nil {
+		f.mu.Unlock()
+		return nil, err
+	}
+	list := &strapi.CartList{}
+	for _, item := range f.carts {
+		if item.User.ID == userID {
+			list.Data = append(list.Data, item)
+		}
+	}
+	f.mu.Unlock()
+
+	// Ответ приходит позже, чем снят снимок, — как по сети
+	time.Sleep(f.latency)
+	return list, nil
+}
+
+func (f *fakeStrapi) GetUserCartItem(_ context.Context, userID int, id string) (*strapi.CartItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("GetUserCartItem"); err != nil {
+		return nil, err
+	}
+	for _, item := range f.carts {
+		if item.User.ID == userID && (strconv.Itoa(item.ID) == id || item.DocumentID == id) {
+			return &item, nil
+		}
+	}
+	return nil, &strapi.Error{StatusCode: http.StatusNotFound}
+}
+
+func (f *fakeStrapi) CreateCartItem(_ context.Context, input strapi.CartItemInput) (*strapi.CartItem, error) {
+	f.mu.Lock()
+	if err := f.record("CreateCartItem"); err != nil {
+		f.mu.Unlock()
+		return nil, err
+	}
+	f.mu.Unlock()
+
+	item := f.addCartLine(input.User, input.Product, input.Quantity, input.Size)
+	return &item, nil
+}
+
+func (f *fakeStrapi) UpdateCartItem(_ context.Context, id string, input strapi.CartItemUpdate) (*strapi.CartItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("UpdateCartItem"); err != nil {
+		return nil, err
+	}
+	for i := range f.carts {
+		if f.carts[i].DocumentID == id {
+			f.carts[i].Quantity = input.Quantity
+			item := f.carts[i]
+			return &item, nil
+		}
+	}
+	return nil, &strapi.Error{StatusCode: http.StatusNotFound}
+}
+
+func (f *fakeStrapi) DeleteCartItem(_ context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("DeleteCartItem"); err != nil {
+		return err
+	}
+	for i := range f.carts {
+		if f.carts[i].DocumentID == id {
+			f.carts = append(f.carts[:i], f.carts[i+1:]...)
+			return nil
+		}
+	}
+	return &strapi.Error{StatusCode: http.StatusNotFound}
+}
+
+func (f *fakeStrapi) GetOrders(_ context.Context, userID int) (*strapi.OrderList, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("GetOrders"); err != nil {
+		return nil, err
+	}
+	list := &strapi.OrderList{}
+	for _, order := range f.orders {
+		if order["user"] == userID {
+			list.Data = append(list.Data, order)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeStrapi) CreateOrder(_ context.Context, data map[string]interface{}) (strapi.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.record("CreateOrder"); err != nil {
+		return nil, err
+	}
+	f.nextID++
+	order := strapi.Order{"id": f.nextID}
+	for key, value := range data {
+		order[key] = value
+	}
+	f.orders = append(f.orders, order)
+	return order, nil
+}
+
+// serve выполняет хендлер, зарегистрированный на route, запросом на target
+// от имени пользователя userID (0 — гость) и возвращает ответ
+func serve(t *testing.T, handler gin.HandlerFunc, method, route, target string, userID int, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		if userID != 0 {
+			c.Set("userID", userID)
+		}
+		handler(c)
+	})
+
+	req := httptest.NewRequest(method, target, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
@@ -0,0 +1,116 @@
+// internal/gateway/handlers/feed_handlers.go
+package handlers
+
+import (
+	"backend/internal/feeds"
+	"backend/pkg/logger"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// feedRetryDelay — пауза перед повтором, если выгрузка не удалась
+const feedRetryDelay = time.Minute
+
+type FeedHandler struct {
+	Generator *feeds.Generator
+	Config    FeedConfig
+}
+
+// FeedConfig — где хранить фиды и как часто их пересобирать
+type FeedConfig struct {
+	Dir      string
+	Interval time.Duration
+}
+
+func NewFeedHandler(generator *feeds.Generator, cfg FeedConfig) *FeedHandler {
+	return &FeedHandler{
+		Generator: generator,
+		Config:    cfg,
+	}
+}
+
+// Run пересобирает фиды по расписанию, пока жив ctx. Отдаются всегда
+// готовые файлы, так что запрос фида не ждёт обхода каталога.
+func (h *FeedHandler) Run(ctx context.Context) {
+	interval := h.Config.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	for {
+		wait := interval
+		started := time.Now()
+		count, err := h.Generator.WriteDir(ctx, h.Config.Dir)
+		if err != nil {
+			logger.ErrorLogger.Println("Ошибка выгрузки фидов:", err)
+			wait = min(interval, feedRetryDelay)
+		} else {
+			logger.InfoLogger.Printf("Фиды выгружены: %d товаров за %s", count, time.Since(started).Round(time.Millisecond))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// YML godoc
+// @Summary Фид Яндекс Маркета
+// @Description Каталог в формате YML: категории, цены, изображения, размеры и наличие
+// @Tags Feeds
+// @Produce xml
+// @Success 200 {string} string "YML"
+// @Success 304 "Не изменился с версии клиента"
+// @Failure 503 {object} gin.H{"error": "Фид ещё не готов"}
+// @Router /feeds/yml [get]
+func (h *FeedHandler) YML(c *gin.Context) {
+	h.serve(c, feeds.FormatYML)
+}
+
+// Google godoc
+// @Summary Фид Google Merchant Center
+// @Description Каталог в формате RSS 2.0 с атрибутами g:
+// @Tags Feeds
+// @Produce xml
+// @Success 200 {string} string "RSS"
+// @Success 304 "Не изменился с версии клиента"
+// @Failure 503 {object} gin.H{"error": "Фид ещё не готов"}
+// @Router /feeds/google.xml [get]
+func (h *FeedHandler) Google(c *gin.Context) {
+	h.serve(c, feeds.FormatGoogle)
+}
+
+// serve отдаёт готовый файл фида потоком; http.ServeContent
+// сам отвечает на If-Modified-Since и Range
+func (h *FeedHandler) serve(c *gin.Context, format feeds.Format) {
+	file, err := os.Open(filepath.Join(h.Config.Dir, format.FileName()))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.ErrorLogger.Println("Ошибка чтения фида:", err)
+		}
+		c.Header("Retry-After", "60")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Фид ещё не готов"})
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		logger.ErrorLogger.Println("Ошибка чтения фида:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+		return
+	}
+
+	c.Header("Content-Type", "application/xml; charset=utf-8")
+	if cacheControl := c.GetString(CacheControlKey); cacheControl != "" {
+		c.Header("Cache-Control", cacheControl)
+	}
+	http.ServeContent(c.Writer, c.Request, format.FileName(), info.ModTime(), file)
+}
@@ -0,0 +1,418 @@
+// internal/gateway/handlers/guest_cart.go
+package handlers
+
+import (
+	"backend/internal/guestcart"
+	"backend/internal/strapi"
+	"backend/pkg/logger"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GuestCartHeader — заголовок с токеном гостевой корзины для клиентов без cookie
+const GuestCartHeader = "X-Guest-Cart"
+
+// isGuest сообщает, что запрос пришёл без валидного токена пользователя
+func isGuest(c *gin.Context) bool {
+	_, ok := c.Get("userID")
+	return !ok
+}
+
+// guestCartsEnabled отвечает 401, если гостевые корзины выключены
+func (h *CartHandler) guestCartsEnabled(c *gin.Context) bool {
+	if h.Config.GuestStore == nil || h.Config.GuestSigner == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
+		return false
+	}
+	return true
+}
+
+// guestCartID достаёт токен гостевой корзины из заголовка или cookie
+// и возвращает идентификатор корзины, если подпись верна
+func (h *CartHandler) guestCartID(c *gin.Context) (id, token string, ok bool) {
+	if h.Config.GuestSigner == nil {
+		return "", "", false
+	}
+	token = c.GetHeader(GuestCartHeader)
+	if token == "" && h.Config.GuestCookie != "" {
+		token, _ = c.Cookie(h.Config.GuestCookie)
+	}
+	if token == "" {
+		return "", "", false
+	}
+	id, ok = h.Config.GuestSigner.Verify(token)
+	return id, token, ok
+}
+
+// setGuestToken отдаёт токен в заголовке и продлевает cookie вместе с корзиной
+func (h *CartHandler) setGuestToken(c *gin.Context, token string) {
+	c.Header(GuestCartHeader, token)
+	if h.Config.GuestCookie == "" {
+		return
+	}
+	c.SetSameSite(h.Config.GuestCookieSameSite)
+	c.SetCookie(h.Config.GuestCookie, token, int(h.Config.GuestTTL.Seconds()), "/", "", h.Config.GuestCookieSecure, true)
+}
+
+func (h *CartHandler) clearGuestToken(c *gin.Context) {
+	if h.Config.GuestCookie == "" {
+		return
+	}
+	c.SetSameSite(h.Config.GuestCookieSameSite)
+	c.SetCookie(h.Config.GuestCookie, "", -1, "/", "", h.Config.GuestCookieSecure, true)
+}
+
+func (h *CartHandler) getGuestCart(c *gin.Context) {
+	if !h.guestCartsEnabled(c) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var lines []cartLine
+
+	if id, _, ok := h.guestCartID(c); ok {
+		cart, err := h.Config.GuestStore.Get(ctx, id)
+		if err != nil {
+			cartFailed(c, err)
+			return
+		}
+		if cart != nil {
+			for _, item := range cart.Items {
+				lines = append(lines, cartLine{
+					ID:        item.ID,
+					ProductID: item.ProductID,
+					Quantity:  item.Quantity,
+					Variant:   cartVariant{Size: item.Size, Color: item.Color},
+					Price:     item.Price,
+				})
+			}
+		}
+	}
+
+	products, err := h.loadCartProducts(ctx, productIDs(lines))
+	if err != nil {
+		strapiFailed(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.buildCartView(lines, products)})
+}
+
+func (h *CartHandler) addToGuestCart(c *gin.Context, product strapi.Product, variant cartVariant, quantity int) {
+	id, token, ok := h.guestCartID(c)
+	if !ok {
+		var err error
+		if id, token, err = h.Config.GuestSigner.NewToken(); err != nil {
+			logger.ErrorLogger.Println("Ошибка создания гостевой корзины:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+			return
+		}
+	}
+
+	var added guestcart.Item
+	_, err := h.Config.GuestStore.Update(c.Request.Context(), id, h.Config.GuestTTL, func(cart *guestcart.Cart) error {
+		item := cart.Find(product.ID, variant.Size, variant.Color)
+		if item == nil {
+			if h.Config.GuestMaxItems > 0 && len(cart.Items) >= h.Config.GuestMaxItems {
+				return errGuestCartFull
+			}
+			item = cart.Add(product.ID, variant.Size, variant.Color)
+		}
+		if err := h.validateQuantity(product, variant, item.Quantity+quantity); err != nil {
+			return err
+		}
+		item.Quantity += quantity
+		item.Price = product.VariantPrice(variant.Size, variant.Color)
+		added = *item
+		return nil
+	})
+	if err != nil {
+		cartFailed(c, err)
+		return
+	}
+
+	h.setGuestToken(c, token)
+	c.JSON(http.StatusCreated, gin.H{"data": guestCartItem(added, product)})
+}
+
+func (h *CartHandler) updateGuestCartItem(c *gin.Context, itemID string, quantity int) {
+	if !h.guestCartsEnabled(c) {
+		return
+	}
+
+	id, token, ok := h.guestCartID(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Товар в корзине не найден"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	cart, err := h.Config.GuestStore.Get(ctx, id)
+	if err != nil {
+		cartFailed(c, err)
+		return
+	}
+	var line *guestcart.Item
+	if cart != nil {
+		for i := range cart.Items {
+			if cart.Items[i].ID == itemID {
+				line = &cart.Items[i]
+			}
+		}
+	}
+	if line == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Товар в корзине не найден"})
+		return
+	}
+
+	product, ok := h.cartProduct(c, line.ProductID)
+	if !ok {
+		return
+	}
+	variant := cartVariant{Size: line.Size, Color: line.Color}
+	if err := h.validateQuantity(product, variant, quantity); err != nil {
+		cartFailed(c, err)
+		return
+	}
+
+	var updated guestcart.Item
+	_, err = h.Config.GuestStore.Update(ctx, id, h.Config.GuestTTL, func(cart *guestcart.Cart) error {
+		for i := range cart.Items {
+			if cart.Items[i].ID == itemID {
+				cart.Items[i].Quantity = quantity
+				cart.Items[i].Price = product.VariantPrice(variant.Size, variant.Color)
+				updated = cart.Items[i]
+				return nil
+			}
+		}
+		return errCartItemNotFound
+	})
+	if err != nil {
+		cartFailed(c, err)
+		return
+	}
+
+	h.setGuestToken(c, token)
+	c.JSON(http.StatusOK, gin.H{"data": guestCartItem(updated, product)})
+}
+
+func (h *CartHandler) clearGuestCart(c *gin.Context) {
+	if !h.guestCartsEnabled(c) {
+		return
+	}
+
+	if id, _, ok := h.guestCartID(c); ok {
+		if err := h.Config.GuestStore.Delete(c.Request.Context(), id); err != nil {
+			cartFailed(c, err)
+			return
+		}
+	}
+	h.clearGuestToken(c)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Корзина очищена"})
+}
+
+func (h *CartHandler) removeFromGuestCart(c *gin.Context, itemID string) {
+	if !h.guestCartsEnabled(c) {
+		return
+	}
+
+	id, token, ok := h.guestCartID(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Товар в корзине не найден"})
+		return
+	}
+
+	_, err := h.Config.GuestStore.Update(c.Request.Context(), id, h.Config.GuestTTL, func(cart *guestcart.Cart) error {
+		if !cart.Remove(itemID) {
+			return errCartItemNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		cartFailed(c, err)
+		return
+	}
+
+	h.setGuestToken(c, token)
+	c.JSON(http.StatusOK, gin.H{"message": "Товар удалён из корзины"})
+}
+
+// adjustedLimit — количество урезано до MaxItemQuantity; остальные причины
+// совпадают с причинами недоступности строки корзины
+const adjustedLimit = "quantity_limit"
+
+// cartAdjustment — строка гостевой корзины, которая после входа оказалась
+// в корзине пользователя в меньшем количестве, чем гость ожидал
+type cartAdjustment struct {
+	ProductID int    `json:"productId"`
+	Size      string `json:"size,omitempty"`
+	Color     string `json:"color,omitempty"`
+	// Requested — сколько было бы в корзине после сложения, Quantity — сколько стало
+	Requested int    `json:"requested"`
+	Quantity  int    `json:"quantity"`
+	Reason    string `json:"reason"`
+}
+
+// MergeGuestCart переносит гостевую корзину в корзину пользователя Strapi после
+// входа: количества одинаковых товаров складываются, но не превышают остаток.
+// Возвращает строки, перенесённые не полностью, чтобы клиент мог о них сообщить.
+// Ошибка переноса не мешает входу — неперенесённые строки остаются у гостя
+// и попадут в корзину при следующем входе.
+func (h *CartHandler) MergeGuestCart(c *gin.Context, userID int) []cartAdjustment {
+	if h.Config.GuestStore == nil {
+		return nil
+	}
+	id, _, ok := h.guestCartID(c)
+	if !ok {
+		return nil
+	}
+
+	ctx := c.Request.Context()
+	cart, err := h.Config.GuestStore.Get(ctx, id)
+	if err != nil {
+		logger.ErrorLogger.Println("Ошибка чтения гостевой корзины:", err)
+		return nil
+	}
+
+	merged, adjusted, err := h.mergeGuestItems(ctx, userID, cart)
+	if err != nil {
+		logger.ErrorLogger.Printf("Ошибка переноса гостевой корзины пользователю %d: %v", userID, err)
+		if len(merged) > 0 {
+			// Уже перенесённые строки убираем, чтобы повторный вход их не удвоил
+			_, err := h.Config.GuestStore.Update(ctx, id, h.Config.GuestTTL, func(cart *guestcart.Cart) error {
+				for _, itemID := range merged {
+					cart.Remove(itemID)
+				}
+				return nil
+			})
+			if err != nil {
+				logger.ErrorLogger.Println("Ошибка сохранения гостевой корзины:", err)
+			}
+		}
+		return adjusted
+	}
+
+	if err := h.Config.GuestStore.Delete(ctx, id); err != nil {
+		logger.ErrorLogger.Println("Ошибка удаления гостевой корзины:", err)
+		return adjusted
+	}
+	h.clearGuestToken(c)
+	return adjusted
+}
+
+// mergeGuestItems возвращает идентификаторы строк, которые уже перенесены,
+// и строки, количество которых пришлось уменьшить
+func (h *CartHandler) mergeGuestItems(ctx context.Context, userID int, cart *guestcart.Cart) ([]string, []cartAdjustment, error) {
+	if cart == nil || len(cart.Items) == 0 {
+		return nil, nil, nil
+	}
+
+	products, err := h.loadCartProducts(ctx, guestProductIDs(cart))
+	if err != nil {
+		return nil, nil, err
+	}
+	unlock := h.locks.lock(userID)
+	defer unlock()
+	existing, err := h.Strapi.GetCartItems(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		merged   []string
+		adjusted []cartAdjustment
+	)
+	for _, item := range cart.Items {
+		adjustment := cartAdjustment{ProductID: item.ProductID, Size: item.Size, Color: item.Color}
+
+		product, ok := products[item.ProductID]
+		if !ok {
+			// Товар сняли с продажи — переносить нечего
+			adjustment.Requested, adjustment.Reason = item.Quantity, unavailableUnpublished
+			adjusted = append(adjusted, adjustment)
+			merged = append(merged, item.ID)
+			continue
+		}
+
+		variant := cartVariant{Size: item.Size, Color: item.Color}
+		var line strapi.CartItem
+		found := findCartLine(existing.Data, item.ProductID, variant)
+		hasLine := found != nil
+		if hasLine {
+			line = *found
+		}
+
+		requested := item.Quantity + line.Quantity
+		quantity := requested
+		if limit := h.Config.MaxItemQuantity; limit > 0 && quantity > limit {
+			quantity = max(limit, line.Quantity)
+			adjustment.Reason = adjustedLimit
+		}
+		if stock, tracked := product.VariantStock(variant.Size, variant.Color); tracked && quantity > stock {
+			quantity = max(stock, line.Quantity)
+			adjustment.Reason = unavailableShortStock
+		}
+
+		switch {
+		case hasLine && quantity > line.Quantity:
+			_, err = h.Strapi.UpdateCartItem(ctx, line.DocumentID, strapi.CartItemUpdate{Quantity: quantity})
+		case !hasLine && quantity > 0:
+			_, err = h.Strapi.CreateCartItem(ctx, strapi.CartItemInput{
+				User:     userID,
+				Product:  item.ProductID,
+				Quantity: quantity,
+				Size:     item.Size,
+				Color:    item.Color,
+				Price:    h.guestPriceSnapshot(item, product),
+			})
+		}
+		if err != nil {
+			return merged, adjusted, err
+		}
+		if quantity < requested {
+			adjustment.Requested, adjustment.Quantity = requested, quantity
+			adjusted = append(adjusted, adjustment)
+		}
+		merged = append(merged, item.ID)
+	}
+	return merged, adjusted, nil
+}
+
+// guestPriceSnapshot переносит цену, которую видел гость, чтобы после входа
+// изменение цены тоже было отмечено
+func (h *CartHandler) guestPriceSnapshot(item guestcart.Item, product strapi.Product) *int {
+	if !h.Config.StorePrice || item.Price <= 0 {
+		return h.priceSnapshot(product, cartVariant{Size: item.Size, Color: item.Color})
+	}
+	price := item.Price
+	return &price
+}
+
+// loadCartProducts загружает опубликованные товары корзины одним запросом
+func (h *CartHandler) loadCartProducts(ctx context.Context, ids []int) (map[int]strapi.Product, error) {
+	return loadProductsByID(ctx, h.Strapi, h.Config.ProductPopulate, ids)
+}
+
+func guestProductIDs(cart *guestcart.Cart) []int {
+	ids := make([]int, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		ids = append(ids, item.ProductID)
+	}
+	return ids
+}
+
+// guestCartItem представляет строку гостевой корзины так же, как строку из Strapi,
+// чтобы клиенту не нужно было различать два вида корзины
+func guestCartItem(item guestcart.Item, product strapi.Product) strapi.CartItem {
+	return strapi.CartItem{
+		DocumentID: item.ID,
+		Quantity:   item.Quantity,
+		Size:       item.Size,
+		Color:      item.Color,
+		Product:    &product,
+		CreatedAt:  item.AddedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,113 @@
+// internal/gateway/handlers/guest_cart_test.go
+package handlers
+
+import (
+	"backend/internal/guestcart"
+	"backend/internal/strapi"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newGuestCartTest возвращает хендлер с гостевыми корзинами в памяти и токен
+// корзины, в которую fill положил строки
+func newGuestCartTest(t *testing.T, fake *fakeStrapi, config CartConfig, fill func(*guestcart.Cart)) (*CartHandler, string) {
+	t.Helper()
+	signer, err := guestcart.NewSigner([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.GuestStore = guestcart.NewMemoryStore()
+	config.GuestSigner = signer
+	config.GuestTTL = time.Hour
+	config.GuestCookie = "guest_cart"
+
+	id, token, err := signer.NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.GuestStore.Update(context.Background(), id, config.GuestTTL, func(cart *guestcart.Cart) error {
+		fill(cart)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return NewCartHandler(fake, config), token
+}
+
+func guestContext(method, target, token string, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set(GuestCartHeader, token)
+	return c, rec
+}
+
+func TestMergeGuestCartReportsClampedLines(t *testing.T) {
+	stock := 3
+	fake := newFakeStrapi()
+	fake.products[1] = strapi.Product{ID: 1, Price: 1000, Variants: []strapi.Variant{{Size: "M", Stock: &stock}}}
+	fake.products[2] = strapi.Product{ID: 2, Price: 500}
+	fake.products[3] = strapi.Product{ID: 3, Price: 700}
+	fake.addCartLine(7, 2, 4, "")
+
+	h, token := newGuestCartTest(t, fake, CartConfig{MaxItemQuantity: 5}, func(cart *guestcart.Cart) {
+		cart.Add(1, "M", "").Quantity = 4
+		cart.Add(2, "", "").Quantity = 3
+		cart.Add(3, "", "").Quantity = 2
+		cart.Add(99, "", "").Quantity = 1
+	})
+
+	c, _ := guestContext(http.MethodPost, "/auth/login", token, "")
+	adjusted := h.MergeGuestCart(c, 7)
+
+	want := []cartAdjustment{
+		{ProductID: 1, Size: "M", Requested: 4, Quantity: 3, Reason: unavailableShortStock},
+		{ProductID: 2, Requested: 7, Quantity: 5, Reason: adjustedLimit},
+		{ProductID: 99, Requested: 1, Quantity: 0, Reason: unavailableUnpublished},
+	}
+	if len(adjusted) != len(want) {
+		t.Fatalf("adjusted = %+v, want %+v", adjusted, want)
+	}
+	for i := range want {
+		if adjusted[i] != want[i] {
+			t.Errorf("adjusted[%d] = %+v, want %+v", i, adjusted[i], want[i])
+		}
+	}
+
+	quantities := map[int]int{}
+	for _, item := range fake.carts {
+		quantities[item.Product.ID] = item.Quantity
+	}
+	if quantities[1] != 3 || quantities[2] != 5 || quantities[3] != 2 {
+		t.Fatalf("user cart = %v, want 1:3 2:5 3:2", quantities)
+	}
+}
+
+func TestRemoveFromGuestCartRefreshesCookie(t *testing.T) {
+	fake := newFakeStrapi()
+	h, token := newGuestCartTest(t, fake, CartConfig{}, func(cart *guestcart.Cart) {
+		cart.Add(1, "", "").Quantity = 1
+		cart.Add(2, "", "").Quantity = 1
+	})
+
+	c, rec := guestContext(http.MethodDelete, "/cart", token, `{"cartItemId":"g1"}`)
+	h.RemoveFromCart(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	cookie := rec.Header().Get("Set-Cookie")
+	if !strings.HasPrefix(cookie, "guest_cart="+token) || !strings.Contains(cookie, "Max-Age=3600") {
+		t.Fatalf("Set-Cookie = %q, want the token with the store TTL", cookie)
+	}
+	if rec.Header().Get(GuestCartHeader) != token {
+		t.Fatalf("%s = %q, want the token", GuestCartHeader, rec.Header().Get(GuestCartHeader))
+	}
+}
@@ -0,0 +1,107 @@
+// internal/gateway/handlers/handlers.go
+package handlers
+
+import (
+	"backend/internal/strapi"
+	"backend/pkg/logger"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StrapiAPI — методы Strapi, которые нужны хендлерам; в тестах подменяется фейком
+type StrapiAPI interface {
+	Login(ctx context.Context, identifier, password string) (*strapi.AuthResponse, error)
+	Register(ctx context.Context, username, email, password string) (*strapi.AuthResponse, error)
+	GetUser(ctx context.Context, id int) (*strapi.User, error)
+	GetProducts(ctx context.Context, query url.Values) (*strapi.ProductList, error)
+	GetProduct(ctx context.Context, id string) (*strapi.Product, error)
+	ListAllProducts(ctx context.Context, query url.Values) ([]strapi.Product, error)
+	GetCartItems(ctx context.Context, userID int) (*strapi.CartList, error)
+	GetUserCartItem(ctx context.Context, userID int, id string) (*strapi.CartItem, error)
+	CreateCartItem(ctx context.Context, input strapi.CartItemInput) (*strapi.CartItem, error)
+	UpdateCartItem(ctx context.Context, id string, input strapi.CartItemUpdate) (*strapi.CartItem, error)
+	DeleteCartItem(ctx context.Context, id string) error
+	GetOrders(ctx context.Context, userID int) (*strapi.OrderList, error)
+	CreateOrder(ctx context.Context, data map[string]interface{}) (strapi.Order, error)
+	ListOrders(ctx context.Context, query url.Values) (*strapi.OrderList, error)
+	UpdateOrder(ctx context.Context, id string, data map[string]interface{}) (strapi.Order, error)
+}
+
+// currentUserID достаёт ID пользователя, который middleware положил в контекст
+func currentUserID(c *gin.Context) (int, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
+		return 0, false
+	}
+
+	uid, err := toUserID(userID)
+	if err != nil {
+		logger.ErrorLogger.Println("Неверный формат userID:", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID пользователя"})
+		return 0, false
+	}
+	return uid, true
+}
+
+// toUserID приводит значение claims["id"] к int: числа из JWT приходят как float64
+func toUserID(v interface{}) (int, error) {
+	switch id := v.(type) {
+	case int:
+		return id, nil
+	case float64:
+		if id != float64(int(id)) {
+			return 0, fmt.Errorf("дробный ID %v", id)
+		}
+		return int(id), nil
+	default:
+		return strconv.Atoi(fmt.Sprintf("%v", v))
+	}
+}
+
+// strapiFailed логирует ошибку обращения к Strapi и отвечает клиенту 500
+func strapiFailed(c *gin.Context, err error) {
+	logger.ErrorLogger.Println("Ошибка запроса к Strapi:", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+}
+
+// loadProductsByID загружает опубликованные товары по числовым ID запросами
+// не больше maxPageSize: Strapi ограничивает страницу (maxLimit) и молча
+// обрезает лишнее. Ненайденных товаров в результате нет.
+func loadProductsByID(ctx context.Context, client StrapiAPI, populate []string, ids []int) (map[int]strapi.Product, error) {
+	products := make(map[int]strapi.Product, len(ids))
+
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	for start := 0; start < len(unique); start += maxPageSize {
+		chunk := unique[start:min(start+maxPageSize, len(unique))]
+
+		query := strapi.PopulateValues(populate)
+		for i, id := range chunk {
+			query.Set("filters[id][$in]["+strconv.Itoa(i)+"]", strconv.Itoa(id))
+		}
+		query.Set("pagination[pageSize]", strconv.Itoa(len(chunk)))
+
+		list, err := client.GetProducts(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, product := range list.Data {
+			normalizeProduct(&product)
+			products[product.ID] = product
+		}
+	}
+	return products, nil
+}
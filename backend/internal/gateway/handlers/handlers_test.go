@@ -0,0 +1,150 @@
+// internal/gateway/handlers/handlers_test.go
+package handlers
+
+import (
+	"backend/internal/strapi"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestGetOrdersReturnsOnlyOwnOrders(t *testing.T) {
+	fake := newFakeStrapi()
+	fake.orders = []strapi.Order{
+		{"id": 1, "user": 7},
+		{"id": 2, "user": 8},
+	}
+	h := NewOrderHandler(fake, nil)
+
+	rec := serve(t, h.GetOrders, http.MethodGet, "/orders", "/orders", 7, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var list strapi.OrderList
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Data) != 1 || list.Data[0]["id"] != float64(1) {
+		t.Fatalf("orders = %v, want only order 1", list.Data)
+	}
+}
+
+func TestGetOrdersRequiresUser(t *testing.T) {
+	h := NewOrderHandler(newFakeStrapi(), nil)
+
+	rec := serve(t, h.GetOrders, http.MethodGet, "/orders", "/orders", 0, nil)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", rec.Code)
+	}
+}
+
+func TestStrapiFailureIsServerError(t *testing.T) {
+	fake := newFakeStrapi()
+	fake.err = errors.New("connection refused")
+	h := NewOrderHandler(fake, nil)
+
+	rec := serve(t, h.GetOrders, http.MethodGet, "/orders", "/orders", 7, nil)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+}
+
+func TestCreateOrderBuildsItemsFromCart(t *testing.T) {
+	fake := newFakeStrapi()
+	xlPrice := 1200
+	fake.products[1] = strapi.Product{
+		ID:       1,
+		Name:     "Футболка",
+		Price:    1000,
+		Variants: []strapi.Variant{{Size: "M"}, {Size: "XL", Price: &xlPrice}},
+	}
+	fake.addCartLine(7, 1, 2, "xl")
+	fake.addCartLine(8, 1, 5, "M")
+	h := NewOrderHandler(fake, NewCartHandler(fake, CartConfig{}))
+
+	// Позиции, сумма, статус и чужие поля из тела запроса не попадают в заказ
+	body := map[string]interface{}{
+		"address": "Москва",
+		"items":   []map[string]interface{}{{"productId": 1, "quantity": 100, "price": 1}},
+		"total":   1,
+		"status":  "paid",
+		"user":    8,
+		"paidAt":  "2026-01-01",
+	}
+	rec := serve(t, h.CreateOrder, http.MethodPost, "/orders", "/orders", 7, body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body)
+	}
+
+	rec = serve(t, h.GetOrders, http.MethodGet, "/orders", "/orders", 7, nil)
+	var list struct {
+		Data []struct {
+			Address string  `json:"address"`
+			Status  string  `json:"status"`
+			Total   int64   `json:"total"`
+			PaidAt  *string `json:"paidAt"`
+			Items   []struct {
+				ProductID int    `json:"productId"`
+				Name      string `json:"name"`
+				Quantity  int    `json:"quantity"`
+				Size      string `json:"size"`
+				Price     int    `json:"price"`
+			} `json:"items"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Data) != 1 || list.Data[0].Address != "Москва" || len(list.Data[0].Items) != 1 {
+		t.Fatalf("orders = %+v", list.Data)
+	}
+	item := list.Data[0].Items[0]
+	if item.ProductID != 1 || item.Name != "Футболка" || item.Quantity != 2 || item.Size != "XL" || item.Price != 1200 {
+		t.Fatalf("item = %+v, want 2 × XL at 1200 from the cart", item)
+	}
+	order := list.Data[0]
+	if order.Status != orderStatusPending || order.Total != 2*1200*100 || order.PaidAt != nil {
+		t.Fatalf("order = %+v, want pending with the cart total and no client fields", order)
+	}
+
+	// Корзина заказчика очищена, чужая — нет
+	if len(fake.carts) != 1 || fake.carts[0].User.ID != 8 {
+		t.Fatalf("carts = %+v, want only user 8's line", fake.carts)
+	}
+}
+
+func TestCreateOrderRejectsBadCarts(t *testing.T) {
+	cases := []struct {
+		name   string
+		setup  func(*fakeStrapi)
+		status int
+		error  string
+	}{
+		{"empty cart", func(*fakeStrapi) {}, http.StatusBadRequest, "Корзина пуста"},
+		{"unpublished product", func(f *fakeStrapi) {
+			f.addCartLine(7, 2, 1, "")
+		}, http.StatusNotFound, "Товар не найден"},
+		{"size no longer sold", func(f *fakeStrapi) {
+			f.addCartLine(7, 1, 1, "XXL")
+		}, http.StatusBadRequest, "Неверный вариант товара"},
+	}
+	for _, tc := range cases {
+		fake := newFakeStrapi()
+		fake.products[1] = strapi.Product{ID: 1, Name: "Футболка", Price: 1000, Variants: []strapi.Variant{{Size: "M"}}}
+		tc.setup(fake)
+		h := NewOrderHandler(fake, NewCartHandler(fake, CartConfig{}))
+
+		rec := serve(t, h.CreateOrder, http.MethodPost, "/orders", "/orders", 7, map[string]interface{}{})
+		var resp struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != tc.status || resp.Error != tc.error {
+			t.Errorf("%s: %d %q, want %d %q", tc.name, rec.Code, resp.Error, tc.status, tc.error)
+		}
+		if fake.called("CreateOrder") {
+			t.Errorf("%s: order created", tc.name)
+		}
+	}
+}
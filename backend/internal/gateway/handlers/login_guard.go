@@ -0,0 +1,158 @@
+// internal/gateway/handlers/login_guard.go
+package handlers
+
+import (
+	"backend/pkg/logger"
+	"context"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/ulule/limiter/v3"
+)
+
+// LoginGuardConfig задаёт пороги блокировки и задержки при неудачных входах
+type LoginGuardConfig struct {
+	MaxAccountFailures int
+	MaxIPFailures      int
+	LockWindow         time.Duration
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+}
+
+// LoginGuard защищает вход от перебора паролей. Неудачные попытки считаются
+// отдельно по аккаунту и по IP в том же хранилище, что и лимиты маршрутов;
+// каждая следующая неудача отвечает дольше, а по достижении порога
+// вход блокируется до конца окна.
+type LoginGuard struct {
+	accounts  *limiter.Limiter
+	ips       *limiter.Limiter
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+// LoginLock описывает действующую блокировку входа
+type LoginLock struct {
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func NewLoginGuard(store limiter.Store, cfg LoginGuardConfig) *LoginGuard {
+	return &LoginGuard{
+		accounts: limiter.New(store, limiter.Rate{
+			Period: cfg.LockWindow,
+			Limit:  int64(cfg.MaxAccountFailures),
+		}),
+		ips: limiter.New(store, limiter.Rate{
+			Period: cfg.LockWindow,
+			Limit:  int64(cfg.MaxIPFailures),
+		}),
+		baseDelay: cfg.BaseDelay,
+		maxDelay:  cfg.MaxDelay,
+	}
+}
+
+// Check сообщает, заблокирован ли вход для аккаунта или IP, не увеличивая счётчики
+func (g *LoginGuard) Check(ctx context.Context, account, ip string) (LoginLock, error) {
+	for _, probe := range []struct {
+		lim *limiter.Limiter
+		key string
+	}{
+		{g.accounts, accountKey(account)},
+		{g.ips, ipKey(ip)},
+	} {
+		state, err := probe.lim.Peek(ctx, probe.key)
+		if err != nil {
+			return LoginLock{}, err
+		}
+		if state.Remaining <= 0 {
+			return LoginLock{Locked: true, RetryAfter: untilReset(state)}, nil
+		}
+	}
+	return LoginLock{}, nil
+}
+
+// Failure учитывает неудачную попытку и возвращает задержку перед ответом
+func (g *LoginGuard) Failure(ctx context.Context, account, ip string) (time.Duration, error) {
+	accountState, err := g.accounts.Get(ctx, accountKey(account))
+	if err != nil {
+		return 0, err
+	}
+	ipState, err := g.ips.Get(ctx, ipKey(ip))
+	if err != nil {
+		return 0, err
+	}
+
+	failures := accountState.Limit - accountState.Remaining
+	logger.AuditLogger.Printf("event=login_failed account=%q ip=%s failures=%d", account, ip, failures)
+
+	if accountState.Remaining <= 0 {
+		logger.AuditLogger.Printf("event=account_locked account=%q ip=%s until=%s",
+			account, ip, time.Unix(accountState.Reset, 0).UTC().Format(time.RFC3339))
+	}
+	if ipState.Remaining <= 0 {
+		logger.AuditLogger.Printf("event=ip_locked ip=%s until=%s",
+			ip, time.Unix(ipState.Reset, 0).UTC().Format(time.RFC3339))
+	}
+
+	return g.delay(failures), nil
+}
+
+// Success сбрасывает счётчик неудач аккаунта; счётчик IP не сбрасываем,
+// чтобы удачный вход в свой аккаунт не открывал перебор чужих
+func (g *LoginGuard) Success(ctx context.Context, account, ip string) error {
+	_, err := g.accounts.Reset(ctx, accountKey(account))
+	logger.AuditLogger.Printf("event=login_success account=%q ip=%s", account, ip)
+	return err
+}
+
+// delay растёт экспоненциально: base, 2·base, 4·base... но не больше maxDelay
+func (g *LoginGuard) delay(failures int64) time.Duration {
+	if failures <= 1 || g.baseDelay <= 0 {
+		return g.baseDelay
+	}
+
+	d := g.baseDelay
+	for i := int64(1); i < failures; i++ {
+		d *= 2
+		if d >= g.maxDelay {
+			return g.maxDelay
+		}
+	}
+	return d
+}
+
+// sleepContext ждёт d или отмены запроса
+func sleepContext(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+func untilReset(state limiter.Context) time.Duration {
+	d := time.Until(time.Unix(state.Reset, 0))
+	if d < time.Second {
+		return time.Second
+	}
+	return d
+}
+
+func accountKey(account string) string {
+	return "login:account:" + strings.ToLower(strings.TrimSpace(account))
+}
+
+// ipKey считает IPv6-клиентов по подсети /64: провайдер выдаёт её целиком,
+// и смена адреса внутри неё не должна обнулять счётчик
+func ipKey(ip string) string {
+	if addr, err := netip.ParseAddr(ip); err == nil && addr.Is6() && !addr.Is4In6() {
+		prefix, _ := addr.Prefix(64)
+		return "login:ip:" + prefix.String()
+	}
+	return "login:ip:" + ip
+}
@@ -0,0 +1,77 @@
+// internal/gateway/handlers/login_guard_test.go
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ulule/limiter/v3/drivers/store/memory"
+)
+
+func newGuardedLogin(t *testing.T, maxIPFailures int) *gin.Engine {
+	t.Helper()
+
+	h := newTestAuthHandler(newFakeStrapi())
+	h.Guard = NewLoginGuard(memory.NewStore(), LoginGuardConfig{
+		MaxAccountFailures: 100,
+		MaxIPFailures:      maxIPFailures,
+		LockWindow:         time.Minute,
+	})
+
+	// Как в шлюзе без TRUSTED_PROXIES: заголовкам прокси не верим
+	router := gin.New()
+	if err := router.SetTrustedProxies(nil); err != nil {
+		t.Fatal(err)
+	}
+	router.POST("/login", h.Login)
+	return router
+}
+
+func login(router *gin.Engine, account, remoteAddr, forwardedFor string) int {
+	body, _ := json.Marshal(map[string]string{"identifier": account, "password": "wrong"})
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestLoginIPLockIgnoresForwardedFor(t *testing.T) {
+	router := newGuardedLogin(t, 3)
+
+	for i := 1; i <= 3; i++ {
+		spoofed := "10.0.0." + strconv.Itoa(i)
+		if code := login(router, "user"+strconv.Itoa(i), "203.0.113.5:4000", spoofed); code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: status = %d, want 401", i, code)
+		}
+	}
+	if code := login(router, "user4", "203.0.113.5:4000", "10.0.0.4"); code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429 after IP lockout", code)
+	}
+	if code := login(router, "user4", "203.0.113.6:4000", ""); code != http.StatusUnauthorized {
+		t.Fatalf("other IP: status = %d, want 401", code)
+	}
+}
+
+func TestLoginIPLockCoversIPv6Subnet(t *testing.T) {
+	router := newGuardedLogin(t, 2)
+
+	login(router, "a", "[2001:db8:1:2::1]:4000", "")
+	login(router, "b", "[2001:db8:1:2::2]:4000", "")
+	if code := login(router, "c", "[2001:db8:1:2:ffff::3]:4000", ""); code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429 within the same /64", code)
+	}
+	if code := login(router, "c", "[2001:db8:1:3::1]:4000", ""); code != http.StatusUnauthorized {
+		t.Fatalf("other subnet: status = %d, want 401", code)
+	}
+}
@@ -0,0 +1,94 @@
+// internal/gateway/handlers/media_handlers.go
+package handlers
+
+import (
+	"backend/internal/media"
+	"backend/pkg/logger"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MediaHandler struct {
+	Processor *media.Processor
+	Limits    media.Limits
+}
+
+func NewMediaHandler(processor *media.Processor, limits media.Limits) *MediaHandler {
+	return &MediaHandler{
+		Processor: processor,
+		Limits:    limits,
+	}
+}
+
+// Serve godoc
+// @Summary Изображение из Strapi
+// @Description Проксирует файл из /uploads Strapi с изменением размера и формата.
+// @Description Без параметров отдаётся исходный файл; format=auto выбирает WebP по заголовку Accept.
+// @Tags Media
+// @Produce image/jpeg,image/png,image/webp
+// @Param path path string true "Путь загрузки, например uploads/photo.jpg"
+// @Param width query int false "Ширина, округляется вверх до ближайшего шага MEDIA_WIDTHS"
+// @Param height query int false "Высота, округляется вверх до ближайшего шага MEDIA_WIDTHS"
+// @Param fit query string false "contain, cover или fill"
+// @Param quality query int false "Качество 1–100, сводится к ближайшему из MEDIA_QUALITIES"
+// @Param format query string false "auto, webp, jpeg или png"
+// @Success 200 {file} file
+// @Failure 400 {object} gin.H{"error": "Неверные параметры запроса"}
+// @Failure 404 {object} gin.H{"error": "Файл не найден"}
+// @Failure 502 {object} gin.H{"error": "Ошибка загрузки изображения"}
+// @Router /media/{path} [get]
+func (h *MediaHandler) Serve(c *gin.Context) {
+	uploadPath, ok := media.CleanPath(c.Param("path"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Файл не найден"})
+		return
+	}
+
+	query := c.Request.URL.Query()
+	params, err := media.ParseParams(query, c.GetHeader("Accept"), h.Processor.WebP(), h.Limits)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры запроса", "details": err.Error()})
+		return
+	}
+
+	file, result, err := h.Processor.Open(c.Request.Context(), uploadPath, params)
+	if err != nil {
+		switch {
+		case errors.Is(err, media.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Файл не найден"})
+		case errors.Is(err, media.ErrUnsupported):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Неподдерживаемый формат файла"})
+		case errors.Is(err, media.ErrTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Слишком большое изображение"})
+		default:
+			logger.ErrorLogger.Println("Ошибка обработки изображения:", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Ошибка загрузки изображения"})
+		}
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		logger.ErrorLogger.Println("Ошибка чтения изображения из кеша:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+		return
+	}
+
+	c.Header("Content-Type", result.ContentType)
+	c.Header("X-Content-Type-Options", "nosniff")
+	// SVG может содержать скрипты, а отдаётся с домена API
+	if result.ContentType == "image/svg+xml" {
+		c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	}
+	if cacheControl := c.GetString(CacheControlKey); cacheControl != "" {
+		c.Header("Cache-Control", cacheControl)
+	}
+	// Формат выбран по Accept — кешам нужно учитывать этот заголовок
+	if query.Get("format") == "auto" || (query.Get("format") == "" && params.Resized()) {
+		c.Header("Vary", "Accept")
+	}
+	http.ServeContent(c.Writer, c.Request, "", info.ModTime(), file)
+}
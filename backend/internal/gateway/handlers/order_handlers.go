@@ -2,96 +2,204 @@
 package handlers
 
 import (
-	"backend/internal/config"
+	"backend/internal/strapi"
 	"backend/pkg/logger"
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
+	"context"
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 type OrderHandler struct {
-	StrapiURL string
+	Strapi StrapiAPI
+	// Carts — корзины пользователей: из них собирается заказ, под их
+	// блокировкой корзина читается и очищается
+	Carts *CartHandler
 }
 
-func NewOrderHandler(cfg *config.Config) *OrderHandler {
+func NewOrderHandler(client StrapiAPI, carts *CartHandler) *OrderHandler {
 	return &OrderHandler{
-		StrapiURL: cfg.StrapiURL,
+		Strapi: client,
+		Carts:  carts,
 	}
 }
 
+// orderStatusPending — статус нового заказа
+const orderStatusPending = "pending"
+
+// orderFields — поля заказа, которые задаёт клиент. Пользователя, позиции,
+// сумму и статус заполняет шлюз, остальные поля из тела запроса отбрасываются.
+var orderFields = map[string]bool{
+	"address":        true,
+	"phone":          true,
+	"email":          true,
+	"comment":        true,
+	"deliveryMethod": true,
+	"paymentMethod":  true,
+}
+
 // CreateOrder godoc
 // @Summary Создать новый заказ
-// @Description Создаёт новый заказ для текущего пользователя
+// @Description Создаёт заказ текущего пользователя из его корзины на сервере и очищает корзину.
+// @Description Позиции (товар, количество, размер, цвет и цена), сумма и статус берутся из корзины и каталога;
+// @Description из тела запроса принимаются только address, phone, email, comment, deliveryMethod и paymentMethod.
+// @Description total — итог корзины с доставкой в минимальных единицах валюты.
 // @Tags Orders
 // @Accept json
 // @Produce json
 // @Param order body map[string]interface{} true "Данные заказа"
 // @Success 201 {object} interface{}
 // @Failure 400 {object} gin.H{"error": "Неверные данные"}
+// @Failure 400 {object} gin.H{"error": "Корзина пуста"}
+// @Failure 400 {object} gin.H{"error": "Неверный вариант товара"}
 // @Failure 401 {object} gin.H{"error": "Не авторизован"}
+// @Failure 404 {object} gin.H{"error": "Товар не найден"}
+// @Failure 409 {object} gin.H{"error": "Недостаточно товара на складе"}
 // @Failure 500 {object} gin.H{"error": "Ошибка сервера"}
 // @Router /api/orders [post]
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
+	uid, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	var orderData map[string]interface{}
-	if err := c.ShouldBindJSON(&orderData); err != nil {
+	var body map[string]interface{}
+	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
 		return
 	}
+	orderData := map[string]interface{}{}
+	for key, value := range body {
+		if orderFields[key] {
+			orderData[key] = value
+		}
+	}
 
-	// Добавляем ID пользователя к данным заказа
-	orderData["user"] = userID
+	// Пока заказ собирается и корзина очищается, она не меняется:
+	// иначе добавленная в это время строка пропала бы вместе с заказанными
+	unlock := h.Carts.locks.lock(uid)
+	defer unlock()
 
-	payload := map[string]interface{}{
-		"data": orderData,
+	ctx := c.Request.Context()
+	cart, err := h.Strapi.GetCartItems(ctx, uid)
+	if err != nil {
+		strapiFailed(c, err)
+		return
 	}
 
-	payloadBytes, err := json.Marshal(payload)
+	// Позиции собираются из корзины: клиент не может подменить цену или вариант
+	items, total, err := h.cartOrderItems(ctx, cart.Data)
 	if err != nil {
-		logger.ErrorLogger.Println("Ошибка маршалинга данных заказа:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+		orderFailed(c, err)
 		return
 	}
+	orderData["user"] = uid
+	orderData["items"] = items
+	orderData["total"] = total
+	orderData["status"] = orderStatusPending
 
-	resp, err := http.Post(fmt.Sprintf("%s/api/orders", h.StrapiURL), "application/json", bytes.NewBuffer(payloadBytes))
+	createdOrder, err := h.Strapi.CreateOrder(ctx, orderData)
 	if err != nil {
-		logger.ErrorLogger.Println("Ошибка отправки запроса к Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+		strapiFailed(c, err)
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated {
-		body, _ := ioutil.ReadAll(resp.Body)
-		logger.ErrorLogger.Printf("Strapi ответил с ошибкой %d: %s", resp.StatusCode, string(body))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
-		return
+	// Заказ уже создан, поэтому ошибка очистки не отменяет ответ: оставшиеся
+	// строки клиент увидит в корзине
+	for _, line := range cart.Data {
+		if err := h.Strapi.DeleteCartItem(ctx, line.DocumentID); err != nil && !strapi.IsNotFound(err) {
+			logger.ErrorLogger.Printf("Ошибка очистки корзины пользователя %d после заказа: %v", uid, err)
+			break
+		}
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	c.JSON(http.StatusCreated, gin.H{"data": createdOrder})
+}
+
+// cartOrderItems строит позиции заказа по строкам корзины пользователя:
+// размер и цвет приводятся к написанию каталога, цена, остаток и итог — по каталогу
+func (h *OrderHandler) cartOrderItems(ctx context.Context, lines []strapi.CartItem) ([]map[string]interface{}, int64, error) {
+	if len(lines) == 0 {
+		return nil, 0, errEmptyCart
+	}
+
+	cartLines := userCartLines(lines)
+	products, err := h.Carts.loadCartProducts(ctx, productIDs(cartLines))
 	if err != nil {
-		logger.ErrorLogger.Println("Ошибка чтения ответа от Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
-		return
+		return nil, 0, err
 	}
 
-	var createdOrder interface{}
-	if err := json.Unmarshal(body, &createdOrder); err != nil {
-		logger.ErrorLogger.Println("Ошибка парсинга ответа Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+	items := make([]map[string]interface{}, 0, len(lines))
+	for _, line := range cartLines {
+		product, ok := products[line.ProductID]
+		if !ok {
+			return nil, 0, &productNotFoundError{ProductID: line.ProductID}
+		}
+
+		variant, err := resolveVariant(product, line.Variant.Size, line.Variant.Color)
+		if err != nil {
+			var variantErr *variantError
+			if errors.As(err, &variantErr) {
+				variantErr.Message = product.Name + ": " + variantErr.Message
+			}
+			return nil, 0, err
+		}
+		if stock, tracked := product.VariantStock(variant.Size, variant.Color); tracked && line.Quantity > stock {
+			return nil, 0, &stockError{Available: stock}
+		}
+
+		item := map[string]interface{}{
+			"productId": product.ID,
+			"name":      product.Name,
+			"quantity":  line.Quantity,
+			"price":     product.VariantPrice(variant.Size, variant.Color),
+		}
+		setOrderItemValue(item, "size", variant.Size)
+		setOrderItemValue(item, "color", variant.Color)
+		items = append(items, item)
+	}
+
+	// Все строки проверены выше, поэтому итог корзины совпадает с суммой позиций
+	return items, h.Carts.buildCartView(cartLines, products).Totals.Total, nil
+}
+
+func setOrderItemValue(item map[string]interface{}, key, value string) {
+	if value == "" {
+		delete(item, key)
 		return
 	}
+	item[key] = value
+}
+
+var errEmptyCart = errors.New("корзина пуста")
 
-	c.JSON(http.StatusCreated, createdOrder)
+// productNotFoundError — товара из корзины нет в каталоге (снят с публикации или удалён)
+type productNotFoundError struct {
+	ProductID int
+}
+
+func (e *productNotFoundError) Error() string {
+	return "товар " + strconv.Itoa(e.ProductID) + " не найден"
+}
+
+// orderFailed отвечает клиенту по ошибке сборки заказа
+func orderFailed(c *gin.Context, err error) {
+	var notFound *productNotFoundError
+	var variantErr *variantError
+	var stockErr *stockError
+	switch {
+	case errors.Is(err, errEmptyCart):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Корзина пуста"})
+	case errors.As(err, &notFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Товар не найден", "productId": notFound.ProductID})
+	case errors.As(err, &variantErr), errors.As(err, &stockErr):
+		cartFailed(c, err)
+	default:
+		strapiFailed(c, err)
+	}
 }
 
 // GetOrders godoc
@@ -105,40 +213,108 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 // @Failure 500 {object} gin.H{"error": "Ошибка сервера"}
 // @Router /api/orders [get]
 func (h *OrderHandler) GetOrders(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
+	uid, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	resp, err := http.Get(fmt.Sprintf("%s/api/orders?filters[user][$eq]=%d&populate=*", h.StrapiURL, userID))
+	orders, err := h.Strapi.GetOrders(c.Request.Context(), uid)
 	if err != nil {
-		logger.ErrorLogger.Println("Ошибка запроса к Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+		strapiFailed(c, err)
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		logger.ErrorLogger.Printf("Strapi ответил с ошибкой %d: %s", resp.StatusCode, string(body))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
-		return
+	c.JSON(http.StatusOK, orders)
+}
+
+// orderStatuses — допустимые статусы заказа при ручной смене сотрудником
+var orderStatuses = map[string]bool{
+	"pending":   true,
+	"paid":      true,
+	"shipped":   true,
+	"delivered": true,
+	"cancelled": true,
+}
+
+// ListAllOrders godoc
+// @Summary Получить заказы всех пользователей
+// @Description Служебный список заказов с фильтром по статусу и пагинацией
+// @Tags Staff
+// @Accept json
+// @Produce json
+// @Param status query string false "Статус заказа"
+// @Param page query int false "Номер страницы"
+// @Param pageSize query int false "Размер страницы"
+// @Success 200 {object} interface{}
+// @Failure 400 {object} gin.H{"error": "Неверные данные"}
+// @Failure 403 {object} gin.H{"error": "Доступ запрещён"}
+// @Failure 500 {object} gin.H{"error": "Ошибка сервера"}
+// @Router /api/staff/orders [get]
+func (h *OrderHandler) ListAllOrders(c *gin.Context) {
+	query := url.Values{}
+	query.Set("sort", "createdAt:desc")
+
+	if status := c.Query("status"); status != "" {
+		if !orderStatuses[status] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный статус заказа"})
+			return
+		}
+		query.Set("filters[status][$eq]", status)
+	}
+	for param, key := range map[string]string{"page": "pagination[page]", "pageSize": "pagination[pageSize]"} {
+		if value := c.Query(param); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+				return
+			}
+			query.Set(key, strconv.Itoa(n))
+		}
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	orders, err := h.Strapi.ListOrders(c.Request.Context(), query)
 	if err != nil {
-		logger.ErrorLogger.Println("Ошибка чтения ответа от Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+		strapiFailed(c, err)
 		return
 	}
 
-	var orders interface{}
-	if err := json.Unmarshal(body, &orders); err != nil {
-		logger.ErrorLogger.Println("Ошибка парсинга ответа Strapi:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
+	c.JSON(http.StatusOK, orders)
+}
+
+// UpdateOrderStatus godoc
+// @Summary Сменить статус заказа
+// @Description Служебная смена статуса заказа по его documentId
+// @Tags Staff
+// @Accept json
+// @Produce json
+// @Param id path string true "documentId заказа"
+// @Success 200 {object} interface{}
+// @Failure 400 {object} gin.H{"error": "Неверные данные"}
+// @Failure 403 {object} gin.H{"error": "Доступ запрещён"}
+// @Failure 404 {object} gin.H{"error": "Заказ не найден"}
+// @Failure 500 {object} gin.H{"error": "Ошибка сервера"}
+// @Router /api/staff/orders/{id}/status [patch]
+func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
+	var statusData struct {
+		Status string `json:"status" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&statusData); err != nil || !orderStatuses[statusData.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
 		return
 	}
 
-	c.JSON(http.StatusOK, orders)
+	order, err := h.Strapi.UpdateOrder(c.Request.Context(), c.Param("id"), map[string]interface{}{
+		"status": statusData.Status,
+	})
+	if err != nil {
+		if strapi.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Заказ не найден"})
+			return
+		}
+		strapiFailed(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": order})
 }
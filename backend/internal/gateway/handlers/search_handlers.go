@@ -0,0 +1,257 @@
+// internal/gateway/handlers/search_handlers.go
+package handlers
+
+import (
+	"backend/internal/media"
+	"backend/internal/search"
+	"backend/internal/strapi"
+	"backend/pkg/logger"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxSearchQuery       = 200
+	defaultSuggest       = 8
+	maxSuggest           = 20
+	searchRefreshTimeout = 30 * time.Second
+	// searchRetryDelay — пауза перед повтором, если Strapi не ответил при сборке индекса
+	searchRetryDelay = 30 * time.Second
+)
+
+type SearchHandler struct {
+	Strapi StrapiAPI
+	Index  *search.Index
+	Config SearchConfig
+
+	reindex chan struct{}
+	// updates выстраивает пересборку и точечные обновления индекса в очередь:
+	// иначе Replace со списком, прочитанным до изменения товара, затрёт его свежую версию
+	updates sync.Mutex
+}
+
+// SearchConfig — настройки поискового индекса
+type SearchConfig struct {
+	// ReindexInterval — период полной пересборки индекса из Strapi
+	ReindexInterval time.Duration
+	// Populate — связи, которые нужны для индексации (изображения, бренд, категория)
+	Populate []string
+	// Media переписывает ссылки на изображения в выдаче на прокси /media
+	Media *media.Rewriter
+}
+
+func NewSearchHandler(client StrapiAPI, cfg SearchConfig) *SearchHandler {
+	return &SearchHandler{
+		Strapi:  client,
+		Index:   search.NewIndex(),
+		Config:  cfg,
+		reindex: make(chan struct{}, 1),
+	}
+}
+
+// Run строит индекс и поддерживает его в актуальном состоянии:
+// пересобирает по расписанию и по запросу из вебхука, пока жив ctx
+func (h *SearchHandler) Run(ctx context.Context) {
+	interval := h.Config.ReindexInterval
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	for {
+		wait := interval
+		if !h.rebuild(ctx) {
+			wait = min(interval, searchRetryDelay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		case <-h.reindex:
+		}
+	}
+}
+
+func (h *SearchHandler) rebuild(ctx context.Context) bool {
+	h.updates.Lock()
+	defer h.updates.Unlock()
+
+	started := time.Now()
+	products, err := h.Strapi.ListAllProducts(ctx, strapi.PopulateValues(h.Config.Populate))
+	if err != nil {
+		logger.ErrorLogger.Println("Ошибка построения поискового индекса:", err)
+		return false
+	}
+
+	for i := range products {
+		normalizeProduct(&products[i])
+		h.Config.Media.Product(&products[i])
+	}
+	h.Index.Replace(products)
+	logger.InfoLogger.Printf("Поисковый индекс построен: %d товаров за %s", len(products), time.Since(started).Round(time.Millisecond))
+	return true
+}
+
+// HandleStrapiEvent обновляет индекс по событию вебхука Strapi: изменённый
+// товар переиндексируется сразу, остальные изменения — полной пересборкой
+func (h *SearchHandler) HandleStrapiEvent(_ context.Context, event StrapiEvent) {
+	switch {
+	case event.Model == "product":
+		keys := []string{strconv.Itoa(event.Entry.ID)}
+		if event.Entry.DocumentID != "" {
+			keys = append(keys, event.Entry.DocumentID)
+		}
+		removed := event.Event == "entry.delete" || event.Event == "entry.unpublish"
+		// Вебхук не ждёт ни обращения к Strapi, ни идущей пересборки
+		go h.refreshProduct(keys, removed)
+	case strings.HasPrefix(event.Event, "media."), event.Model == "category", event.Model == "brand":
+		h.requestReindex()
+	}
+}
+
+// refreshProduct перечитывает товар из Strapi и заменяет его в индексе;
+// неопубликованный или удалённый товар убирается
+func (h *SearchHandler) refreshProduct(keys []string, removed bool) {
+	h.updates.Lock()
+	defer h.updates.Unlock()
+
+	if removed {
+		h.Index.Remove(keys...)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), searchRefreshTimeout)
+	defer cancel()
+
+	product, err := h.Strapi.GetProduct(ctx, keys[len(keys)-1])
+	if err != nil {
+		if strapi.IsNotFound(err) {
+			h.Index.Remove(keys...)
+			return
+		}
+		logger.ErrorLogger.Println("Ошибка переиндексации товара:", err)
+		return
+	}
+
+	normalizeProduct(product)
+	product.Related = nil
+	h.Config.Media.Product(product)
+	h.Index.Remove(keys...)
+	h.Index.Upsert(*product)
+}
+
+// requestReindex ставит полную пересборку в очередь, не дублируя её
+func (h *SearchHandler) requestReindex() {
+	select {
+	case h.reindex <- struct{}{}:
+	default:
+	}
+}
+
+type searchResponse struct {
+	Data []strapi.Product `json:"data"`
+	Meta searchMeta       `json:"meta"`
+}
+
+type searchMeta struct {
+	Query      string            `json:"query"`
+	Pagination strapi.Pagination `json:"pagination"`
+}
+
+// Search godoc
+// @Summary Поиск товаров
+// @Description Полнотекстовый поиск по названию, бренду, категории и описанию с учётом
+// @Description морфологии (русский и английский), опечаток и дополнения последнего слова
+// @Tags Catalog
+// @Produce json
+// @Param q query string true "Поисковый запрос"
+// @Param page query int false "Номер страницы"
+// @Param pageSize query int false "Размер страницы (до 100)"
+// @Success 200 {object} searchResponse
+// @Failure 400 {object} gin.H{"error": "Неверные параметры запроса"}
+// @Failure 503 {object} gin.H{"error": "Поиск временно недоступен"}
+// @Router /api/catalog/search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	query, ok := searchQuery(c)
+	if !ok {
+		return
+	}
+	page, err := intParam(c, "page", 1, 1, 1<<20)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры запроса", "details": err.Error()})
+		return
+	}
+	pageSize, err := intParam(c, "pageSize", defaultPageSize, 1, maxPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры запроса", "details": err.Error()})
+		return
+	}
+
+	if !h.Index.Ready() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Поиск временно недоступен"})
+		return
+	}
+
+	hits, total := h.Index.Search(query, (page-1)*pageSize, pageSize)
+	products := make([]strapi.Product, 0, len(hits))
+	for _, hit := range hits {
+		products = append(products, hit.Product)
+	}
+
+	writeConditionalJSON(c, searchResponse{
+		Data: products,
+		Meta: searchMeta{
+			Query: query,
+			Pagination: strapi.Pagination{
+				Page:      page,
+				PageSize:  pageSize,
+				PageCount: (total + pageSize - 1) / pageSize,
+				Total:     total,
+			},
+		},
+	}, time.Time{})
+}
+
+// Suggest godoc
+// @Summary Подсказки поиска
+// @Description Дополняет последнее слово запроса словами из названий и брендов
+// @Tags Catalog
+// @Produce json
+// @Param q query string true "Начало запроса"
+// @Param limit query int false "Количество подсказок (до 20)"
+// @Success 200 {object} interface{}
+// @Failure 400 {object} gin.H{"error": "Неверные параметры запроса"}
+// @Router /api/catalog/search/suggest [get]
+func (h *SearchHandler) Suggest(c *gin.Context) {
+	query, ok := searchQuery(c)
+	if !ok {
+		return
+	}
+	limit, err := intParam(c, "limit", defaultSuggest, 1, maxSuggest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры запроса", "details": err.Error()})
+		return
+	}
+
+	suggestions := h.Index.Suggest(query, limit)
+	if suggestions == nil {
+		suggestions = []string{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": suggestions})
+}
+
+// searchQuery читает и проверяет параметр q
+func searchQuery(c *gin.Context) (string, bool) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" || len([]rune(query)) > maxSearchQuery {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные параметры запроса", "details": "параметр q обязателен и не длиннее 200 символов"})
+		return "", false
+	}
+	return query, true
+}
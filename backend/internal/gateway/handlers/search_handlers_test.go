@@ -0,0 +1,74 @@
+// internal/gateway/handlers/search_handlers_test.go
+package handlers
+
+import (
+	"backend/internal/strapi"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSearchRefreshDuringRebuildKeepsFreshProduct(t *testing.T) {
+	fake := newFakeStrapi()
+	fake.products[1] = strapi.Product{ID: 1, Name: "Куртка"}
+	fake.listGate = make(chan struct{})
+	h := NewSearchHandler(fake, SearchConfig{})
+
+	// Пересборка прочитала каталог до изменения товара и ещё не применила его
+	rebuilt := make(chan bool)
+	go func() { rebuilt <- h.rebuild(context.Background()) }()
+	for fake.count("ListAllProducts") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	fake.mu.Lock()
+	fake.products[1] = strapi.Product{ID: 1, Name: "Пуховик"}
+	fake.mu.Unlock()
+	h.HandleStrapiEvent(context.Background(), strapiEvent("entry.update", "product", 1))
+
+	time.Sleep(20 * time.Millisecond)
+	close(fake.listGate)
+	if !<-rebuilt {
+		t.Fatal("rebuild failed")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		hits, _ := h.Index.Search("пуховик", 0, 10)
+		if len(hits) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			old, _ := h.Index.Search("куртка", 0, 10)
+			t.Fatalf("fresh product lost after rebuild; stale hits = %d", len(old))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if hits, _ := h.Index.Search("куртка", 0, 10); len(hits) != 0 {
+		t.Errorf("stale version still indexed")
+	}
+}
+
+func TestSearchRemovesUnpublishedProduct(t *testing.T) {
+	fake := newFakeStrapi()
+	fake.products[1] = strapi.Product{ID: 1, Name: "Куртка"}
+	fake.products[2] = strapi.Product{ID: 2, Name: "Куртка зимняя"}
+	h := NewSearchHandler(fake, SearchConfig{})
+	if !h.rebuild(context.Background()) {
+		t.Fatal("rebuild failed")
+	}
+
+	h.HandleStrapiEvent(context.Background(), strapiEvent("entry.unpublish", "product", 1))
+	fake.mu.Lock()
+	delete(fake.products, 2)
+	fake.mu.Unlock()
+	h.HandleStrapiEvent(context.Background(), strapiEvent("entry.update", "product", 2))
+
+	deadline := time.Now().Add(time.Second)
+	for h.Index.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("index len = %d, want 0", h.Index.Len())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,113 @@
+// internal/gateway/handlers/webhook_handlers.go
+package handlers
+
+import (
+	"backend/pkg/logger"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBody ограничивает размер тела вебхука
+const maxWebhookBody = 1 << 20
+
+// StrapiEvent — событие вебхука Strapi (entry.create, entry.update,
+// entry.delete, entry.publish, entry.unpublish, media.*)
+type StrapiEvent struct {
+	Event     string `json:"event"`
+	CreatedAt string `json:"createdAt"`
+	Model     string `json:"model"`
+	UID       string `json:"uid"`
+	Entry     struct {
+		ID         int    `json:"id"`
+		DocumentID string `json:"documentId"`
+	} `json:"entry"`
+}
+
+// StrapiEventListener получает события вебхука после проверки подписи
+type StrapiEventListener func(ctx context.Context, event StrapiEvent)
+
+type WebhookHandler struct {
+	Secret    string
+	listeners []StrapiEventListener
+}
+
+func NewWebhookHandler(secret string) *WebhookHandler {
+	return &WebhookHandler{
+		Secret: secret,
+	}
+}
+
+// Subscribe добавляет получателя событий Strapi
+func (h *WebhookHandler) Subscribe(listener StrapiEventListener) {
+	h.listeners = append(h.listeners, listener)
+}
+
+// HandleStrapi godoc
+// @Summary Вебхук Strapi
+// @Description Принимает события изменения записей и сбрасывает связанные кеши.
+// @Description Подпись — HMAC-SHA256 тела в X-Strapi-Signature или секрет в Authorization: Bearer
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {object} gin.H{"message": "ok"}
+// @Failure 400 {object} gin.H{"error": "Неверные данные"}
+// @Failure 401 {object} gin.H{"error": "Неверная подпись"}
+// @Router /webhooks/strapi [post]
+func (h *WebhookHandler) HandleStrapi(c *gin.Context) {
+	if h.Secret == "" {
+		logger.ErrorLogger.Println("Вебхук Strapi отклонён: STRAPI_WEBHOOK_SECRET не задан")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Вебхук не настроен"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	if !h.validSignature(c, body) {
+		logger.ErrorLogger.Println("Вебхук Strapi с неверной подписью от", c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверная подпись"})
+		return
+	}
+
+	var event StrapiEvent
+	if err := json.Unmarshal(body, &event); err != nil || event.Event == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	for _, listener := range h.listeners {
+		listener(c.Request.Context(), event)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+// validSignature принимает HMAC тела или статический секрет: сам Strapi
+// умеет отправлять только фиксированные заголовки, HMAC — для прокси перед ним
+func (h *WebhookHandler) validSignature(c *gin.Context, body []byte) bool {
+	if signature := c.GetHeader("X-Strapi-Signature"); signature != "" {
+		signature = strings.TrimPrefix(signature, "sha256=")
+		got, err := hex.DecodeString(signature)
+		if err != nil {
+			return false
+		}
+		mac := hmac.New(sha256.New, []byte(h.Secret))
+		mac.Write(body)
+		return hmac.Equal(got, mac.Sum(nil))
+	}
+
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.Secret)) == 1
+}
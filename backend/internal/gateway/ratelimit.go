@@ -0,0 +1,112 @@
+// internal/gateway/ratelimit.go
+package gateway
+
+import (
+	"backend/internal/auth"
+	"backend/pkg/logger"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/ulule/limiter/v3"
+	memory "github.com/ulule/limiter/v3/drivers/store/memory"
+	sredis "github.com/ulule/limiter/v3/drivers/store/redis"
+)
+
+// Политики ограничения скорости; лимиты задаются в Config.RateLimits
+const (
+	RatePolicyGlobal  = "global"  // общий лимит по IP до аутентификации
+	RatePolicyDefault = "default" // маршруты без собственной политики
+	RatePolicyCatalog = "catalog" // чтение каталога
+	RatePolicyCart    = "cart"    // изменение корзины
+	RatePolicyOrders  = "orders"  // создание заказов
+	RatePolicyLogin   = "login"   // вход и регистрация
+)
+
+// rateLimitHeaders открываются браузеру на всех маршрутах с лимитом
+var rateLimitHeaders = []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"}
+
+// newLimiterStore создаёт хранилище счётчиков для лимитеров.
+// С Redis счётчики общие для всех реплик шлюза, без него — локальные.
+func newLimiterStore(redisClient *redis.Client, prefix string) (limiter.Store, error) {
+	if redisClient == nil {
+		return memory.NewStore(), nil
+	}
+
+	store, err := sredis.NewStoreWithOptions(redisClient, limiter.StoreOptions{
+		Prefix: prefix + "ratelimit",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("хранилище лимитов в Redis: %w", err)
+	}
+	return store, nil
+}
+
+// newRateLimiters создаёт лимитеры политик поверх общего хранилища
+func newRateLimiters(policies map[string]string, store limiter.Store) (map[string]*limiter.Limiter, error) {
+	limiters := make(map[string]*limiter.Limiter, len(policies))
+	for name, formatted := range policies {
+		rate, err := limiter.NewRateFromFormatted(formatted)
+		if err != nil {
+			return nil, fmt.Errorf("лимит %q (%s): %w", name, formatted, err)
+		}
+		limiters[name] = limiter.New(store, rate)
+	}
+
+	if _, ok := limiters[RatePolicyDefault]; !ok {
+		return nil, fmt.Errorf("не задан лимит %q", RatePolicyDefault)
+	}
+	return limiters, nil
+}
+
+// RateLimit ограничивает частоту запросов по политике. Ключ — ID пользователя,
+// если middleware аутентификации уже отработал, иначе IP клиента.
+// Ответ содержит заголовки RateLimit-* из черновика IETF.
+func (g *Gateway) RateLimit(policy string) gin.HandlerFunc {
+	lim, ok := g.RateLimiters[policy]
+	if !ok {
+		lim = g.RateLimiters[RatePolicyDefault]
+	}
+	window := int(lim.Rate.Period / time.Second)
+
+	return func(c *gin.Context) {
+		key := policy + ":" + rateLimitKey(c)
+
+		ctx, err := lim.Get(c.Request.Context(), key)
+		if err != nil {
+			// Недоступное хранилище лимитов не должно ронять магазин
+			logger.ErrorLogger.Println("Ошибка лимитера:", err)
+			c.Next()
+			return
+		}
+
+		reset := ctx.Reset - time.Now().Unix()
+		if reset < 0 {
+			reset = 0
+		}
+
+		c.Header("RateLimit-Limit", strconv.FormatInt(ctx.Limit, 10))
+		c.Header("RateLimit-Remaining", strconv.FormatInt(ctx.Remaining, 10))
+		c.Header("RateLimit-Reset", strconv.FormatInt(reset, 10))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", ctx.Limit, window))
+		exposeHeaders(c, rateLimitHeaders...)
+
+		if ctx.Reached {
+			c.Header("Retry-After", strconv.FormatInt(reset, 10))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Слишком много запросов"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func rateLimitKey(c *gin.Context) string {
+	if principal, ok := auth.PrincipalFrom(c); ok {
+		return "user:" + strconv.Itoa(principal.UserID)
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,48 @@
+// internal/gateway/rbac.go
+package gateway
+
+import (
+	"backend/internal/auth"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole пропускает пользователей с одной из указанных ролей.
+// Ставится после Middleware, который кладёт Principal в контекст.
+func (g *Gateway) RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.PrincipalFrom(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
+			return
+		}
+
+		if !principal.HasRole(roles...) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Доступ запрещён"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission пропускает пользователей, у которых есть все указанные права
+func (g *Gateway) RequirePermission(perms ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.PrincipalFrom(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
+			return
+		}
+
+		for _, perm := range perms {
+			if !principal.HasPermission(perm) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Доступ запрещён"})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+// internal/gateway/rbac_test.go
+package gateway
+
+import (
+	"backend/internal/auth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRBACUnauthorizedVersusForbidden(t *testing.T) {
+	g, issuer := newTestGateway(t)
+	perms, err := auth.ParseRolePermissions("admin=*;manager=orders:read")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.RolePermissions = perms
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router := gin.New()
+	router.GET("/role", g.Middleware(), g.RequireRole("admin"), ok)
+	router.GET("/perm", g.Middleware(), g.RequirePermission("orders:read"), ok)
+	router.GET("/perm-both", g.Middleware(), g.RequirePermission("orders:read", "orders:manage"), ok)
+	// Без middleware аутентификации Principal в контексте нет
+	router.GET("/no-auth", g.RequirePermission("orders:read"), ok)
+
+	tokens := map[string]string{"": ""}
+	for _, role := range []string{"admin", "manager", "customer"} {
+		tokens[role] = issue(t, issuer, 1, role)
+	}
+
+	cases := []struct {
+		route  string
+		role   string
+		status int
+	}{
+		{"/role", "", http.StatusUnauthorized},
+		{"/role", "customer", http.StatusForbidden},
+		{"/role", "manager", http.StatusForbidden},
+		{"/role", "admin", http.StatusOK},
+		{"/perm", "", http.StatusUnauthorized},
+		{"/perm", "customer", http.StatusForbidden},
+		{"/perm", "manager", http.StatusOK},
+		{"/perm", "admin", http.StatusOK},
+		{"/perm-both", "manager", http.StatusForbidden},
+		{"/perm-both", "admin", http.StatusOK},
+		{"/no-auth", "admin", http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.route, nil)
+		if token := tokens[tc.role]; token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != tc.status {
+			t.Errorf("%s as %q: status = %d, want %d", tc.route, tc.role, rec.Code, tc.status)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// internal/gateway/routes_test.go
+package gateway
+
+import (
+	"backend/internal/auth"
+	"backend/internal/config"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter собирает шлюз из конфигурации, как в cmd/gateway, поверх
+// Strapi, который на любой запрос отвечает пустым списком
+func newTestRouter(t *testing.T, env map[string]string) (*gin.Engine, *auth.Issuer) {
+	t.Helper()
+
+	strapiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[],"meta":{"pagination":{"page":1,"pageSize":25,"pageCount":0,"total":0}}}`))
+	}))
+	t.Cleanup(strapiServer.Close)
+
+	dir := t.TempDir()
+	defaults := map[string]string{
+		"JWT_SECRET":      "test-secret",
+		"JWT_ISSUER":      "",
+		"JWT_AUDIENCE":    "",
+		"STRAPI_URL":      strapiServer.URL,
+		"MEDIA_CACHE_DIR": dir + "/media",
+		"FEEDS_DIR":       dir + "/feeds",
+		"FRONTEND_URL":    "https://shop.example.com",
+		"REDIS_URL":       "",
+	}
+	for key, value := range env {
+		defaults[key] = value
+	}
+	for key, value := range defaults {
+		t.Setenv(key, value)
+	}
+	// JWT_ALGORITHMS снимаем, чтобы алгоритмы выбрались по умолчанию
+	t.Setenv("JWT_ALGORITHMS", "")
+	os.Unsetenv("JWT_ALGORITHMS")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw, err := NewGateway(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := gw.SetupRouter()
+	return router, auth.NewIssuer([]byte(cfg.JWTSecret), time.Minute, cfg.JWTIssuer, cfg.JWTAudience)
+}
+
+// call выполняет запрос к шлюзу; token — Bearer-токен или пусто
+func call(router *gin.Engine, method, target, token string, headers map[string]string) *httptest.ResponseRecorder {
+	var body *bytes.Reader
+	if method == http.MethodPost {
+		body = bytes.NewReader([]byte(`{}`))
+	} else {
+		body = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRouteTiers(t *testing.T) {
+	router, issuer := newTestRouter(t, nil)
+	customer := issue(t, issuer, 7, "customer")
+	manager := issue(t, issuer, 8, "manager")
+	const invalid = "not-a-jwt"
+
+	cases := []struct {
+		tier   string
+		method string
+		target string
+		token  string
+		status int
+	}{
+		// Публичные маршруты не смотрят на токен
+		{"public", http.MethodPost, "/api/auth/login", "", http.StatusBadRequest},
+		{"public", http.MethodPost, "/api/auth/login", invalid, http.StatusBadRequest},
+		{"public", http.MethodGet, "/feeds/yml", invalid, http.StatusServiceUnavailable},
+
+		// Каталог: гость и пользователь, неверный токен — как гость
+		{"optional", http.MethodGet, "/api/catalog/products", "", http.StatusOK},
+		{"optional", http.MethodGet, "/api/catalog/products", invalid, http.StatusOK},
+		{"optional", http.MethodGet, "/api/catalog/products", customer, http.StatusOK},
+
+		// Корзина: гость без заголовка, неверный токен — 401
+		{"guest", http.MethodGet, "/api/cart/", "", http.StatusOK},
+		{"guest", http.MethodGet, "/api/cart/", invalid, http.StatusUnauthorized},
+		{"guest", http.MethodGet, "/api/cart/", customer, http.StatusOK},
+
+		// Токен обязателен
+		{"required", http.MethodGet, "/api/auth/me", "", http.StatusUnauthorized},
+		{"required", http.MethodGet, "/api/orders/", "", http.StatusUnauthorized},
+		{"required", http.MethodGet, "/api/orders/", invalid, http.StatusUnauthorized},
+		{"required", http.MethodGet, "/api/orders/", customer, http.StatusOK},
+
+		// Служебные маршруты: токен и право
+		{"staff", http.MethodGet, "/api/staff/orders/", "", http.StatusUnauthorized},
+		{"staff", http.MethodGet, "/api/staff/orders/", customer, http.StatusForbidden},
+		{"staff", http.MethodGet, "/api/staff/orders/", manager, http.StatusOK},
+	}
+	for _, tc := range cases {
+		rec := call(router, tc.method, tc.target, tc.token, nil)
+		if rec.Code != tc.status {
+			t.Errorf("%s %s %s (token %.10q): status = %d, want %d: %s", tc.tier, tc.method, tc.target, tc.token, rec.Code, tc.status, rec.Body)
+		}
+	}
+}
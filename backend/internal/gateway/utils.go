@@ -1,9 +1,44 @@
 // internal/gateway/utils.go
 package gateway
 
-import "strings"
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
 
 // split разделяет строку по разделителю и возвращает массив строк
 func split(s, sep string, n int) []string {
 	return strings.SplitN(s, sep, n)
 }
+
+// parseSameSite переводит значение SameSite из конфига в режим cookie
+func parseSameSite(value string) (http.SameSite, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return 0, fmt.Errorf("некорректный SameSite для cookie: %q", value)
+	}
+}
+
+// validateTrustedProxies проверяет, что каждый доверенный прокси — IP или подсеть CIDR
+func validateTrustedProxies(proxies []string) error {
+	for _, proxy := range proxies {
+		if strings.Contains(proxy, "/") {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("некорректный TRUSTED_PROXIES: %w", err)
+			}
+			continue
+		}
+		if net.ParseIP(proxy) == nil {
+			return fmt.Errorf("некорректный TRUSTED_PROXIES: %q не IP-адрес", proxy)
+		}
+	}
+	return nil
+}
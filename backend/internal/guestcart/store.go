@@ -0,0 +1,259 @@
+// internal/guestcart/store.go
+package guestcart
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cart — корзина гостя, хранится на стороне шлюза до входа в аккаунт
+type Cart struct {
+	Items     []Item    `json:"items"`
+	NextID    int       `json:"nextId"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Item — строка гостевой корзины
+type Item struct {
+	ID        string    `json:"id"`
+	ProductID int       `json:"productId"`
+	Quantity  int       `json:"quantity"`
+	Size      string    `json:"size,omitempty"`
+	Color     string    `json:"color,omitempty"`
+	Price     int       `json:"price,omitempty"` // цена, которую гость видел при последнем изменении строки
+	AddedAt   time.Time `json:"addedAt"`
+}
+
+// Find возвращает строку с товаром в данном размере и цвете или nil
+func (c *Cart) Find(productID int, size, color string) *Item {
+	for i := range c.Items {
+		item := &c.Items[i]
+		if item.ProductID == productID && item.Size == size && item.Color == color {
+			return item
+		}
+	}
+	return nil
+}
+
+// Add добавляет пустую строку для товара в данном размере и цвете и возвращает её
+func (c *Cart) Add(productID int, size, color string) *Item {
+	c.NextID++
+	c.Items = append(c.Items, Item{
+		ID:        "g" + strconv.Itoa(c.NextID),
+		ProductID: productID,
+		Size:      size,
+		Color:     color,
+		AddedAt:   time.Now(),
+	})
+	return &c.Items[len(c.Items)-1]
+}
+
+// Remove убирает строку по её идентификатору
+func (c *Cart) Remove(id string) bool {
+	for i := range c.Items {
+		if c.Items[i].ID == id {
+			c.Items = append(c.Items[:i], c.Items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Store хранит гостевые корзины с ограниченным сроком жизни
+type Store interface {
+	// Get возвращает корзину или nil, если её нет или срок истёк
+	Get(ctx context.Context, id string) (*Cart, error)
+	// Update читает корзину (пустую, если её нет), передаёт её в fn и сохраняет
+	// с новым сроком жизни. Ошибка из fn отменяет сохранение.
+	Update(ctx context.Context, id string, ttl time.Duration, fn func(*Cart) error) (*Cart, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore хранит корзины в памяти одного экземпляра шлюза
+type MemoryStore struct {
+	mu    sync.Mutex
+	carts map[string]memoryCart
+}
+
+type memoryCart struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{carts: make(map[string]memoryCart)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.loadLocked(id, time.Now())
+}
+
+func (s *MemoryStore) Update(_ context.Context, id string, ttl time.Duration, fn func(*Cart) error) (*Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	cart, err := s.loadLocked(id, now)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		cart = &Cart{}
+	}
+	if err := fn(cart); err != nil {
+		return nil, err
+	}
+	cart.UpdatedAt = now
+
+	// Храним копию в JSON, чтобы вызывающий не менял корзину в обход Update
+	data, err := json.Marshal(cart)
+	if err != nil {
+		return nil, err
+	}
+	s.carts[id] = memoryCart{data: data, expiresAt: now.Add(ttl)}
+	return cart, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.carts, id)
+	return nil
+}
+
+func (s *MemoryStore) loadLocked(id string, now time.Time) (*Cart, error) {
+	stored, ok := s.carts[id]
+	if !ok || now.After(stored.expiresAt) {
+		return nil, nil
+	}
+	var cart Cart
+	if err := json.Unmarshal(stored.data, &cart); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// Run периодически удаляет истёкшие корзины, пока не отменён ctx. Очистка
+// не делается при каждом Update: проход по всем корзинам под общей
+// блокировкой замедлял бы каждое изменение корзины.
+func (s *MemoryStore) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.sweep(now)
+		}
+	}
+}
+
+// sweep удаляет корзины, срок жизни которых истёк к моменту now
+func (s *MemoryStore) sweep(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, stored := range s.carts {
+		if now.After(stored.expiresAt) {
+			delete(s.carts, id)
+		}
+	}
+}
+
+// maxUpdateAttempts — сколько раз повторять Update при параллельном изменении корзины
+const maxUpdateAttempts = 5
+
+// RedisStore хранит корзины в Redis, чтобы гость видел одну корзину на всех репликах
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *RedisStore) Get(ctx context.Context, id string) (*Cart, error) {
+	return s.load(ctx, s.client, s.prefix+id)
+}
+
+// Update применяет fn в оптимистичной транзакции WATCH/MULTI
+// и повторяет её, если корзину изменил параллельный запрос
+func (s *RedisStore) Update(ctx context.Context, id string, ttl time.Duration, fn func(*Cart) error) (*Cart, error) {
+	key := s.prefix + id
+
+	var result *Cart
+	txf := func(tx *redis.Tx) error {
+		cart, err := s.load(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		if cart == nil {
+			cart = &Cart{}
+		}
+		if err := fn(cart); err != nil {
+			return err
+		}
+		cart.UpdatedAt = time.Now()
+
+		data, err := json.Marshal(cart)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, data, ttl)
+			return nil
+		})
+		if err == nil {
+			result = cart
+		}
+		return err
+	}
+
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		err := s.client.Watch(ctx, txf, key)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("гостевая корзина %s: слишком много параллельных изменений", id)
+}
+
+func (s *RedisStore) Delete(ctx context.Context, id string) error {
+	return s.client.Del(ctx, s.prefix+id).Err()
+}
+
+func (s *RedisStore) load(ctx context.Context, client redis.Cmdable, key string) (*Cart, error) {
+	data, err := client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("чтение гостевой корзины: %w", err)
+	}
+	var cart Cart
+	if err := json.Unmarshal(data, &cart); err != nil {
+		return nil, fmt.Errorf("разбор гостевой корзины: %w", err)
+	}
+	return &cart, nil
+}
@@ -0,0 +1,43 @@
+// internal/guestcart/store_test.go
+package guestcart
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreSweepRemovesExpiredCarts(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	add := func(cart *Cart) error {
+		cart.Add(1, "", "").Quantity = 1
+		return nil
+	}
+	if _, err := store.Update(ctx, "old", time.Minute, add); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Update(ctx, "new", time.Hour, add); err != nil {
+		t.Fatal(err)
+	}
+
+	store.sweep(time.Now().Add(2 * time.Minute))
+
+	if len(store.carts) != 1 {
+		t.Fatalf("carts = %d, want 1", len(store.carts))
+	}
+	if cart, err := store.Get(ctx, "new"); err != nil || cart == nil {
+		t.Fatalf("live cart: %v, %v", cart, err)
+	}
+}
+
+func TestMemoryStoreGetIgnoresExpiredBeforeSweep(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	if _, err := store.Update(ctx, "gone", -time.Second, func(*Cart) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if cart, err := store.Get(ctx, "gone"); err != nil || cart != nil {
+		t.Fatalf("expired cart: %v, %v, want nil", cart, err)
+	}
+}
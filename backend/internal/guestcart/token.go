@@ -0,0 +1,60 @@
+// internal/guestcart/token.go
+package guestcart
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// idBytes — длина случайного идентификатора корзины
+const idBytes = 18
+
+// Signer выпускает и проверяет токены гостевых корзин вида "<id>.<подпись>".
+// Подпись не даёт подобрать чужую корзину перебором идентификаторов.
+type Signer struct {
+	key []byte
+}
+
+// NewSigner создаёт подписчик; без секрета ключ генерируется случайно,
+// и токены перестают действовать после перезапуска
+func NewSigner(secret []byte) (*Signer, error) {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+	}
+	return &Signer{key: secret}, nil
+}
+
+// NewToken создаёт новую корзину: возвращает её идентификатор и токен для клиента
+func (s *Signer) NewToken() (id, token string, err error) {
+	raw := make([]byte, idBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	id = base64.RawURLEncoding.EncodeToString(raw)
+	return id, id + "." + s.sign(id), nil
+}
+
+// Verify возвращает идентификатор корзины, если подпись токена верна
+func (s *Signer) Verify(token string) (string, bool) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok || id == "" || len(id) > 64 {
+		return "", false
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(id))) {
+		return "", false
+	}
+	return id, true
+}
+
+func (s *Signer) sign(id string) string {
+	// Префикс отделяет эти подписи от JWT, если секрет общий
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte("guest-cart:" + id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
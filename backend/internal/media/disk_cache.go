@@ -0,0 +1,175 @@
+// internal/media/disk_cache.go
+package media
+
+import (
+	"container/list"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiskCache хранит готовые изображения в файлах и удаляет давно
+// не запрашиваемые, когда суммарный размер превышает лимит.
+// Порядок использования ведётся в памяти и восстанавливается
+// при старте по времени изменения файлов.
+type DiskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	size  int64
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type diskEntry struct {
+	key  string
+	path string
+	size int64
+}
+
+// NewDiskCache открывает каталог кеша и учитывает уже лежащие в нём файлы
+func NewDiskCache(dir string, maxBytes int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	c := &DiskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		ll:       list.New(),
+		items:    map[string]*list.Element{},
+	}
+
+	type found struct {
+		entry   diskEntry
+		modTime time.Time
+	}
+	var files []found
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		name := d.Name()
+		// Недописанные временные файлы остаются после аварийной остановки
+		if strings.HasPrefix(name, ".tmp-") {
+			os.Remove(path)
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		key := strings.TrimSuffix(name, filepath.Ext(name))
+		files = append(files, found{diskEntry{key: key, path: path, size: info.Size()}, info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+	for _, f := range files {
+		entry := f.entry
+		c.items[entry.key] = c.ll.PushBack(&entry)
+		c.size += entry.size
+	}
+	c.mu.Lock()
+	c.evict()
+	c.mu.Unlock()
+
+	return c, nil
+}
+
+// Lookup возвращает путь к файлу по ключу и отмечает его использование
+func (c *DiskCache) Lookup(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*diskEntry).path, true
+}
+
+// Forget убирает запись о файле, которого уже нет на диске. Если файл
+// успели записать заново, запись остаётся.
+func (c *DiskCache) Forget(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return
+	}
+	if _, err := os.Stat(el.Value.(*diskEntry).path); err == nil {
+		return
+	}
+	c.size -= el.Value.(*diskEntry).size
+	c.ll.Remove(el)
+	delete(c.items, key)
+}
+
+// Put записывает файл через временный и переименование, чтобы
+// читатели не увидели его недописанным, и освобождает место под лимит
+func (c *DiskCache) Put(key, ext string, write func(io.Writer) error) (string, error) {
+	shard := filepath.Join(c.dir, key[:2])
+	if err := os.MkdirAll(shard, 0o755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(shard, ".tmp-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(shard, key+ext)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.size -= el.Value.(*diskEntry).size
+		c.ll.Remove(el)
+	}
+	c.items[key] = c.ll.PushFront(&diskEntry{key: key, path: path, size: info.Size()})
+	c.size += info.Size()
+	c.evict()
+
+	return path, nil
+}
+
+// evict удаляет самые давние файлы, пока кеш больше лимита; вызывается под mu
+func (c *DiskCache) evict() {
+	for c.maxBytes > 0 && c.size > c.maxBytes && c.ll.Len() > 1 {
+		el := c.ll.Back()
+		entry := el.Value.(*diskEntry)
+		c.ll.Remove(el)
+		delete(c.items, entry.key)
+		c.size -= entry.size
+		os.Remove(entry.path)
+	}
+}
@@ -0,0 +1,118 @@
+// internal/media/disk_cache_test.go
+package media
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func putBytes(t *testing.T, c *DiskCache, key string, size int) string {
+	t.Helper()
+	path, err := c.Put(key, ".png", func(w io.Writer) error {
+		_, err := w.Write([]byte(strings.Repeat("x", size)))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestDiskCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir(), 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := putBytes(t, c, "aa01", 100)
+	b := putBytes(t, c, "bb02", 100)
+	putBytes(t, c, "cc03", 100)
+
+	// Обращение к a делает самым давним b
+	if _, ok := c.Lookup("aa01"); !ok {
+		t.Fatal("aa01 missing")
+	}
+	putBytes(t, c, "dd04", 100)
+
+	if _, ok := c.Lookup("bb02"); ok || exists(b) {
+		t.Fatal("bb02 should be evicted")
+	}
+	for _, key := range []string{"aa01", "cc03", "dd04"} {
+		if _, ok := c.Lookup(key); !ok {
+			t.Fatalf("%s evicted", key)
+		}
+	}
+	if !exists(a) {
+		t.Fatal("aa01 removed from disk")
+	}
+	if c.size != 300 {
+		t.Fatalf("size = %d, want 300", c.size)
+	}
+}
+
+func TestDiskCacheRestoresFromDisk(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := putBytes(t, c, "aa01", 100)
+	fresh := putBytes(t, c, "bb02", 100)
+
+	now := time.Now()
+	os.Chtimes(old, now.Add(-time.Hour), now.Add(-time.Hour))
+	os.Chtimes(fresh, now, now)
+	tmp := filepath.Join(dir, "aa", ".tmp-123")
+	if err := os.WriteFile(tmp, []byte("partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Новый лимит вмещает один файл: остаётся изменённый последним
+	restored, err := NewDiskCache(dir, 150)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists(tmp) {
+		t.Fatal("temp file not removed")
+	}
+	if path, ok := restored.Lookup("bb02"); !ok || path != fresh {
+		t.Fatalf("bb02 = %q, %v", path, ok)
+	}
+	if _, ok := restored.Lookup("aa01"); ok || exists(old) {
+		t.Fatal("aa01 should be evicted on restore")
+	}
+	if restored.size != 100 {
+		t.Fatalf("size = %d, want 100", restored.size)
+	}
+}
+
+func TestDiskCacheForgetKeepsRewrittenFile(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := putBytes(t, c, "aa01", 100)
+
+	c.Forget("aa01")
+	if _, ok := c.Lookup("aa01"); !ok {
+		t.Fatal("existing file forgotten")
+	}
+
+	os.Remove(path)
+	c.Forget("aa01")
+	if _, ok := c.Lookup("aa01"); ok {
+		t.Fatal("missing file still cached")
+	}
+	if c.size != 0 {
+		t.Fatalf("size = %d, want 0", c.size)
+	}
+}
@@ -0,0 +1,188 @@
+// internal/media/params.go
+package media
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Режимы вписывания в заданные размеры
+const (
+	// FitContain — уменьшить, сохранив пропорции, чтобы картинка поместилась в рамку
+	FitContain = "contain"
+	// FitCover — заполнить рамку целиком, обрезав лишнее по центру
+	FitCover = "cover"
+	// FitFill — растянуть ровно до заданных размеров без сохранения пропорций
+	FitFill = "fill"
+)
+
+// Форматы результата
+const (
+	FormatOriginal = ""
+	FormatJPEG     = "jpeg"
+	FormatPNG      = "png"
+	FormatWebP     = "webp"
+)
+
+// Params — параметры преобразования из строки запроса
+type Params struct {
+	Width   int
+	Height  int
+	Fit     string
+	Quality int
+	Format  string
+}
+
+// Limits ограничивают параметры, чтобы запросы не могли загрузить сервер.
+// Размеры округляются вверх до ближайшего из Widths, качество — до ближайшего
+// из Qualities: иначе перебор значений заполняет дисковый кеш почти
+// одинаковыми копиями одной картинки.
+type Limits struct {
+	MaxDimension   int
+	DefaultQuality int
+	Widths         []int
+	Qualities      []int
+}
+
+// ParseParams читает width, height, fit, quality и format. format=auto
+// (по умолчанию при изменении размера) выбирает WebP, если клиент его
+// принимает и кодировщик доступен, иначе JPEG.
+func ParseParams(query url.Values, accept string, webp bool, limits Limits) (Params, error) {
+	p := Params{Fit: FitContain, Quality: limits.DefaultQuality}
+
+	var err error
+	if p.Width, err = dimension(query, "width", limits.MaxDimension); err != nil {
+		return p, err
+	}
+	if p.Height, err = dimension(query, "height", limits.MaxDimension); err != nil {
+		return p, err
+	}
+
+	if fit := query.Get("fit"); fit != "" {
+		switch fit {
+		case FitContain, FitCover, FitFill:
+			p.Fit = fit
+		default:
+			return p, fmt.Errorf("параметр fit должен быть contain, cover или fill")
+		}
+	}
+
+	if quality := query.Get("quality"); quality != "" {
+		q, err := strconv.Atoi(quality)
+		if err != nil || q < 1 || q > 100 {
+			return p, fmt.Errorf("параметр quality должен быть числом от 1 до 100")
+		}
+		p.Quality = q
+	}
+
+	p.Width = snapUp(p.Width, limits.Widths, limits.MaxDimension)
+	p.Height = snapUp(p.Height, limits.Widths, limits.MaxDimension)
+	p.Quality = snapNearest(p.Quality, limits.Qualities)
+
+	format := query.Get("format")
+	if format == "" && p.Resized() {
+		format = "auto"
+	}
+	switch format {
+	case "":
+	case "auto":
+		p.Format = FormatJPEG
+		if webp && strings.Contains(accept, "image/webp") {
+			p.Format = FormatWebP
+		}
+	case "jpeg", "jpg":
+		p.Format = FormatJPEG
+	case FormatPNG:
+		p.Format = FormatPNG
+	case FormatWebP:
+		if !webp {
+			return p, fmt.Errorf("формат webp недоступен на этом сервере")
+		}
+		p.Format = FormatWebP
+	default:
+		return p, fmt.Errorf("параметр format должен быть auto, webp, jpeg или png")
+	}
+
+	return p, nil
+}
+
+// Resized сообщает, задан ли хотя бы один размер
+func (p Params) Resized() bool {
+	return p.Width > 0 || p.Height > 0
+}
+
+// Original — отдавать исходный файл без преобразований
+func (p Params) Original() bool {
+	return !p.Resized() && p.Format == FormatOriginal
+}
+
+// cacheKey — канонический вид параметров для ключа дискового кеша
+func (p Params) cacheKey() string {
+	if p.Original() {
+		return "original"
+	}
+	return fmt.Sprintf("w%d-h%d-%s-q%d-%s", p.Width, p.Height, p.Fit, p.Quality, p.Format)
+}
+
+func dimension(query url.Values, name string, max int) (int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > max {
+		return 0, fmt.Errorf("параметр %s должен быть числом от 1 до %d", name, max)
+	}
+	return n, nil
+}
+
+// snapUp округляет размер вверх до ближайшего шага не больше max.
+// Размер больше всех шагов сводится к наибольшему из них.
+func snapUp(n int, steps []int, max int) int {
+	if n == 0 {
+		return 0
+	}
+	best, largest := 0, 0
+	for _, step := range steps {
+		if step > max {
+			continue
+		}
+		if step >= n && (best == 0 || step < best) {
+			best = step
+		}
+		if step > largest {
+			largest = step
+		}
+	}
+	switch {
+	case best > 0:
+		return best
+	case largest > 0:
+		return largest
+	default:
+		return n
+	}
+}
+
+// snapNearest сводит значение к ближайшему шагу; при равенстве берётся больший
+func snapNearest(n int, steps []int) int {
+	best := 0
+	for _, step := range steps {
+		if best == 0 || abs(step-n) < abs(best-n) || (abs(step-n) == abs(best-n) && step > best) {
+			best = step
+		}
+	}
+	if best == 0 {
+		return n
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
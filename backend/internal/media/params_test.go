@@ -0,0 +1,98 @@
+// internal/media/params_test.go
+package media
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+var testLimits = Limits{
+	MaxDimension:   2560,
+	DefaultQuality: 80,
+	Widths:         []int{128, 256, 512, 1024, 2560},
+	Qualities:      []int{50, 65, 80, 90},
+}
+
+func parse(t *testing.T, raw string) Params {
+	t.Helper()
+	query, err := url.ParseQuery(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := ParseParams(query, "image/webp", true, testLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestParseParamsSnapsToConfiguredSteps(t *testing.T) {
+	cases := []struct {
+		query string
+		want  Params
+	}{
+		{"width=300", Params{Width: 512, Fit: FitContain, Quality: 80, Format: FormatWebP}},
+		{"width=256&height=100", Params{Width: 256, Height: 128, Fit: FitContain, Quality: 80, Format: FormatWebP}},
+		{"width=2000&quality=72", Params{Width: 2560, Fit: FitContain, Quality: 65, Format: FormatWebP}},
+		{"height=1&quality=100&format=png", Params{Height: 128, Fit: FitContain, Quality: 90, Format: FormatPNG}},
+		{"quality=1", Params{Fit: FitContain, Quality: 50}},
+	}
+	for _, tc := range cases {
+		if got := parse(t, tc.query); got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestCacheKeyCollapsesNearbyRequests(t *testing.T) {
+	keys := map[string]bool{}
+	for width := 257; width <= 512; width++ {
+		for quality := 73; quality <= 100; quality++ {
+			query := url.Values{}
+			query.Set("width", strconv.Itoa(width))
+			query.Set("quality", strconv.Itoa(quality))
+			p, err := ParseParams(query, "", false, testLimits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys[p.cacheKey()] = true
+		}
+	}
+	if len(keys) != 2 {
+		t.Fatalf("got %d cache keys, want 2: %v", len(keys), keys)
+	}
+}
+
+func TestParseParamsWithoutStepsKeepsValues(t *testing.T) {
+	query := url.Values{"width": {"301"}, "quality": {"77"}}
+	p, err := ParseParams(query, "", false, Limits{MaxDimension: 2560, DefaultQuality: 80})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Width != 301 || p.Quality != 77 {
+		t.Fatalf("got %+v", p)
+	}
+}
+
+func TestNewRewriterRequiresAbsoluteURL(t *testing.T) {
+	if r, err := NewRewriter("", "http://strapi:1337"); r != nil || err != nil {
+		t.Fatalf("empty prefix: %v, %v, want disabled", r, err)
+	}
+	for _, prefix := range []string{"/media", "media", "ftp://cdn/media"} {
+		if _, err := NewRewriter(prefix, "http://strapi:1337"); err == nil {
+			t.Errorf("%q accepted, want error", prefix)
+		}
+	}
+
+	r, err := NewRewriter("https://api.example.com/media/", "http://strapi:1337")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.URL("http://strapi:1337/uploads/a.jpg"); got != "https://api.example.com/media/uploads/a.jpg" {
+		t.Fatalf("got %q", got)
+	}
+	if got := r.URL("https://cdn.example.com/a.jpg"); got != "https://cdn.example.com/a.jpg" {
+		t.Fatalf("external url rewritten: %q", got)
+	}
+}
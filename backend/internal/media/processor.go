@@ -0,0 +1,278 @@
+// internal/media/processor.go
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxPixels защищает от «бомб» — маленьких файлов с огромным разрешением
+const maxPixels = 50_000_000
+
+var (
+	// ErrNotFound — исходного файла нет в Strapi
+	ErrNotFound = errors.New("media: файл не найден")
+	// ErrUnsupported — файл не является поддерживаемым изображением
+	ErrUnsupported = errors.New("media: неподдерживаемый формат")
+	// ErrTooLarge — исходник превышает лимиты по размеру файла или разрешению
+	ErrTooLarge = errors.New("media: слишком большое изображение")
+)
+
+// contentTypes — допустимые исходные типы и расширения файлов в кеше
+var contentTypes = map[string]string{
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/webp":    ".webp",
+	"image/gif":     ".gif",
+	"image/svg+xml": ".svg",
+}
+
+var formatTypes = map[string]string{
+	FormatJPEG: "image/jpeg",
+	FormatPNG:  "image/png",
+	FormatWebP: "image/webp",
+}
+
+// Config — настройки прокси изображений
+type Config struct {
+	// Origin — адрес Strapi, откуда берутся файлы /uploads
+	Origin    string
+	CacheDir  string
+	CacheSize int64
+	Limits    Limits
+	// CWebP — имя или путь к cwebp; если не найден, WebP не предлагается
+	CWebP          string
+	MaxSourceBytes int64
+	Timeout        time.Duration
+}
+
+// Result — готовый файл в дисковом кеше
+type Result struct {
+	Path        string
+	ContentType string
+}
+
+// Processor скачивает загрузки Strapi, преобразует их и кладёт в дисковый кеш.
+// Одинаковые запросы объединяются, число одновременных преобразований
+// ограничено числом процессоров.
+type Processor struct {
+	cfg        Config
+	cwebp      string
+	httpClient *http.Client
+	cache      *DiskCache
+	sem        chan struct{}
+
+	mu       sync.Mutex
+	inflight map[string]*call
+}
+
+type call struct {
+	done   chan struct{}
+	result *Result
+	err    error
+}
+
+func NewProcessor(cfg Config) (*Processor, error) {
+	cache, err := NewDiskCache(cfg.CacheDir, cfg.CacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("media: кеш %s: %w", cfg.CacheDir, err)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	p := &Processor{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		cache:      cache,
+		sem:        make(chan struct{}, runtime.NumCPU()),
+		inflight:   map[string]*call{},
+	}
+	if cfg.CWebP != "" {
+		if path, err := exec.LookPath(cfg.CWebP); err == nil {
+			p.cwebp = path
+		}
+	}
+	return p, nil
+}
+
+// WebP сообщает, доступно ли кодирование в WebP
+func (p *Processor) WebP() bool {
+	return p.cwebp != ""
+}
+
+// CleanPath проверяет путь из URL: проксируются только загрузки Strapi
+func CleanPath(raw string) (string, bool) {
+	clean := path.Clean("/" + strings.TrimLeft(raw, "/"))
+	if !strings.HasPrefix(clean, "/uploads/") {
+		return "", false
+	}
+	return clean, true
+}
+
+// Get возвращает файл для пути загрузки и параметров, готовя его при промахе кеша
+func (p *Processor) Get(ctx context.Context, uploadPath string, params Params) (*Result, error) {
+	key := p.key(uploadPath, params)
+
+	if file, ok := p.cache.Lookup(key); ok {
+		return &Result{Path: file, ContentType: mime.TypeByExtension(filepath.Ext(file))}, nil
+	}
+
+	p.mu.Lock()
+	if c, ok := p.inflight[key]; ok {
+		p.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.result, c.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c := &call{done: make(chan struct{})}
+	p.inflight[key] = c
+	p.mu.Unlock()
+
+	// Подготовка не зависит от клиента: если он ушёл, результат
+	// всё равно пригодится следующим запросам
+	prepareCtx, cancel := context.WithTimeout(context.Background(), 2*p.cfg.Timeout)
+	c.result, c.err = p.prepare(prepareCtx, key, uploadPath, params)
+	cancel()
+
+	p.mu.Lock()
+	delete(p.inflight, key)
+	p.mu.Unlock()
+	close(c.done)
+
+	return c.result, c.err
+}
+
+// Open возвращает открытый файл для пути загрузки и параметров. Файл из кеша
+// может быть вытеснен между Get и открытием — тогда он готовится заново.
+func (p *Processor) Open(ctx context.Context, uploadPath string, params Params) (*os.File, *Result, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := p.Get(ctx, uploadPath, params)
+		if err != nil {
+			return nil, nil, err
+		}
+		file, err := os.Open(result.Path)
+		if err == nil {
+			return file, result, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) || attempt > 0 {
+			return nil, nil, err
+		}
+		// Файл мог удалить и не сам кеш — запись о нём больше не нужна
+		p.cache.Forget(p.key(uploadPath, params))
+	}
+}
+
+func (p *Processor) key(uploadPath string, params Params) string {
+	sum := sha256.Sum256([]byte(uploadPath + "|" + params.cacheKey()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (p *Processor) prepare(ctx context.Context, key, uploadPath string, params Params) (*Result, error) {
+	data, contentType, err := p.fetch(ctx, uploadPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Векторные и анимированные изображения не преобразуются
+	if params.Original() || contentType == "image/svg+xml" || contentType == "image/gif" {
+		return p.store(key, contentType, func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		})
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	select {
+	case p.sem <- struct{}{}:
+		defer func() { <-p.sem }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	img = resize(img, params)
+
+	return p.store(key, formatTypes[params.Format], func(w io.Writer) error {
+		return encode(ctx, w, img, params, p.cwebp)
+	})
+}
+
+func (p *Processor) store(key, contentType string, write func(io.Writer) error) (*Result, error) {
+	file, err := p.cache.Put(key, contentTypes[contentType], write)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Path: file, ContentType: contentType}, nil
+}
+
+// fetch скачивает исходник из Strapi с ограничением размера
+func (p *Processor) fetch(ctx context.Context, uploadPath string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(p.cfg.Origin, "/")+uploadPath, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("media: %s: %w", uploadPath, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, "", ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", fmt.Errorf("media: %s: статус %d", uploadPath, resp.StatusCode)
+	}
+
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if _, ok := contentTypes[contentType]; !ok {
+		return nil, "", ErrUnsupported
+	}
+	if p.cfg.MaxSourceBytes > 0 && resp.ContentLength > p.cfg.MaxSourceBytes {
+		return nil, "", ErrTooLarge
+	}
+
+	limit := p.cfg.MaxSourceBytes
+	if limit <= 0 {
+		limit = 1 << 62
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("media: %s: %w", uploadPath, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, "", ErrTooLarge
+	}
+	return data, contentType, nil
+}
@@ -0,0 +1,239 @@
+// internal/media/processor_test.go
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// origin — Strapi с файлами /uploads, считающий обращения
+type origin struct {
+	*httptest.Server
+	hits  atomic.Int32
+	gate  chan struct{}
+	files map[string]originFile
+}
+
+type originFile struct {
+	contentType string
+	body        []byte
+}
+
+func newOrigin(t *testing.T, files map[string]originFile) *origin {
+	t.Helper()
+	o := &origin{files: files}
+	o.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		o.hits.Add(1)
+		if o.gate != nil {
+			<-o.gate
+		}
+		f, ok := o.files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", f.contentType)
+		w.Write(f.body)
+	}))
+	t.Cleanup(o.Close)
+	return o
+}
+
+func newTestProcessor(t *testing.T, o *origin, maxSource int64) *Processor {
+	t.Helper()
+	p, err := NewProcessor(Config{
+		Origin:         o.URL,
+		CacheDir:       t.TempDir(),
+		CacheSize:      1 << 20,
+		Limits:         testLimits,
+		MaxSourceBytes: maxSource,
+		Timeout:        5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func pngBytes(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pngHeader — начало PNG с заявленными размерами: DecodeConfig читает
+// только IHDR, поэтому огромную картинку не нужно создавать целиком
+func pngHeader(w, h uint32) []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], w)
+	binary.BigEndian.PutUint32(ihdr[4:], h)
+	ihdr[8], ihdr[9] = 8, 2
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	chunk := append([]byte("IHDR"), ihdr...)
+	buf.Write(chunk)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+func TestGetResizesAndCaches(t *testing.T) {
+	o := newOrigin(t, map[string]originFile{
+		"/uploads/a.png": {"image/png", pngBytes(t, 400, 200)},
+	})
+	p := newTestProcessor(t, o, 0)
+	params := Params{Width: 128, Fit: FitContain, Quality: 80, Format: FormatPNG}
+
+	for i := 0; i < 2; i++ {
+		result, err := p.Get(context.Background(), "/uploads/a.png", params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ContentType != "image/png" {
+			t.Fatalf("content type %q", result.ContentType)
+		}
+		file, err := os.Open(result.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := png.DecodeConfig(file)
+		file.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Width != 128 || cfg.Height != 64 {
+			t.Fatalf("size %dx%d, want 128x64", cfg.Width, cfg.Height)
+		}
+	}
+	if hits := o.hits.Load(); hits != 1 {
+		t.Fatalf("origin hits = %d, want 1", hits)
+	}
+}
+
+func TestGetCoalescesConcurrentRequests(t *testing.T) {
+	o := newOrigin(t, map[string]originFile{
+		"/uploads/a.png": {"image/png", pngBytes(t, 64, 64)},
+	})
+	o.gate = make(chan struct{})
+	p := newTestProcessor(t, o, 0)
+	params := Params{Width: 32, Fit: FitContain, Quality: 80, Format: FormatPNG}
+
+	const n = 8
+	var wg sync.WaitGroup
+	paths := make([]string, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := p.Get(context.Background(), "/uploads/a.png", params)
+			errs[i] = err
+			if err == nil {
+				paths[i] = result.Path
+			}
+		}(i)
+	}
+
+	// Первый запрос дошёл до Strapi — ждём, пока остальные встанут в очередь
+	deadline := time.Now().Add(5 * time.Second)
+	for o.hits.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(o.gate)
+	wg.Wait()
+
+	for i := range errs {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		if paths[i] != paths[0] {
+			t.Fatalf("paths differ: %q vs %q", paths[i], paths[0])
+		}
+	}
+	if hits := o.hits.Load(); hits != 1 {
+		t.Fatalf("origin hits = %d, want 1", hits)
+	}
+}
+
+func TestGetRejectsOversizedAndUnsupportedSources(t *testing.T) {
+	o := newOrigin(t, map[string]originFile{
+		"/uploads/big.png":   {"image/png", append(pngHeader(64, 64), make([]byte, 2048)...)},
+		"/uploads/huge.png":  {"image/png", pngHeader(10000, 10000)},
+		"/uploads/a.avif":    {"image/avif", []byte("avif")},
+		"/uploads/fake.jpg":  {"image/jpeg", []byte("not a jpeg")},
+		"/uploads/small.png": {"image/png", pngBytes(t, 4, 4)},
+	})
+	p := newTestProcessor(t, o, 1024)
+	params := Params{Width: 128, Fit: FitContain, Quality: 80, Format: FormatPNG}
+
+	cases := []struct {
+		path string
+		want error
+	}{
+		{"/uploads/big.png", ErrTooLarge},
+		{"/uploads/huge.png", ErrTooLarge},
+		{"/uploads/a.avif", ErrUnsupported},
+		{"/uploads/fake.jpg", ErrUnsupported},
+		{"/uploads/missing.png", ErrNotFound},
+		{"/uploads/small.png", nil},
+	}
+	for _, tc := range cases {
+		_, err := p.Get(context.Background(), tc.path, params)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.path, err, tc.want)
+		}
+	}
+}
+
+func TestOpenRegeneratesEvictedFile(t *testing.T) {
+	o := newOrigin(t, map[string]originFile{
+		"/uploads/a.png": {"image/png", pngBytes(t, 64, 64)},
+	})
+	p := newTestProcessor(t, o, 0)
+	params := Params{Width: 32, Fit: FitContain, Quality: 80, Format: FormatPNG}
+
+	result, err := p.Get(context.Background(), "/uploads/a.png", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Так файл пропадает, если его вытеснил параллельный Put
+	if err := os.Remove(result.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	file, reopened, err := p.Open(context.Background(), "/uploads/a.png", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	if reopened.Path != result.Path {
+		t.Fatalf("path %q, want %q", reopened.Path, result.Path)
+	}
+	if hits := o.hits.Load(); hits != 2 {
+		t.Fatalf("origin hits = %d, want 2", hits)
+	}
+}
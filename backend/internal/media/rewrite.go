@@ -0,0 +1,69 @@
+// internal/media/rewrite.go
+package media
+
+import (
+	"backend/internal/strapi"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Rewriter переводит ссылки на загрузки Strapi в ссылки на прокси изображений,
+// чтобы клиенты могли запрашивать нужный размер и формат.
+// Ссылки на внешние хранилища остаются без изменений.
+type Rewriter struct {
+	prefix string
+	origin string
+}
+
+// NewRewriter возвращает nil, если prefix пуст — тогда ссылки не переписываются.
+// prefix должен быть абсолютным URL: клиенты на другом домене, чем API,
+// не смогут загрузить относительную ссылку.
+func NewRewriter(prefix, origin string) (*Rewriter, error) {
+	if prefix == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(prefix)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("MEDIA_PUBLIC_URL должен быть абсолютным URL, например https://api.example.com/media: %q", prefix)
+	}
+	return &Rewriter{
+		prefix: strings.TrimRight(prefix, "/"),
+		origin: strings.TrimRight(origin, "/"),
+	}, nil
+}
+
+// URL переписывает одну ссылку
+func (r *Rewriter) URL(u string) string {
+	if r == nil || u == "" {
+		return u
+	}
+	if r.origin != "" && strings.HasPrefix(u, r.origin+"/") {
+		u = strings.TrimPrefix(u, r.origin)
+	}
+	if !strings.HasPrefix(u, "/uploads/") {
+		return u
+	}
+	return r.prefix + u
+}
+
+// Product переписывает ссылки на изображения товара, его форматы и связанные товары
+func (r *Rewriter) Product(product *strapi.Product) {
+	if r == nil {
+		return
+	}
+
+	product.ImageURL = r.URL(product.ImageURL)
+	for i := range product.Images {
+		image := &product.Images[i]
+		image.URL = r.URL(image.URL)
+		for _, format := range []*strapi.ImageFormat{image.Formats.Thumbnail, image.Formats.Small, image.Formats.Medium, image.Formats.Large} {
+			if format != nil {
+				format.URL = r.URL(format.URL)
+			}
+		}
+	}
+	for i := range product.Related {
+		r.Product(&product.Related[i])
+	}
+}
@@ -0,0 +1,139 @@
+// internal/media/transform.go
+package media
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	// Декодеры исходных форматов
+	_ "image/gif"
+
+	_ "golang.org/x/image/webp"
+
+	"golang.org/x/image/draw"
+)
+
+// resize масштабирует изображение по параметрам. Картинка никогда
+// не увеличивается: исходник меньше рамки отдаётся в своём размере.
+func resize(src image.Image, p Params) image.Image {
+	if !p.Resized() {
+		return src
+	}
+
+	bounds := src.Bounds()
+	sw, sh := float64(bounds.Dx()), float64(bounds.Dy())
+	if sw == 0 || sh == 0 {
+		return src
+	}
+
+	w, h := float64(p.Width), float64(p.Height)
+	fit := p.Fit
+	// Обрезать или растягивать можно только по двум сторонам
+	if w == 0 || h == 0 {
+		fit = FitContain
+	}
+
+	srcRect := bounds
+	switch fit {
+	case FitCover:
+		scale := math.Max(w/sw, h/sh)
+		if scale > 1 {
+			w, h, scale = w/scale, h/scale, 1
+		}
+		cw, ch := w/scale, h/scale
+		x0 := bounds.Min.X + int((sw-cw)/2)
+		y0 := bounds.Min.Y + int((sh-ch)/2)
+		srcRect = image.Rect(x0, y0, x0+int(math.Round(cw)), y0+int(math.Round(ch)))
+	case FitFill:
+		w, h = math.Min(w, sw), math.Min(h, sh)
+	default:
+		scale := math.Inf(1)
+		if w > 0 {
+			scale = w / sw
+		}
+		if h > 0 {
+			scale = math.Min(scale, h/sh)
+		}
+		if scale >= 1 {
+			return src
+		}
+		w, h = sw*scale, sh*scale
+	}
+
+	dw, dh := max(1, int(math.Round(w))), max(1, int(math.Round(h)))
+	if srcRect == bounds && dw == bounds.Dx() && dh == bounds.Dy() {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, srcRect, draw.Src, nil)
+	return dst
+}
+
+// encode записывает изображение в формате из параметров
+func encode(ctx context.Context, w io.Writer, img image.Image, p Params, cwebp string) error {
+	switch p.Format {
+	case FormatJPEG:
+		return jpeg.Encode(w, flatten(img), &jpeg.Options{Quality: p.Quality})
+	case FormatPNG:
+		return png.Encode(w, img)
+	case FormatWebP:
+		return encodeWebP(ctx, w, img, p.Quality, cwebp)
+	}
+	return fmt.Errorf("media: неизвестный формат %q", p.Format)
+}
+
+// flatten накладывает изображение на белый фон: в JPEG нет прозрачности
+func flatten(img image.Image) image.Image {
+	if _, ok := img.(*image.YCbCr); ok {
+		return img
+	}
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+	return dst
+}
+
+// encodeWebP кодирует через cwebp из libwebp: чисто Go-кодировщика
+// WebP с потерями нет, а cgo-обёртки усложняют сборку образа
+func encodeWebP(ctx context.Context, w io.Writer, img image.Image, quality int, cwebp string) error {
+	dir, err := os.MkdirTemp("", "media-webp-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	in, out := filepath.Join(dir, "in.png"), filepath.Join(dir, "out.webp")
+	var buf bytes.Buffer
+	// Для промежуточного файла важна скорость, а не размер
+	enc := png.Encoder{CompressionLevel: png.NoCompression}
+	if err := enc.Encode(&buf, img); err != nil {
+		return err
+	}
+	if err := os.WriteFile(in, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, cwebp, "-quiet", "-metadata", "none", "-q", fmt.Sprint(quality), in, "-o", out)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("media: cwebp: %w: %s", err, bytes.TrimSpace(output))
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
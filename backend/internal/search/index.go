@@ -0,0 +1,354 @@
+// internal/search/index.go
+package search
+
+import (
+	"backend/internal/strapi"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Веса полей при ранжировании: совпадение в названии важнее бренда,
+// бренд — важнее категории и описания
+const (
+	weightName        = 3.0
+	weightBrand       = 2.0
+	weightCategory    = 1.5
+	weightDescription = 1.0
+
+	// Множители для неточных совпадений
+	prefixFactor = 0.8
+	typoFactor   = 0.6
+	// phraseBonus — множитель, если название содержит запрос целиком
+	phraseBonus = 1.5
+)
+
+// Hit — найденный товар и его релевантность
+type Hit struct {
+	Product strapi.Product
+	Score   float64
+}
+
+// Index — полнотекстовый индекс товаров в памяти процесса.
+// Термы — основы слов после стемминга; исходные слова хранятся отдельно
+// для автодополнения по префиксу. Словарь перебирается целиком, что
+// приемлемо для каталога магазина в несколько десятков тысяч товаров.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*document
+	postings map[string]map[string]float64 // основа -> ключ товара -> вес
+	words    map[string]*word              // слово -> основа и частота
+	ready    bool
+}
+
+type document struct {
+	product strapi.Product
+	name    string
+	terms   map[string]float64
+	words   map[string]bool // слова, попавшие в словарь
+	suggest map[string]bool // слова названия и бренда для подсказок
+}
+
+type word struct {
+	stem    string
+	docs    int
+	suggest int
+}
+
+func NewIndex() *Index {
+	return &Index{
+		docs:     map[string]*document{},
+		postings: map[string]map[string]float64{},
+		words:    map[string]*word{},
+	}
+}
+
+// Key возвращает ключ товара в индексе: documentId, а для старых записей — id
+func Key(product strapi.Product) string {
+	if product.DocumentID != "" {
+		return product.DocumentID
+	}
+	return strconv.Itoa(product.ID)
+}
+
+// Ready сообщает, был ли индекс хотя бы раз построен целиком
+func (idx *Index) Ready() bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.ready
+}
+
+// Len возвращает число товаров в индексе
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Replace перестраивает индекс по полному списку товаров
+func (idx *Index) Replace(products []strapi.Product) {
+	fresh := NewIndex()
+	for _, product := range products {
+		fresh.add(product)
+	}
+
+	idx.mu.Lock()
+	idx.docs, idx.postings, idx.words = fresh.docs, fresh.postings, fresh.words
+	idx.ready = true
+	idx.mu.Unlock()
+}
+
+// Upsert добавляет товар или заменяет его прежнюю версию
+func (idx *Index) Upsert(product strapi.Product) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(Key(product))
+	idx.add(product)
+}
+
+// Remove убирает товары из индекса по ключам
+func (idx *Index) Remove(keys ...string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, key := range keys {
+		idx.remove(key)
+	}
+}
+
+func (idx *Index) add(product strapi.Product) {
+	key := Key(product)
+	doc := &document{
+		product: product,
+		name:    strings.Join(Tokenize(product.Name), " "),
+		terms:   map[string]float64{},
+		words:   map[string]bool{},
+		suggest: map[string]bool{},
+	}
+
+	fields := []struct {
+		text    string
+		weight  float64
+		suggest bool
+	}{
+		{product.Name, weightName, true},
+		{product.Brand.Name, weightBrand, true},
+		{strings.Join(product.Category.Path(), " "), weightCategory, false},
+		{product.Description, weightDescription, false},
+	}
+	for _, field := range fields {
+		seen := map[string]bool{}
+		for _, token := range Tokenize(field.text) {
+			if !indexable(token) {
+				continue
+			}
+			stem := Stem(token)
+			// Каждое поле добавляет вес терма один раз: повторы в описании
+			// не должны перевешивать одно упоминание в названии
+			if !seen[stem] {
+				seen[stem] = true
+				doc.terms[stem] += field.weight
+			}
+			doc.words[token] = true
+			if field.suggest {
+				doc.suggest[token] = true
+			}
+		}
+	}
+
+	for stem, weight := range doc.terms {
+		if idx.postings[stem] == nil {
+			idx.postings[stem] = map[string]float64{}
+		}
+		idx.postings[stem][key] = weight
+	}
+	for token := range doc.words {
+		w := idx.words[token]
+		if w == nil {
+			w = &word{stem: Stem(token)}
+			idx.words[token] = w
+		}
+		w.docs++
+		if doc.suggest[token] {
+			w.suggest++
+		}
+	}
+	idx.docs[key] = doc
+}
+
+func (idx *Index) remove(key string) {
+	doc, ok := idx.docs[key]
+	if !ok {
+		return
+	}
+	for stem := range doc.terms {
+		delete(idx.postings[stem], key)
+		if len(idx.postings[stem]) == 0 {
+			delete(idx.postings, stem)
+		}
+	}
+	for token := range doc.words {
+		w := idx.words[token]
+		w.docs--
+		if doc.suggest[token] {
+			w.suggest--
+		}
+		if w.docs <= 0 {
+			delete(idx.words, token)
+		}
+	}
+	delete(idx.docs, key)
+}
+
+// Search находит товары, содержащие все слова запроса. Слова сравниваются
+// по основам, последнее слово дополняется по префиксу, а при отсутствии
+// точного совпадения допускаются опечатки. Возвращает страницу результатов
+// и общее число найденных товаров.
+func (idx *Index) Search(query string, offset, limit int) ([]Hit, int) {
+	var tokens []string
+	for _, token := range Tokenize(query) {
+		if indexable(token) {
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, 0
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var scores map[string]float64
+	for i, token := range tokens {
+		matches := idx.matchToken(token, i == len(tokens)-1)
+		if scores == nil {
+			scores = matches
+		} else {
+			for key, score := range scores {
+				if extra, ok := matches[key]; ok {
+					scores[key] = score + extra
+				} else {
+					delete(scores, key)
+				}
+			}
+		}
+		if len(scores) == 0 {
+			return nil, 0
+		}
+	}
+
+	phrase := strings.Join(tokens, " ")
+	hits := make([]Hit, 0, len(scores))
+	for key, score := range scores {
+		doc := idx.docs[key]
+		if strings.Contains(doc.name, phrase) {
+			score *= phraseBonus
+		}
+		hits = append(hits, Hit{Product: doc.product, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Product.Name < hits[j].Product.Name
+	})
+
+	total := len(hits)
+	if offset >= total {
+		return nil, total
+	}
+	return hits[offset:min(offset+limit, total)], total
+}
+
+// matchToken возвращает вклад слова запроса в оценку каждого товара
+func (idx *Index) matchToken(token string, last bool) map[string]float64 {
+	stems := map[string]float64{}
+	stem := Stem(token)
+	if _, ok := idx.postings[stem]; ok {
+		stems[stem] = 1
+	}
+
+	if last {
+		for w, info := range idx.words {
+			if strings.HasPrefix(w, token) && stems[info.stem] < prefixFactor {
+				stems[info.stem] = prefixFactor
+			}
+		}
+	}
+
+	if len(stems) == 0 {
+		runes := []rune(stem)
+		if budget := typoBudget(len([]rune(token))); budget > 0 {
+			for candidate := range idx.postings {
+				if d := editDistance(runes, []rune(candidate), budget); d <= budget {
+					factor := typoFactor / float64(d)
+					if stems[candidate] < factor {
+						stems[candidate] = factor
+					}
+				}
+			}
+		}
+	}
+
+	scores := map[string]float64{}
+	for stem, factor := range stems {
+		postings := idx.postings[stem]
+		idf := math.Log(1 + float64(len(idx.docs))/float64(len(postings)))
+		for key, weight := range postings {
+			if score := factor * weight * idf; score > scores[key] {
+				scores[key] = score
+			}
+		}
+	}
+	return scores
+}
+
+// Suggest дополняет последнее слово запроса словами из названий и брендов,
+// начиная с самых частых, и возвращает готовые строки запроса
+func (idx *Index) Suggest(query string, limit int) []string {
+	tokens := Tokenize(query)
+	if len(tokens) == 0 || limit <= 0 {
+		return nil
+	}
+	prefix := tokens[len(tokens)-1]
+	head := strings.Join(tokens[:len(tokens)-1], " ")
+
+	idx.mu.RLock()
+	type candidate struct {
+		word  string
+		count int
+	}
+	var candidates []candidate
+	for w, info := range idx.words {
+		if info.suggest > 0 && strings.HasPrefix(w, prefix) {
+			candidates = append(candidates, candidate{w, info.suggest})
+		}
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].count != candidates[j].count {
+			return candidates[i].count > candidates[j].count
+		}
+		return candidates[i].word < candidates[j].word
+	})
+
+	suggestions := make([]string, 0, min(limit, len(candidates)))
+	for _, c := range candidates[:min(limit, len(candidates))] {
+		if head != "" {
+			suggestions = append(suggestions, head+" "+c.word)
+		} else {
+			suggestions = append(suggestions, c.word)
+		}
+	}
+	return suggestions
+}
+
+// indexable отбрасывает однобуквенные слова, кроме чисел (размеры, модели)
+func indexable(token string) bool {
+	if len([]rune(token)) > 1 {
+		return true
+	}
+	return token[0] >= '0' && token[0] <= '9'
+}
@@ -0,0 +1,144 @@
+// internal/search/index_test.go
+package search
+
+import (
+	"backend/internal/strapi"
+	"testing"
+)
+
+func TestStemRussianForms(t *testing.T) {
+	groups := [][]string{
+		{"кроссовки", "кроссовок", "кроссовкам", "кроссовками", "кроссовка"},
+		{"куртка", "куртки", "куртку", "курток"},
+		{"носок", "носки", "носков"},
+		{"красная", "красные", "красный", "красного"},
+		{"зелёный", "зеленые"},
+		{"shoes", "shoe"},
+		{"running", "runs"},
+	}
+	for _, group := range groups {
+		want := Stem(Tokenize(group[0])[0])
+		for _, word := range group[1:] {
+			if got := Stem(Tokenize(word)[0]); got != want {
+				t.Errorf("Stem(%q) = %q, want %q like %q", word, got, want, group[0])
+			}
+		}
+	}
+
+	if Stem("куртка") == Stem("кроссовка") {
+		t.Error("different words share a stem")
+	}
+}
+
+func newTestIndex() *Index {
+	idx := NewIndex()
+	idx.Replace([]strapi.Product{
+		{ID: 1, Name: "Кроссовки беговые", Brand: strapi.Term{Name: "Nord"}, Category: strapi.Term{Name: "Обувь"}},
+		{ID: 2, Name: "Куртка зимняя", Brand: strapi.Term{Name: "Nord"}, Description: "Тёплая куртка под кроссовки"},
+		{ID: 3, Name: "Шапка", Brand: strapi.Term{Name: "Кроссовки Club"}},
+		{ID: 4, Name: "Носки", Category: strapi.Term{Name: "Кроссовки", Parent: &strapi.Term{Name: "Обувь"}}},
+		{ID: 5, Name: "Running shoes", Brand: strapi.Term{Name: "Sud"}},
+	})
+	return idx
+}
+
+func ids(hits []Hit) []int {
+	var list []int
+	for _, hit := range hits {
+		list = append(list, hit.Product.ID)
+	}
+	return list
+}
+
+func TestSearchMorphologyAndRanking(t *testing.T) {
+	idx := newTestIndex()
+
+	// Название важнее бренда, бренд — категории, категория — описания
+	for _, query := range []string{"кроссовки", "кроссовок", "КРОССОВКАМИ"} {
+		hits, total := idx.Search(query, 0, 10)
+		if got := ids(hits); total != 4 || len(got) != 4 || got[0] != 1 || got[1] != 3 || got[2] != 4 || got[3] != 2 {
+			t.Errorf("%q: ids = %v (total %d), want [1 3 4 2]", query, got, total)
+		}
+	}
+
+	// Все слова запроса обязательны
+	if hits, _ := idx.Search("кроссовки nord", 0, 10); len(hits) != 2 || hits[0].Product.ID != 1 {
+		t.Errorf("кроссовки nord: ids = %v, want [1 2]", ids(hits))
+	}
+	if hits, _ := idx.Search("куртка sud", 0, 10); len(hits) != 0 {
+		t.Errorf("куртка sud: ids = %v, want none", ids(hits))
+	}
+
+	// Фраза из названия целиком поднимает товар
+	hits, _ := idx.Search("running shoe", 0, 10)
+	if got := ids(hits); len(got) != 1 || got[0] != 5 {
+		t.Errorf("running shoe: ids = %v", got)
+	}
+
+	hits, total := idx.Search("кроссовки", 2, 1)
+	if total != 4 || len(hits) != 1 || hits[0].Product.ID != 4 {
+		t.Errorf("page: ids = %v, total = %d", ids(hits), total)
+	}
+	if hits, total := idx.Search("кроссовки", 10, 5); hits != nil || total != 4 {
+		t.Errorf("page past the end: %v, %d", ids(hits), total)
+	}
+}
+
+func TestSearchTyposAndPrefix(t *testing.T) {
+	idx := newTestIndex()
+
+	for query, want := range map[string]int{
+		"кросовки":   1, // пропущенная буква
+		"корссовки":  1, // перестановка
+		"куртак":     2,
+		"runing":     5,
+		"кур":        2, // последнее слово — префикс
+		"беговые кр": 1,
+	} {
+		hits, _ := idx.Search(query, 0, 10)
+		if len(hits) == 0 || hits[0].Product.ID != want {
+			t.Errorf("%q: ids = %v, want %d first", query, ids(hits), want)
+		}
+	}
+
+	// Короткие слова без опечаток: «шапа» не «шапка»
+	if hits, _ := idx.Search("нос шапа", 0, 10); len(hits) != 0 {
+		t.Errorf("нос шапа: ids = %v, want none", ids(hits))
+	}
+
+	// Точное совпадение важнее опечатки
+	idx.Upsert(strapi.Product{ID: 6, Name: "Куртак"})
+	if hits, _ := idx.Search("куртак", 0, 10); len(hits) != 1 || hits[0].Product.ID != 6 {
+		t.Errorf("exact word ignored: ids = %v", ids(hits))
+	}
+}
+
+func TestIndexUpsertRemoveSuggest(t *testing.T) {
+	idx := newTestIndex()
+	if !idx.Ready() || idx.Len() != 5 {
+		t.Fatalf("ready = %v, len = %d", idx.Ready(), idx.Len())
+	}
+
+	idx.Upsert(strapi.Product{ID: 2, Name: "Пуховик"})
+	if hits, _ := idx.Search("куртка", 0, 10); len(hits) != 0 {
+		t.Errorf("old version still found: %v", ids(hits))
+	}
+	if hits, _ := idx.Search("пуховик", 0, 10); len(hits) != 1 {
+		t.Errorf("new version not found")
+	}
+
+	if got := idx.Suggest("кр", 5); len(got) != 1 || got[0] != "кроссовки" {
+		t.Errorf("suggest = %v", got)
+	}
+	if got := idx.Suggest("беговые n", 5); len(got) != 1 || got[0] != "беговые nord" {
+		t.Errorf("suggest with head = %v", got)
+	}
+
+	idx.Remove("1", "3")
+	if got := idx.Suggest("кр", 5); len(got) != 0 {
+		t.Errorf("suggest after remove = %v", got)
+	}
+	if idx.Len() != 3 {
+		t.Errorf("len = %d, want 3", idx.Len())
+	}
+}
@@ -0,0 +1,230 @@
+// internal/search/stem.go
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenize разбивает текст на слова в нижнем регистре; ё приводится к е
+func Tokenize(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Stem возвращает основу слова: для кириллицы — по алгоритму Snowball
+// для русского языка, для латиницы — облегчённый английский стеммер
+func Stem(word string) string {
+	runes := []rune(word)
+	if len(runes) < 3 {
+		return word
+	}
+	if unicode.Is(unicode.Cyrillic, runes[0]) {
+		return string(stemRussian(runes))
+	}
+	if runes[0] < unicode.MaxASCII && unicode.IsLetter(runes[0]) {
+		return stemEnglish(word)
+	}
+	return word
+}
+
+// Окончания русского Snowball-стеммера. Группы "после а/я" отрезаются
+// только если перед окончанием стоит а или я, сама буква остаётся.
+var (
+	ruPerfectiveGerund1 = []string{"вшись", "вши", "в"}
+	ruPerfectiveGerund2 = []string{"ившись", "ывшись", "ивши", "ывши", "ив", "ыв"}
+	ruReflexive         = []string{"ся", "сь"}
+	ruAdjective         = []string{
+		"ими", "ыми", "его", "ого", "ему", "ому",
+		"ее", "ие", "ые", "ое", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом",
+		"их", "ых", "ую", "юю", "ая", "яя", "ою", "ею",
+	}
+	ruParticiple1 = []string{"ем", "нн", "вш", "ющ", "щ"}
+	ruParticiple2 = []string{"ивш", "ывш", "ующ"}
+	ruVerb1       = []string{
+		"ете", "йте", "ешь", "нно",
+		"ла", "на", "ли", "ем", "ло", "но", "ет", "ют", "ны", "ть",
+		"й", "л", "н",
+	}
+	ruVerb2 = []string{
+		"ейте", "уйте",
+		"ила", "ыла", "ена", "ите", "или", "ыли", "ило", "ыло", "ено", "ует", "уют", "ены", "ить", "ыть", "ишь",
+		"ей", "уй", "ил", "ыл", "им", "ым", "ен", "ят", "ит", "ыт", "ую",
+		"ю",
+	}
+	ruNoun = []string{
+		"иями", "ями", "ами", "ией", "иям", "ием", "иях",
+		"ев", "ов", "ие", "ье", "еи", "ии", "ей", "ой", "ий", "ям", "ем", "ам", "ом", "ах", "ях", "ию", "ью", "ия", "ья",
+		"а", "е", "и", "й", "о", "у", "ы", "ь", "ю", "я",
+	}
+	ruSuperlative  = []string{"ейше", "ейш"}
+	ruDerivational = []string{"ость", "ост"}
+	ruVowels       = "аеиоуыэюя"
+)
+
+func isRuVowel(r rune) bool {
+	return strings.ContainsRune(ruVowels, r)
+}
+
+// stemRussian — алгоритм Snowball для русского языка с поправкой на беглые гласные
+func stemRussian(word []rune) []rune {
+	// RV — часть слова после первой гласной, R2 — стандартная область Snowball
+	rv := len(word)
+	for i, r := range word {
+		if isRuVowel(r) {
+			rv = i + 1
+			break
+		}
+	}
+	r2 := snowballR2(word, isRuVowel)
+	if rv >= len(word) {
+		return word
+	}
+
+	head, tail := word[:rv], word[rv:]
+
+	// Шаг 1
+	if t, ok := cutPreceded(tail, ruPerfectiveGerund1); ok {
+		tail = t
+	} else if t, ok := cutSuffix(tail, ruPerfectiveGerund2); ok {
+		tail = t
+	} else {
+		if t, ok := cutSuffix(tail, ruReflexive); ok {
+			tail = t
+		}
+		if t, ok := cutSuffix(tail, ruAdjective); ok {
+			tail = t
+			if t, ok := cutPreceded(tail, ruParticiple1); ok {
+				tail = t
+			} else if t, ok := cutSuffix(tail, ruParticiple2); ok {
+				tail = t
+			}
+		} else if t, ok := cutPreceded(tail, ruVerb1); ok {
+			tail = t
+		} else if t, ok := cutSuffix(tail, ruVerb2); ok {
+			tail = t
+		} else if t, ok := cutSuffix(tail, ruNoun); ok {
+			tail = t
+		}
+	}
+
+	// Шаг 2
+	if t, ok := cutSuffix(tail, []string{"и"}); ok {
+		tail = t
+	}
+
+	// Шаг 3: словообразовательные окончания только в R2
+	if t, ok := cutSuffix(tail, ruDerivational); ok && rv+len(t) >= r2 {
+		tail = t
+	}
+
+	// Шаг 4
+	if t, ok := cutSuffix(tail, ruSuperlative); ok {
+		tail = t
+	}
+	if t, ok := cutSuffix(tail, []string{"нн"}); ok {
+		tail = append(t, 'н')
+	} else if t, ok := cutSuffix(tail, []string{"ь"}); ok {
+		tail = t
+	}
+
+	stem := append(append([]rune{}, head...), tail...)
+
+	// Шаг 5 (вне Snowball): беглая гласная перед к/ц — кроссовок/кроссовки,
+	// носок/носки, отец/отца; иначе родительный падеж множественного числа
+	// получает другую основу, чем остальные формы слова
+	if n := len(stem); n >= 5 && (stem[n-1] == 'к' || stem[n-1] == 'ц') &&
+		(stem[n-2] == 'о' || stem[n-2] == 'е') && !isRuVowel(stem[n-3]) {
+		stem = append(stem[:n-2], stem[n-1])
+	}
+	return stem
+}
+
+// snowballR2 возвращает начало области R2: R1 — после первой согласной,
+// следующей за гласной; R2 — то же правило внутри R1
+func snowballR2(word []rune, isVowel func(rune) bool) int {
+	region := func(start int) int {
+		for i := start + 1; i < len(word); i++ {
+			if !isVowel(word[i]) && isVowel(word[i-1]) {
+				return i + 1
+			}
+		}
+		return len(word)
+	}
+	return region(region(0))
+}
+
+// cutSuffix отрезает самое длинное подходящее окончание из списка
+func cutSuffix(word []rune, suffixes []string) ([]rune, bool) {
+	best := -1
+	for _, suffix := range suffixes {
+		n := len([]rune(suffix))
+		if n > best && hasSuffix(word, suffix) {
+			best = n
+		}
+	}
+	if best < 0 {
+		return word, false
+	}
+	return word[:len(word)-best], true
+}
+
+// cutPreceded отрезает окончание, только если перед ним стоит а или я
+func cutPreceded(word []rune, suffixes []string) ([]rune, bool) {
+	best := -1
+	for _, suffix := range suffixes {
+		n := len([]rune(suffix))
+		if n <= best || !hasSuffix(word, suffix) || len(word) <= n {
+			continue
+		}
+		if prev := word[len(word)-n-1]; prev == 'а' || prev == 'я' {
+			best = n
+		}
+	}
+	if best < 0 {
+		return word, false
+	}
+	return word[:len(word)-best], true
+}
+
+func hasSuffix(word []rune, suffix string) bool {
+	s := []rune(suffix)
+	if len(s) > len(word) {
+		return false
+	}
+	for i := range s {
+		if word[len(word)-len(s)+i] != s[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// stemEnglish снимает частые английские окончания (множественное число,
+// -ing, -ed, -ly). Для названий и описаний товаров этого достаточно.
+func stemEnglish(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "shes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "xes"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"):
+	case strings.HasSuffix(word, "s") && len(word) > 3:
+		word = word[:len(word)-1]
+	}
+
+	for _, suffix := range []string{"ingly", "edly", "ing", "ed", "ly"} {
+		stem := strings.TrimSuffix(word, suffix)
+		if stem != word && len(stem) >= 3 && strings.ContainsAny(stem, "aeiouy") {
+			// hopping -> hop
+			if n := len(stem); n >= 2 && stem[n-1] == stem[n-2] && !strings.ContainsRune("lsz", rune(stem[n-1])) {
+				stem = stem[:n-1]
+			}
+			return stem
+		}
+	}
+	return word
+}
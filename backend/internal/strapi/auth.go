@@ -0,0 +1,42 @@
+// internal/strapi/auth.go
+package strapi
+
+import (
+	"context"
+	"net/http"
+)
+
+// AuthResponse — ответ users-permissions на вход и регистрацию
+type AuthResponse struct {
+	JWT  string `json:"jwt"`
+	User User   `json:"user"`
+}
+
+// Login проверяет логин и пароль через /api/auth/local
+func (c *Client) Login(ctx context.Context, identifier, password string) (*AuthResponse, error) {
+	body := map[string]string{
+		"identifier": identifier,
+		"password":   password,
+	}
+
+	var resp AuthResponse
+	if err := c.do(ctx, http.MethodPost, "/api/auth/local", nil, body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Register создаёт пользователя через /api/auth/local/register
+func (c *Client) Register(ctx context.Context, username, email, password string) (*AuthResponse, error) {
+	body := map[string]string{
+		"username": username,
+		"email":    email,
+		"password": password,
+	}
+
+	var resp AuthResponse
+	if err := c.do(ctx, http.MethodPost, "/api/auth/local/register", nil, body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
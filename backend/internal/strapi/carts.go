@@ -0,0 +1,86 @@
+// internal/strapi/carts.go
+package strapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// cartPageSize — сколько строк корзины запрашивать за раз; больше
+// Strapi по умолчанию не отдаёт, поэтому длинная корзина читается страницами
+const cartPageSize = 100
+
+// GetCartItems возвращает все строки корзины пользователя
+func (c *Client) GetCartItems(ctx context.Context, userID int) (*CartList, error) {
+	query := url.Values{}
+	query.Set("filters[user][id][$eq]", strconv.Itoa(userID))
+	query.Set("populate", "*")
+	// Без сортировки порядок между страницами не гарантирован
+	query.Set("sort", "id:asc")
+	query.Set("pagination[pageSize]", strconv.Itoa(cartPageSize))
+
+	var all CartList
+	for page := 1; ; page++ {
+		query.Set("pagination[page]", strconv.Itoa(page))
+
+		var list CartList
+		if err := c.do(ctx, http.MethodGet, "/api/carts", query, nil, &list); err != nil {
+			return nil, err
+		}
+		all.Data = append(all.Data, list.Data...)
+		all.Meta = list.Meta
+
+		if list.Meta.Pagination == nil || page >= list.Meta.Pagination.PageCount || len(list.Data) == 0 {
+			return &all, nil
+		}
+	}
+}
+
+// CreateCartItem добавляет строку в корзину
+func (c *Client) CreateCartItem(ctx context.Context, input CartItemInput) (*CartItem, error) {
+	var created entry[CartItem]
+	if err := c.do(ctx, http.MethodPost, "/api/carts", nil, payload[CartItemInput]{Data: input}, &created); err != nil {
+		return nil, err
+	}
+	return &created.Data, nil
+}
+
+// GetUserCartItem возвращает строку корзины, только если она принадлежит
+// пользователю; id — числовой id или documentId строки. Чужая или
+// несуществующая строка даёт ошибку 404.
+func (c *Client) GetUserCartItem(ctx context.Context, userID int, id string) (*CartItem, error) {
+	query := url.Values{}
+	query.Set("filters[user][id][$eq]", strconv.Itoa(userID))
+	if _, err := strconv.Atoi(id); err == nil {
+		query.Set("filters[id][$eq]", id)
+	} else {
+		query.Set("filters[documentId][$eq]", id)
+	}
+	query.Set("populate", "*")
+	query.Set("pagination[pageSize]", "1")
+
+	var list CartList
+	if err := c.do(ctx, http.MethodGet, "/api/carts", query, nil, &list); err != nil {
+		return nil, err
+	}
+	if len(list.Data) == 0 {
+		return nil, &Error{StatusCode: http.StatusNotFound, Name: "NotFoundError", Message: "Not Found"}
+	}
+	return &list.Data[0], nil
+}
+
+// UpdateCartItem меняет строку корзины; id — documentId строки
+func (c *Client) UpdateCartItem(ctx context.Context, id string, input CartItemUpdate) (*CartItem, error) {
+	var updated entry[CartItem]
+	if err := c.do(ctx, http.MethodPut, "/api/carts/"+url.PathEscape(id), nil, payload[CartItemUpdate]{Data: input}, &updated); err != nil {
+		return nil, err
+	}
+	return &updated.Data, nil
+}
+
+// DeleteCartItem удаляет строку корзины; id — documentId строки
+func (c *Client) DeleteCartItem(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/carts/"+url.PathEscape(id), nil, nil, nil)
+}
@@ -0,0 +1,51 @@
+// internal/strapi/carts_test.go
+package strapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestGetCartItemsReadsAllPages(t *testing.T) {
+	const total = 230
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("filters[user][id][$eq]") != "7" {
+			t.Errorf("user filter = %q", query.Get("filters[user][id][$eq]"))
+		}
+		page, _ := strconv.Atoi(query.Get("pagination[page]"))
+		size, _ := strconv.Atoi(query.Get("pagination[pageSize]"))
+		pages = append(pages, query.Get("pagination[page]"))
+
+		list := CartList{Meta: Meta{Pagination: &Pagination{
+			Page: page, PageSize: size, PageCount: (total + size - 1) / size, Total: total,
+		}}}
+		for id := (page-1)*size + 1; id <= min(page*size, total); id++ {
+			list.Data = append(list.Data, CartItem{ID: id, Quantity: 1})
+		}
+		json.NewEncoder(w).Encode(list)
+	}))
+	defer srv.Close()
+
+	client := &Client{baseURL: srv.URL, httpClient: srv.Client()}
+	list, err := client.GetCartItems(context.Background(), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Data) != total {
+		t.Fatalf("lines = %d, want %d", len(list.Data), total)
+	}
+	for i, item := range list.Data {
+		if item.ID != i+1 {
+			t.Fatalf("line %d has id %d", i, item.ID)
+		}
+	}
+	if len(pages) != 3 || pages[0] != "1" || pages[2] != "3" {
+		t.Fatalf("pages = %v, want 1..3", pages)
+	}
+}
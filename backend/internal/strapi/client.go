@@ -0,0 +1,114 @@
+// internal/strapi/client.go
+package strapi
+
+import (
+	"backend/internal/config"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client обращается к REST API Strapi через общий http.Client
+type Client struct {
+	baseURL    string
+	apiToken   string
+	httpClient *http.Client
+
+	// productPopulate — что подгружать в карточку товара, см. PopulateValues
+	productPopulate []string
+}
+
+// NewClient создаёт клиент Strapi с таймаутами из конфигурации
+func NewClient(cfg *config.Config) *Client {
+	timeout := cfg.StrapiTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   20,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: timeout,
+	}
+
+	return &Client{
+		baseURL:         strings.TrimRight(cfg.StrapiURL, "/"),
+		apiToken:        cfg.StrapiAPIToken,
+		productPopulate: cfg.ProductPopulate,
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+	}
+}
+
+// BaseURL возвращает адрес Strapi без завершающего слэша
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// do выполняет запрос к Strapi, проверяет статус и декодирует ответ в out
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("strapi: маршалинг запроса: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("strapi: создание запроса: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("strapi: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("strapi: чтение ответа: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return decodeError(resp.StatusCode, data)
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("strapi: парсинг ответа: %w", err)
+	}
+	return nil
+}
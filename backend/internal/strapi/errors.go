@@ -0,0 +1,53 @@
+// internal/strapi/errors.go
+package strapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error описывает ошибку из конверта Strapi v5:
+// {"data": null, "error": {"status": 404, "name": "NotFoundError", "message": "...", "details": {}}}
+type Error struct {
+	StatusCode int             `json:"status"`
+	Name       string          `json:"name"`
+	Message    string          `json:"message"`
+	Details    json.RawMessage `json:"details,omitempty"`
+	Body       string          `json:"-"`
+}
+
+func (e *Error) Error() string {
+	if e.Name != "" || e.Message != "" {
+		return fmt.Sprintf("strapi: %d %s: %s", e.StatusCode, e.Name, e.Message)
+	}
+	return fmt.Sprintf("strapi: %d: %s", e.StatusCode, e.Body)
+}
+
+// decodeError разбирает тело ответа с ошибкой, сохраняя сырое тело, если конверт не распознан
+func decodeError(status int, body []byte) error {
+	var envelope struct {
+		Error *Error `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Error != nil {
+		envelope.Error.StatusCode = status
+		envelope.Error.Body = string(body)
+		return envelope.Error
+	}
+	return &Error{StatusCode: status, Body: string(body)}
+}
+
+// StatusCode возвращает HTTP-статус ошибки Strapi или 0, если ошибка другого типа
+func StatusCode(err error) int {
+	var se *Error
+	if errors.As(err, &se) {
+		return se.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound сообщает, что Strapi не нашёл запрошенную запись
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
@@ -0,0 +1,152 @@
+// internal/strapi/models.go
+package strapi
+
+// Pagination — блок meta.pagination из ответов Strapi
+type Pagination struct {
+	Page      int `json:"page,omitempty"`
+	PageSize  int `json:"pageSize,omitempty"`
+	PageCount int `json:"pageCount,omitempty"`
+	Start     int `json:"start,omitempty"`
+	Limit     int `json:"limit,omitempty"`
+	Total     int `json:"total"`
+}
+
+type Meta struct {
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+type Product struct {
+	ID          int         `json:"id"`
+	DocumentID  string      `json:"documentId"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       int         `json:"price"`
+	OldPrice    *int        `json:"oldPrice,omitempty"` // цена до скидки, если товар продаётся со скидкой
+	Category    Term        `json:"category"`
+	Brand       Term        `json:"brand"`
+	Size        interface{} `json:"size"`
+	CreatedAt   string      `json:"createdAt"`
+	UpdatedAt   string      `json:"updatedAt"`
+	PublishedAt string      `json:"publishedAt"`
+	ImageURL    string      `json:"imageUrl"` // Добавляем поле для URL изображения
+	Images      []Image     `json:"image"`    // Полный массив изображений
+	Related     []Product   `json:"related,omitempty"`
+	Variants    []Variant   `json:"variants,omitempty"`
+}
+
+type Image struct {
+	ID              int     `json:"id"`
+	Name            string  `json:"name"`
+	AlternativeText string  `json:"alternativeText,omitempty"`
+	URL             string  `json:"url"`
+	Width           int     `json:"width,omitempty"`
+	Height          int     `json:"height,omitempty"`
+	Mime            string  `json:"mime,omitempty"`
+	Formats         Formats `json:"formats"`
+}
+
+// Formats — производные изображения, которые Strapi нарезает при загрузке
+type Formats struct {
+	Thumbnail *ImageFormat `json:"thumbnail,omitempty"`
+	Small     *ImageFormat `json:"small,omitempty"`
+	Medium    *ImageFormat `json:"medium,omitempty"`
+	Large     *ImageFormat `json:"large,omitempty"`
+}
+
+type ImageFormat struct {
+	Name   string  `json:"name,omitempty"`
+	URL    string  `json:"url"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Mime   string  `json:"mime,omitempty"`
+	Size   float64 `json:"size,omitempty"` // в килобайтах, как отдаёт Strapi
+}
+
+// Variant — вариант товара (компонент variants): размер, цвет и остаток
+type Variant struct {
+	ID    int    `json:"id"`
+	Size  string `json:"size"`
+	Color string `json:"color,omitempty"`
+	SKU   string `json:"sku,omitempty"`
+	Stock *int   `json:"stock,omitempty"`
+	Price *int   `json:"price,omitempty"`
+}
+
+type ProductList struct {
+	Data []Product `json:"data"`
+	Meta Meta      `json:"meta"`
+}
+
+type Role struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+}
+
+type User struct {
+	ID         int    `json:"id"`
+	DocumentID string `json:"documentId"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	Provider   string `json:"provider,omitempty"`
+	Confirmed  bool   `json:"confirmed"`
+	Blocked    bool   `json:"blocked"`
+	CreatedAt  string `json:"createdAt,omitempty"`
+	UpdatedAt  string `json:"updatedAt,omitempty"`
+	Role       *Role  `json:"role,omitempty"`
+}
+
+// CartItem — строка корзины из коллекции carts
+type CartItem struct {
+	ID         int      `json:"id"`
+	DocumentID string   `json:"documentId"`
+	Quantity   int      `json:"quantity"`
+	Size       string   `json:"size,omitempty"`
+	Color      string   `json:"color,omitempty"`
+	Price      *int     `json:"price,omitempty"` // цена на момент добавления, если коллекция её хранит
+	Product    *Product `json:"product,omitempty"`
+	User       *User    `json:"user,omitempty"`
+	CreatedAt  string   `json:"createdAt,omitempty"`
+	UpdatedAt  string   `json:"updatedAt,omitempty"`
+}
+
+// CartItemInput — данные для создания строки корзины
+type CartItemInput struct {
+	User     int    `json:"user"`
+	Product  int    `json:"product"`
+	Quantity int    `json:"quantity"`
+	Size     string `json:"size,omitempty"`
+	Color    string `json:"color,omitempty"`
+	Price    *int   `json:"price,omitempty"`
+}
+
+// CartItemUpdate — изменяемые поля строки корзины
+type CartItemUpdate struct {
+	Quantity int  `json:"quantity"`
+	Price    *int `json:"price,omitempty"`
+}
+
+type CartList struct {
+	Data []CartItem `json:"data"`
+	Meta Meta       `json:"meta"`
+}
+
+// Order хранит заказ как есть: схема заказа задаётся в Strapi и меняется чаще кода
+type Order map[string]interface{}
+
+type OrderList struct {
+	Data []Order `json:"data"`
+	Meta Meta    `json:"meta"`
+}
+
+// entry — конверт одиночной записи {"data": {...}, "meta": {}}
+type entry[T any] struct {
+	Data T    `json:"data"`
+	Meta Meta `json:"meta"`
+}
+
+// payload — конверт тела запроса на создание/изменение записи
+type payload[T any] struct {
+	Data T `json:"data"`
+}
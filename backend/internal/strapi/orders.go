@@ -0,0 +1,54 @@
+// internal/strapi/orders.go
+package strapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// GetOrders возвращает заказы пользователя
+func (c *Client) GetOrders(ctx context.Context, userID int) (*OrderList, error) {
+	query := url.Values{}
+	query.Set("filters[user][id][$eq]", strconv.Itoa(userID))
+	query.Set("populate", "*")
+
+	var list OrderList
+	if err := c.do(ctx, http.MethodGet, "/api/orders", query, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// CreateOrder создаёт заказ из произвольных данных
+func (c *Client) CreateOrder(ctx context.Context, data map[string]interface{}) (Order, error) {
+	var created entry[Order]
+	if err := c.do(ctx, http.MethodPost, "/api/orders", nil, payload[map[string]interface{}]{Data: data}, &created); err != nil {
+		return nil, err
+	}
+	return created.Data, nil
+}
+
+// ListOrders возвращает заказы всех пользователей с фильтрами и пагинацией из query
+func (c *Client) ListOrders(ctx context.Context, query url.Values) (*OrderList, error) {
+	q := cloneValues(query)
+	if q.Get("populate") == "" {
+		q.Set("populate", "*")
+	}
+
+	var list OrderList
+	if err := c.do(ctx, http.MethodGet, "/api/orders", q, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// UpdateOrder изменяет поля заказа; id — documentId заказа
+func (c *Client) UpdateOrder(ctx context.Context, id string, data map[string]interface{}) (Order, error) {
+	var updated entry[Order]
+	if err := c.do(ctx, http.MethodPut, "/api/orders/"+url.PathEscape(id), nil, payload[map[string]interface{}]{Data: data}, &updated); err != nil {
+		return nil, err
+	}
+	return updated.Data, nil
+}
@@ -0,0 +1,144 @@
+// internal/strapi/product.go
+package strapi
+
+import "strings"
+
+// Sizes возвращает размеры товара из поля size (строка или массив)
+// и из вариантов, без повторов и пустых значений
+func (p Product) Sizes() []string {
+	var raw []string
+	switch v := p.Size.(type) {
+	case string:
+		raw = append(raw, v)
+	case []string:
+		raw = append(raw, v...)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	}
+	for _, variant := range p.Variants {
+		raw = append(raw, variant.Size)
+	}
+	return uniqueStrings(raw)
+}
+
+// Colors возвращает цвета вариантов без повторов
+func (p Product) Colors() []string {
+	var raw []string
+	for _, variant := range p.Variants {
+		raw = append(raw, variant.Color)
+	}
+	return uniqueStrings(raw)
+}
+
+// Stock суммирует остатки вариантов; ok = false, если остаток
+// хотя бы одного варианта не ведётся или вариантов нет
+func (p Product) Stock() (total int, ok bool) {
+	if len(p.Variants) == 0 {
+		return 0, false
+	}
+	for _, variant := range p.Variants {
+		if variant.Stock == nil {
+			return 0, false
+		}
+		total += *variant.Stock
+	}
+	return total, true
+}
+
+// MatchVariants возвращает варианты с указанным размером и цветом без учёта
+// регистра; пустое значение подходит к любому
+func (p Product) MatchVariants(size, color string) []Variant {
+	var matched []Variant
+	for _, variant := range p.Variants {
+		if size != "" && !sameValue(variant.Size, size) {
+			continue
+		}
+		if color != "" && !sameValue(variant.Color, color) {
+			continue
+		}
+		matched = append(matched, variant)
+	}
+	return matched
+}
+
+// VariantStock — остаток выбранного варианта; если размер и цвет не заданы
+// или вариантов с ними нет, возвращается общий остаток товара
+func (p Product) VariantStock(size, color string) (total int, ok bool) {
+	matched := p.MatchVariants(size, color)
+	if (size == "" && color == "") || len(matched) == 0 {
+		return p.Stock()
+	}
+	for _, variant := range matched {
+		if variant.Stock == nil {
+			return 0, false
+		}
+		total += *variant.Stock
+	}
+	return total, true
+}
+
+// VariantPrice — цена выбранного варианта, если она задана отдельно, иначе цена товара
+func (p Product) VariantPrice(size, color string) int {
+	if price, ok := p.ownVariantPrice(size, color); ok {
+		return price
+	}
+	return p.Price
+}
+
+// ListPrice — цена до скидки для выбранного варианта. OldPrice товара относится
+// к его базовой цене, поэтому у варианта с собственной ценой скидки нет.
+func (p Product) ListPrice(size, color string) int {
+	if price, ok := p.ownVariantPrice(size, color); ok {
+		return price
+	}
+	if p.OldPrice != nil && *p.OldPrice > p.Price {
+		return *p.OldPrice
+	}
+	return p.Price
+}
+
+// ownVariantPrice возвращает цену, заданную у подходящего варианта
+func (p Product) ownVariantPrice(size, color string) (int, bool) {
+	if size == "" && color == "" {
+		return 0, false
+	}
+	for _, variant := range p.MatchVariants(size, color) {
+		if variant.Price != nil {
+			return *variant.Price, true
+		}
+	}
+	return 0, false
+}
+
+// MediaURL дополняет относительный путь загрузки ("/uploads/...") адресом Strapi;
+// абсолютные ссылки (внешний провайдер файлов) возвращаются как есть
+func MediaURL(base, path string) string {
+	if path == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+func sameValue(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+// uniqueStrings убирает пустые значения и повторы без учёта регистра
+func uniqueStrings(values []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		key := strings.ToLower(value)
+		if value == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, value)
+	}
+	return out
+}
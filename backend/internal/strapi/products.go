@@ -0,0 +1,117 @@
+// internal/strapi/products.go
+package strapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// listAllPageSize — максимальный размер страницы, который Strapi отдаёт по умолчанию
+const listAllPageSize = 100
+
+// GetProducts возвращает список товаров; query дополняет запрос фильтрами и пагинацией
+func (c *Client) GetProducts(ctx context.Context, query url.Values) (*ProductList, error) {
+	q := cloneValues(query)
+	if q.Get("populate") == "" {
+		q.Set("populate", "image")
+	}
+
+	var list ProductList
+	if err := c.do(ctx, http.MethodGet, "/api/products", q, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetProduct возвращает товар по числовому ID или documentId вместе со всеми
+// изображениями, связанными товарами и вариантами
+func (c *Client) GetProduct(ctx context.Context, id string) (*Product, error) {
+	query := PopulateValues(c.productPopulate)
+
+	if _, err := strconv.Atoi(id); err == nil {
+		query.Set("filters[id][$eq]", id)
+		query.Set("pagination[pageSize]", "1")
+
+		var list ProductList
+		if err := c.do(ctx, http.MethodGet, "/api/products", query, nil, &list); err != nil {
+			return nil, err
+		}
+		if len(list.Data) == 0 {
+			return nil, &Error{StatusCode: http.StatusNotFound, Name: "NotFoundError", Message: "Not Found"}
+		}
+		return &list.Data[0], nil
+	}
+
+	var product entry[Product]
+	if err := c.do(ctx, http.MethodGet, "/api/products/"+url.PathEscape(id), query, nil, &product); err != nil {
+		return nil, err
+	}
+	if product.Data.ID == 0 {
+		return nil, &Error{StatusCode: http.StatusNotFound, Name: "NotFoundError", Message: "Not Found"}
+	}
+	return &product.Data, nil
+}
+
+// PopulateValues переводит пути вида "related.image" в синтаксис populate Strapi v5:
+// populate[related][populate][image]=true. Поле с вложенными путями
+// не получает отдельного =true, иначе Strapi отвергнет запрос.
+func PopulateValues(paths []string) url.Values {
+	v := url.Values{}
+	nested := map[string]bool{}
+	for _, path := range paths {
+		if parent, _, ok := strings.Cut(path, "."); ok {
+			nested[parent] = true
+		}
+	}
+
+	for _, path := range paths {
+		parts := strings.Split(path, ".")
+		if len(parts) == 1 && nested[parts[0]] {
+			continue
+		}
+		key := "populate"
+		for i, part := range parts {
+			if i > 0 {
+				key += "[populate]"
+			}
+			key += "[" + part + "]"
+		}
+		v.Set(key, "true")
+	}
+	return v
+}
+
+// ListAllProducts проходит по всем страницам листинга и возвращает товары целиком.
+// В отличие от GetProducts не подгружает изображения, если populate не задан в query.
+func (c *Client) ListAllProducts(ctx context.Context, query url.Values) ([]Product, error) {
+	q := cloneValues(query)
+	q.Set("pagination[pageSize]", strconv.Itoa(listAllPageSize))
+	q.Del("pagination[start]")
+	q.Del("pagination[limit]")
+
+	var products []Product
+	for page := 1; ; page++ {
+		q.Set("pagination[page]", strconv.Itoa(page))
+
+		var list ProductList
+		if err := c.do(ctx, http.MethodGet, "/api/products", q, nil, &list); err != nil {
+			return nil, err
+		}
+		products = append(products, list.Data...)
+
+		if list.Meta.Pagination == nil || page >= list.Meta.Pagination.PageCount || len(list.Data) == 0 {
+			return products, nil
+		}
+	}
+}
+
+func cloneValues(v url.Values) url.Values {
+	out := url.Values{}
+	for key, values := range v {
+		out[key] = append([]string(nil), values...)
+	}
+	return out
+}
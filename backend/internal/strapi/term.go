@@ -0,0 +1,83 @@
+// internal/strapi/term.go
+package strapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Term — категория или бренд товара. В простой схеме это строковое поле,
+// в расширенной — связь с коллекцией {name, slug, parent}. В ответах шлюза
+// Term всегда сериализуется строкой с названием, чтобы формат API не зависел от схемы.
+type Term struct {
+	Name   string
+	Slug   string
+	Parent *Term
+}
+
+func (t *Term) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*t = Term{}
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return err
+		}
+		*t = Term{Name: name}
+		return nil
+	}
+
+	var relation struct {
+		Name   string `json:"name"`
+		Title  string `json:"title"`
+		Slug   string `json:"slug"`
+		Parent *Term  `json:"parent"`
+	}
+	if err := json.Unmarshal(data, &relation); err != nil {
+		return err
+	}
+	*t = Term{Name: relation.Name, Slug: relation.Slug, Parent: relation.Parent}
+	if t.Name == "" {
+		t.Name = relation.Title
+	}
+	return nil
+}
+
+func (t Term) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Name)
+}
+
+// String возвращает название термина
+func (t Term) String() string {
+	return t.Name
+}
+
+// Path возвращает цепочку названий от корня до термина
+func (t Term) Path() []string {
+	var path []string
+	for cur := &t; cur != nil && cur.Name != ""; cur = cur.Parent {
+		path = append([]string{cur.Name}, path...)
+	}
+	return path
+}
+
+// Levels возвращает путь категории: цепочку parent у связи
+// или части строки, разделённые separator ("Одежда/Куртки")
+func (t Term) Levels(separator string) []string {
+	if t.Parent != nil {
+		return t.Path()
+	}
+
+	var path []string
+	for _, part := range strings.Split(t.Name, separator) {
+		if part = strings.TrimSpace(part); part != "" {
+			path = append(path, part)
+		}
+	}
+	return path
+}
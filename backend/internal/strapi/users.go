@@ -0,0 +1,21 @@
+// internal/strapi/users.go
+package strapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// GetUser возвращает пользователя users-permissions вместе с ролью
+func (c *Client) GetUser(ctx context.Context, id int) (*User, error) {
+	query := url.Values{}
+	query.Set("populate", "role")
+
+	var user User
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/users/%d", id), query, nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,109 @@
+// internal/woocommerce/client.go
+package woocommerce
+
+import (
+	"backend/internal/config"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// apiPrefix — REST API WooCommerce v3 внутри WordPress
+const apiPrefix = "/wp-json/wc/v3"
+
+// Client обращается к REST API WooCommerce с ключами потребителя
+type Client struct {
+	baseURL        string
+	consumerKey    string
+	consumerSecret string
+	httpClient     *http.Client
+}
+
+// NewClient создаёт клиент WooCommerce с таймаутами из конфигурации
+func NewClient(cfg *config.Config) *Client {
+	timeout := cfg.WooCommerceTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          20,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: timeout,
+	}
+
+	return &Client{
+		baseURL:        strings.TrimRight(cfg.WooCommerceURL, "/"),
+		consumerKey:    cfg.WooCommerceKey,
+		consumerSecret: cfg.WooCommerceSecret,
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+	}
+}
+
+// do выполняет запрос к WooCommerce, проверяет статус и декодирует ответ в out.
+// Ключи передаются через Basic Auth — WooCommerce принимает их так по HTTPS.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	endpoint := c.baseURL + apiPrefix + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("woocommerce: маршалинг запроса: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("woocommerce: создание запроса: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.SetBasicAuth(c.consumerKey, c.consumerSecret)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("woocommerce: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("woocommerce: чтение ответа: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return decodeError(resp.StatusCode, data)
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("woocommerce: парсинг ответа: %w", err)
+	}
+	return nil
+}
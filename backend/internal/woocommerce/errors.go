@@ -0,0 +1,56 @@
+// internal/woocommerce/errors.go
+package woocommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error описывает ошибку REST API WordPress:
+// {"code": "woocommerce_rest_product_invalid_id", "message": "...", "data": {"status": 404}}
+type Error struct {
+	StatusCode int    `json:"-"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Body       string `json:"-"`
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" || e.Message != "" {
+		return fmt.Sprintf("woocommerce: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("woocommerce: %d: %s", e.StatusCode, e.Body)
+}
+
+// decodeError разбирает тело ответа с ошибкой, сохраняя сырое тело, если формат не распознан
+func decodeError(status int, body []byte) error {
+	e := &Error{StatusCode: status, Body: string(body)}
+	_ = json.Unmarshal(body, e)
+	return e
+}
+
+// StatusCode возвращает HTTP-статус ошибки WooCommerce или 0, если ошибка другого типа
+func StatusCode(err error) int {
+	var we *Error
+	if errors.As(err, &we) {
+		return we.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound сообщает, что WooCommerce не нашёл запись
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsRetryable сообщает, имеет ли смысл повторить запрос: сетевые ошибки,
+// 429 и ошибки сервера; ошибки валидации (4xx) повтор не исправит
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	status := StatusCode(err)
+	return status == 0 || status == http.StatusTooManyRequests || status >= 500
+}
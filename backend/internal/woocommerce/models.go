@@ -0,0 +1,54 @@
+// internal/woocommerce/models.go
+package woocommerce
+
+// Product — товар WooCommerce в объёме, который заполняет синхронизация.
+// Цены в WooCommerce передаются строками.
+type Product struct {
+	ID               int          `json:"id,omitempty"`
+	Name             string       `json:"name"`
+	Type             string       `json:"type,omitempty"`
+	Status           string       `json:"status,omitempty"`
+	SKU              string       `json:"sku,omitempty"`
+	RegularPrice     string       `json:"regular_price"`
+	Description      string       `json:"description"`
+	ShortDescription string       `json:"short_description,omitempty"`
+	ManageStock      bool         `json:"manage_stock"`
+	StockQuantity    *int         `json:"stock_quantity,omitempty"`
+	Categories       []CategoryID `json:"categories"`
+	Images           []Image      `json:"images"`
+	Attributes       []Attribute  `json:"attributes"`
+	MetaData         []MetaData   `json:"meta_data,omitempty"`
+}
+
+// CategoryID — ссылка на категорию в товаре
+type CategoryID struct {
+	ID int `json:"id"`
+}
+
+type Category struct {
+	ID     int    `json:"id,omitempty"`
+	Name   string `json:"name"`
+	Slug   string `json:"slug,omitempty"`
+	Parent int    `json:"parent,omitempty"`
+}
+
+// Image — изображение товара; WooCommerce сам скачивает файл по Src
+type Image struct {
+	ID   int    `json:"id,omitempty"`
+	Src  string `json:"src,omitempty"`
+	Name string `json:"name,omitempty"`
+	Alt  string `json:"alt,omitempty"`
+}
+
+// Attribute — локальный атрибут товара (размер, бренд)
+type Attribute struct {
+	Name      string   `json:"name"`
+	Visible   bool     `json:"visible"`
+	Variation bool     `json:"variation"`
+	Options   []string `json:"options"`
+}
+
+type MetaData struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
@@ -0,0 +1,73 @@
+// internal/woocommerce/products.go
+package woocommerce
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreateProduct создаёт товар и возвращает его с присвоенным ID
+func (c *Client) CreateProduct(ctx context.Context, product Product) (*Product, error) {
+	var created Product
+	if err := c.do(ctx, http.MethodPost, "/products", nil, product, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateProduct обновляет товар целиком
+func (c *Client) UpdateProduct(ctx context.Context, id int, product Product) (*Product, error) {
+	product.ID = 0
+	var updated Product
+	if err := c.do(ctx, http.MethodPut, "/products/"+strconv.Itoa(id), nil, product, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteProduct переносит товар в корзину WordPress, откуда его можно восстановить
+func (c *Client) DeleteProduct(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, "/products/"+strconv.Itoa(id), nil, nil, nil)
+}
+
+// FindProductBySKU ищет товар по артикулу; nil, если такого нет
+func (c *Client) FindProductBySKU(ctx context.Context, sku string) (*Product, error) {
+	var products []Product
+	query := url.Values{"sku": {sku}}
+	if err := c.do(ctx, http.MethodGet, "/products", query, nil, &products); err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return nil, nil
+	}
+	return &products[0], nil
+}
+
+// EnsureCategory возвращает ID категории с таким названием и родителем,
+// создавая её при отсутствии
+func (c *Client) EnsureCategory(ctx context.Context, name string, parent int) (int, error) {
+	var categories []Category
+	query := url.Values{
+		"search":   {name},
+		"parent":   {strconv.Itoa(parent)},
+		"per_page": {"100"},
+	}
+	if err := c.do(ctx, http.MethodGet, "/products/categories", query, nil, &categories); err != nil {
+		return 0, err
+	}
+	for _, category := range categories {
+		// search ищет по подстроке, нужно точное совпадение; имена приходят с HTML-сущностями
+		if html.UnescapeString(category.Name) == name && category.Parent == parent {
+			return category.ID, nil
+		}
+	}
+
+	var created Category
+	if err := c.do(ctx, http.MethodPost, "/products/categories", nil, Category{Name: name, Parent: parent}, &created); err != nil {
+		return 0, err
+	}
+	return created.ID, nil
+}
@@ -9,9 +9,11 @@ import (
 var (
 	InfoLogger  *log.Logger
 	ErrorLogger *log.Logger
+	AuditLogger *log.Logger
 )
 
 func Init() {
 	InfoLogger = log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile)
 	ErrorLogger = log.New(os.Stderr, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile)
+	AuditLogger = log.New(os.Stdout, "AUDIT: ", log.Ldate|log.Ltime|log.LUTC)
 }